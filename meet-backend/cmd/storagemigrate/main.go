@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/erodriguezg/meet/pkg/config"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/goccy/go-json"
+)
+
+// Copies the files to the target storage and moves their metadata. The source storages must
+// be configured in STORAGE_TYPE or STORAGE_EXTRA_TYPES, it can be executed again for resume.
+func main() {
+	target := flag.String("to", "", "target storage type: S3, DROPBOX or LOCAL")
+	batchSize := flag.Int("batch", service.StorageMigrationDefaultBatchSize, "files by batch")
+	deleteSource := flag.Bool("delete-source", false, "delete the files from the source storage after migrate them")
+	dryRun := flag.Bool("dry-run", false, "only count the files to migrate")
+	flag.Parse()
+
+	if *target == "" {
+		flag.Usage()
+		return
+	}
+
+	defer config.CloseAll()
+
+	config.ConfigCore()
+
+	report, err := config.GetStorageMigrationService().MigrateFiles(service.StorageMigrationOptions{
+		TargetStorageType: *target,
+		BatchSize:         *batchSize,
+		DeleteSource:      *deleteSource,
+		DryRun:            *dryRun,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(output))
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	fileBlobsCollection  = "fileBlobs"
+	fileBlobsDigestIndex = "digest_storageType_unique"
+)
+
+//go:embed 004_file_blobs.go
+var migration004 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration004,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// the blobs are acquired with upserts, the index avoids duplicates on concurrent uploads
+			_, err := db.Collection(fileBlobsCollection).Indexes().CreateOne(
+				context.TODO(),
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "digest", Value: 1}, {Key: "storageType", Value: 1}},
+					Options: options.Index().SetName(fileBlobsDigestIndex).SetUnique(true),
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			_, err := db.Collection(fileBlobsCollection).Indexes().DropOne(context.TODO(), fileBlobsDigestIndex)
+			return err
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
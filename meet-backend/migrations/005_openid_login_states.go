@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	openIdLoginStatesCollection      = "openIdLoginStates"
+	openIdLoginStatesStateIdIndex    = "stateId_unique"
+	openIdLoginStatesExpirationIndex = "expirationDate_ttl"
+)
+
+//go:embed 005_openid_login_states.go
+var migration005 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration005,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// the unfinished logins are removed by mongo when they expire
+			_, err := db.Collection(openIdLoginStatesCollection).Indexes().CreateMany(
+				context.TODO(),
+				[]mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "stateId", Value: 1}},
+						Options: options.Index().SetName(openIdLoginStatesStateIdIndex).SetUnique(true),
+					},
+					{
+						Keys:    bson.D{{Key: "expirationDate", Value: 1}},
+						Options: options.Index().SetName(openIdLoginStatesExpirationIndex).SetExpireAfterSeconds(0),
+					},
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			return db.Collection(openIdLoginStatesCollection).Drop(context.TODO())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
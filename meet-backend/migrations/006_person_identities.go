@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	personsIdentitiesIndex = "identities_provider_subject_unique"
+)
+
+//go:embed 006_person_identities.go
+var migration006 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration006,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// an account of a provider is linked to only one person
+			_, err := db.Collection(personsCollection).Indexes().CreateOne(
+				context.TODO(),
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+					Options: options.Index().
+						SetName(personsIdentitiesIndex).
+						SetUnique(true).
+						SetPartialFilterExpression(bson.M{"identities.subject": bson.M{"$exists": true}}),
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			_, err := db.Collection(personsCollection).Indexes().DropOne(context.TODO(), personsIdentitiesIndex)
+			return err
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
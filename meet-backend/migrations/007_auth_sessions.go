@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	refreshTokensCollection       = "refreshTokens"
+	revokedAccessTokensCollection = "revokedAccessTokens"
+)
+
+//go:embed 007_auth_sessions.go
+var migration007 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration007,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// the expired tokens are removed by mongo
+			_, err := db.Collection(refreshTokensCollection).Indexes().CreateMany(
+				context.TODO(),
+				[]mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+						Options: options.Index().SetName("tokenHash_unique").SetUnique(true),
+					},
+					{
+						Keys:    bson.D{{Key: "familyId", Value: 1}},
+						Options: options.Index().SetName("familyId"),
+					},
+					{
+						Keys:    bson.D{{Key: "expirationDate", Value: 1}},
+						Options: options.Index().SetName("expirationDate_ttl").SetExpireAfterSeconds(0),
+					},
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = db.Collection(revokedAccessTokensCollection).Indexes().CreateMany(
+				context.TODO(),
+				[]mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "jti", Value: 1}},
+						Options: options.Index().SetName("jti_unique").SetUnique(true),
+					},
+					{
+						Keys:    bson.D{{Key: "expirationDate", Value: 1}},
+						Options: options.Index().SetName("expirationDate_ttl").SetExpireAfterSeconds(0),
+					},
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			err := db.Collection(refreshTokensCollection).Drop(context.TODO())
+			if err != nil {
+				return err
+			}
+			return db.Collection(revokedAccessTokensCollection).Drop(context.TODO())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
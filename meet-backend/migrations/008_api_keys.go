@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	serviceAccountsCollection = "serviceAccounts"
+	apiKeysCollection         = "apiKeys"
+)
+
+//go:embed 008_api_keys.go
+var migration008 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration008,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// the revoked and expired keys are kept for the audit
+			_, err := db.Collection(apiKeysCollection).Indexes().CreateMany(
+				context.TODO(),
+				[]mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "prefix", Value: 1}},
+						Options: options.Index().SetName("prefix_unique").SetUnique(true),
+					},
+					{
+						Keys:    bson.D{{Key: "serviceAccountId", Value: 1}},
+						Options: options.Index().SetName("serviceAccountId"),
+					},
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = db.Collection(serviceAccountsCollection).Indexes().CreateOne(
+				context.TODO(),
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "name", Value: 1}},
+					Options: options.Index().SetName("name_unique").SetUnique(true),
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			err := db.Collection(apiKeysCollection).Drop(context.TODO())
+			if err != nil {
+				return err
+			}
+			return db.Collection(serviceAccountsCollection).Drop(context.TODO())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
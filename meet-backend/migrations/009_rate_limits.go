@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	rateLimitsCollection = "rateLimits"
+)
+
+//go:embed 009_rate_limits.go
+var migration009 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration009,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// a bucket not used in its period is full again, mongo removes it
+			_, err := db.Collection(rateLimitsCollection).Indexes().CreateOne(
+				context.TODO(),
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "expirationDate", Value: 1}},
+					Options: options.Index().SetName("expirationDate_ttl").SetExpireAfterSeconds(0),
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			return db.Collection(rateLimitsCollection).Drop(context.TODO())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
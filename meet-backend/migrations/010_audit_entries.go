@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	auditEntriesCollection = "auditEntries"
+)
+
+//go:embed 010_audit_entries.go
+var migration010 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration010,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// the entries are searched by each filter, the newest first
+			_, err := db.Collection(auditEntriesCollection).Indexes().CreateMany(
+				context.TODO(),
+				[]mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "date", Value: -1}},
+						Options: options.Index().SetName("date"),
+					},
+					{
+						Keys:    bson.D{{Key: "actor.personId", Value: 1}, {Key: "date", Value: -1}},
+						Options: options.Index().SetName("actorPersonId_date"),
+					},
+					{
+						Keys:    bson.D{{Key: "action", Value: 1}, {Key: "date", Value: -1}},
+						Options: options.Index().SetName("action_date"),
+					},
+					{
+						Keys:    bson.D{{Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}, {Key: "date", Value: -1}},
+						Options: options.Index().SetName("target_date"),
+					},
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			return db.Collection(auditEntriesCollection).Drop(context.TODO())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
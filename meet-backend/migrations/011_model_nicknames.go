@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/erodriguezg/go-mongodb-migrate/pkg/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	modelsCollection             = "models"
+	modelsNickNameIndex          = "nickName_unique"
+	modelsPreviousNickNamesIndex = "previousNickNames_nickName"
+)
+
+//go:embed 011_model_nicknames.go
+var migration011 string
+
+func init() {
+
+	err := migrate.Register(
+
+		// Embed source code for hashing
+		&migration011,
+
+		// Up function
+		func(db *mongo.Database) error {
+
+			// the old links are resolved by the previous nicknames, a nickname released after
+			// its reservation can be in the history of several models
+			_, err := db.Collection(modelsCollection).Indexes().CreateMany(
+				context.TODO(),
+				[]mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "nickName", Value: 1}},
+						Options: options.Index().SetName(modelsNickNameIndex).SetUnique(true),
+					},
+					{
+						Keys:    bson.D{{Key: "previousNickNames.nickName", Value: 1}},
+						Options: options.Index().SetName(modelsPreviousNickNamesIndex),
+					},
+				},
+			)
+			return err
+
+		},
+
+		// Down function
+		func(db *mongo.Database) error {
+			for _, index := range []string{modelsNickNameIndex, modelsPreviousNickNamesIndex} {
+				_, err := db.Collection(modelsCollection).Indexes().DropOne(context.TODO(), index)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+
+}
@@ -7,6 +7,7 @@ const (
 	ApiStatusBusinessError = "BUSINESS_ERROR"
 	ApiStatusError         = "ERROR"
 	ApiStatusAccessDenied  = "ACCESS_DENIED"
+	ApiStatusRateLimited   = "RATE_LIMITED"
 )
 
 type ApiResponse[T any] struct {
@@ -53,6 +54,14 @@ func ApiAccessDenied() ApiResponse[any] {
 	}
 }
 
+func ApiRateLimited() ApiResponse[any] {
+	return ApiResponse[any]{
+		Status:  ApiStatusRateLimited,
+		Payload: nil,
+		Error:   nil,
+	}
+}
+
 func ApiBusinessException(exception *exception.BusinessException) ApiResponse[any] {
 	return ApiResponse[any]{
 		Status:  ApiStatusBusinessError,
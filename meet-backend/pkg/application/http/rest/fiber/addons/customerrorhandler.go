@@ -27,6 +27,11 @@ func (port *customFiberErrorHandler) CustomFiberErrorHandler(ctx *fiber.Ctx, err
 		apiResponse := rest.ApiAccessDenied()
 		statusCode := fiber.StatusUnauthorized
 		return ctx.Status(statusCode).JSON(apiResponse)
+	} else if forbiddenError, ok := err.(*fiberidentity.FiberForbiddenError); ok {
+		port.log.Debug("forbidden error: ", zap.Error(forbiddenError))
+		apiResponse := rest.ApiAccessDenied()
+		statusCode := fiber.StatusForbidden
+		return ctx.Status(statusCode).JSON(apiResponse)
 	} else {
 		port.log.Error("api error: ", zap.Error(err))
 		return fiber.DefaultErrorHandler(ctx, err)
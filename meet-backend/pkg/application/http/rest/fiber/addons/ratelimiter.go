@@ -0,0 +1,76 @@
+package addons
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/fiberidentity"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type RateLimiter struct {
+	rateLimitRepository repository.RateLimitRepository
+	securityService     security.HttpSecurityService
+	log                 *zap.Logger
+}
+
+func NewRateLimiter(
+	rateLimitRepository repository.RateLimitRepository,
+	securityService security.HttpSecurityService,
+	log *zap.Logger) *RateLimiter {
+	return &RateLimiter{rateLimitRepository, securityService, log}
+}
+
+// Allow takes a token of the bucket of the key. An error of the repository does not block
+// the requests, it is only logged.
+func (port *RateLimiter) Allow(key string, limit domain.RateLimit) (bool, time.Duration) {
+	allowed, retryAfter, err := port.rateLimitRepository.Take(key, limit)
+	if err != nil {
+		port.log.Error("error at taking the rate limit token", zap.String("key", key), zap.Error(err))
+		return true, 0
+	}
+	return allowed, retryAfter
+}
+
+// Middleware limits the requests of each identity (the ip for the anonymous requests) in
+// the group, the rejected requests get a 429 with the Retry-After header.
+func (port *RateLimiter) Middleware(group string, limit domain.RateLimit) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := group + ":" + port.requesterKey(c)
+		allowed, retryAfter := port.Allow(key, limit)
+		if allowed {
+			return c.Next()
+		}
+		port.log.Debug("rate limited", zap.String("key", key), zap.String("path", c.Path()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(rest.ApiRateLimited())
+	}
+}
+
+// RequesterKey the person, the service account or the ip of the identity
+func RequesterKey(identity *fiberidentity.FiberIdentity, ip string) string {
+	if identity != nil && identity.IsServiceAccount() {
+		return "service-account:" + identity.ServiceAccountId
+	}
+	if identity != nil && identity.PersonId != "" {
+		return "person:" + identity.PersonId
+	}
+	return "ip:" + ip
+}
+
+// private
+
+func (port *RateLimiter) requesterKey(c *fiber.Ctx) string {
+	// the identity is resolved once per request, the handler reuses it
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		identity = nil
+	}
+	return RequesterKey(identity, c.IP())
+}
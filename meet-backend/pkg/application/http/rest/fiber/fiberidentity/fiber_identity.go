@@ -4,7 +4,9 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/service"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -18,6 +20,23 @@ type FiberIdentity struct {
 	ProfileCode      int    `json:"profileCode"`
 	ProfileName      string `json:"profileName"`
 	PermissionsCodes []int  `json:"permissionsCodes"`
+	// jti and expiration of the access token, for revoke it
+	TokenId         string    `json:"-"`
+	TokenExpiration time.Time `json:"-"`
+	// set when a machine client authenticates with an api key, the identity has no person
+	ServiceAccountId string `json:"serviceAccountId,omitempty"`
+}
+
+const (
+	// identityLocalsKey the identity is resolved once per request and kept in the locals
+	identityLocalsKey = "fiberIdentity"
+	bearerScheme      = "Bearer "
+	apiKeyScheme      = "ApiKey "
+)
+
+type identityResult struct {
+	identity *FiberIdentity
+	err      error
 }
 
 type FiberAccessDeniedError struct {
@@ -33,17 +52,35 @@ func NewAccessDeniedError(errorDetail error) error {
 	return &FiberAccessDeniedError{errorDetail}
 }
 
+// FiberForbiddenError the identity is valid but the request is not allowed to it
+type FiberForbiddenError struct {
+	ErrorDetail error
+}
+
+// Error implements error
+func (port *FiberForbiddenError) Error() string {
+	return fmt.Sprintf("Forbidden: %v", port.ErrorDetail)
+}
+
+func NewForbiddenError(errorDetail error) error {
+	return &FiberForbiddenError{errorDetail}
+}
+
 type FiberIdentityUtil struct {
-	personService  service.PersonService
-	profileService service.ProfileService
-	modelService   service.ModelService
-	rsaPublicKey   *rsa.PublicKey
+	personService         service.PersonService
+	profileService        service.ProfileService
+	modelService          service.ModelService
+	authSessionService    service.AuthSessionService
+	serviceAccountService service.ServiceAccountService
+	rsaPublicKey          *rsa.PublicKey
 }
 
 func NewFiberIdentityUtil(
 	personService service.PersonService,
 	profileService service.ProfileService,
 	modelService service.ModelService,
+	authSessionService service.AuthSessionService,
+	serviceAccountService service.ServiceAccountService,
 	rsaPublicKeyBytes []byte,
 ) *FiberIdentityUtil {
 
@@ -56,18 +93,36 @@ func NewFiberIdentityUtil(
 		personService,
 		profileService,
 		modelService,
+		authSessionService,
+		serviceAccountService,
 		rsaPublicKey,
 	}
 }
 
+// GetIdentity the first call of the request validates the token and loads the person,
+// the next calls return the same result.
 func (port *FiberIdentityUtil) GetIdentity(c *fiber.Ctx) (*FiberIdentity, error) {
+	if result, ok := c.Locals(identityLocalsKey).(*identityResult); ok {
+		return result.identity, result.err
+	}
+	identity, err := port.resolveIdentity(c)
+	c.Locals(identityLocalsKey, &identityResult{identity, err})
+	return identity, err
+}
+
+// private
+
+func (port *FiberIdentityUtil) resolveIdentity(c *fiber.Ctx) (*FiberIdentity, error) {
 
 	authHeader := c.GetReqHeaders()["Authorization"]
 
 	tokenHeader := ""
 
 	if len(authHeader) > 0 {
-		tokenHeader = strings.ReplaceAll(authHeader[0], "Bearer ", "")
+		if strings.HasPrefix(authHeader[0], apiKeyScheme) {
+			return port.resolveServiceAccountIdentity(strings.TrimPrefix(authHeader[0], apiKeyScheme))
+		}
+		tokenHeader = strings.ReplaceAll(authHeader[0], bearerScheme, "")
 	}
 
 	token, err := jwt.Parse(tokenHeader, func(token *jwt.Token) (interface{}, error) {
@@ -87,7 +142,20 @@ func (port *FiberIdentityUtil) GetIdentity(c *fiber.Ctx) (*FiberIdentity, error)
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
-	email := claims["email"].(string)
+	email, _ := claims["email"].(string)
+	tokenId, _ := claims["jti"].(string)
+	// the tokens issued before the revocation have no jti and can't be revoked, they are
+	// rejected: the persons logged in before the upgrade must login again
+	if tokenId == "" {
+		return nil, NewAccessDeniedError(fmt.Errorf("the authorization token does not have jti"))
+	}
+	revoked, err := port.authSessionService.IsAccessTokenRevoked(tokenId)
+	if err != nil {
+		return nil, fmt.Errorf("error at checking the revocation of the token: %w", err)
+	}
+	if revoked {
+		return nil, NewAccessDeniedError(fmt.Errorf("the authorization token was revoked"))
+	}
 
 	person, err := port.personService.FindByEmail(email)
 	if err != nil {
@@ -98,6 +166,15 @@ func (port *FiberIdentityUtil) GetIdentity(c *fiber.Ctx) (*FiberIdentity, error)
 		return nil, NewAccessDeniedError(fmt.Errorf("no person was found with email: %s on identity creation", email))
 	}
 
+	if !person.Active {
+		return nil, NewAccessDeniedError(fmt.Errorf("the person with email: %s is not active", email))
+	}
+
+	issuedAt, _ := claims.GetIssuedAt()
+	if person.TokensNotBefore != nil && (issuedAt == nil || issuedAt.Unix() < person.TokensNotBefore.Unix()) {
+		return nil, NewAccessDeniedError(fmt.Errorf("the authorization token was issued before the last change of the person"))
+	}
+
 	profile, err := port.profileService.FindByCode(person.ProfileCode)
 	if err != nil {
 		return nil, NewAccessDeniedError(fmt.Errorf("error at finding profile on identity creation: %w", err))
@@ -115,11 +192,64 @@ func (port *FiberIdentityUtil) GetIdentity(c *fiber.Ctx) (*FiberIdentity, error)
 		ProfileCode:      profile.Code,
 		ProfileName:      profile.Name,
 		PermissionsCodes: profile.PermissionsCodes,
+		TokenId:          tokenId,
+	}
+	if expiration, _ := claims.GetExpirationTime(); expiration != nil {
+		identity.TokenExpiration = expiration.Time
 	}
 
 	return &identity, nil
 }
 
+func (port *FiberIdentityUtil) resolveServiceAccountIdentity(key string) (*FiberIdentity, error) {
+	serviceAccount, apiKey, err := port.serviceAccountService.Authenticate(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("error at authenticating the api key: %w", err)
+	}
+	if serviceAccount == nil {
+		return nil, NewAccessDeniedError(fmt.Errorf("the api key is not valid, expired or revoked"))
+	}
+	identity := FiberIdentity{
+		FirstName:        serviceAccount.Name,
+		PermissionsCodes: apiKey.GrantedPermissions(*serviceAccount),
+		ServiceAccountId: serviceAccount.Id.Hex(),
+	}
+	if apiKey.ExpirationDate != nil {
+		identity.TokenExpiration = *apiKey.ExpirationDate
+	}
+	return &identity, nil
+}
+
+func (port *FiberIdentity) IsServiceAccount() bool {
+	return port.ServiceAccountId != ""
+}
+
+// MustBePerson the service accounts have no person id, they can not do the requests on behalf
+// of a person (purchases, own profile, own models and rooms)
+func (port *FiberIdentity) MustBePerson() error {
+	if port.IsServiceAccount() {
+		return NewForbiddenError(fmt.Errorf("the service account %s is not a person", port.ServiceAccountId))
+	}
+	return nil
+}
+
+// PersonIdRequester the person of the optional identity for the access checks, nil when there
+// is no identity or it is a service account
+func (port *FiberIdentity) PersonIdRequester() *string {
+	if port == nil || port.IsServiceAccount() {
+		return nil
+	}
+	return &port.PersonId
+}
+
+// AuditActor who makes the request, for the audit log. The identity can be nil
+func (port *FiberIdentity) AuditActor(ip string) domain.AuditActor {
+	if port == nil {
+		return domain.AuditActor{Ip: ip}
+	}
+	return domain.AuditActor{PersonId: port.PersonId, ServiceAccountId: port.ServiceAccountId, Ip: ip}
+}
+
 func (port *FiberIdentity) MustHaveProfile(profileCode int) error {
 	if profileCode == port.ProfileCode {
 		return nil
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	auditDefaultPageSize = 50
+	auditMaxPageSize     = 500
+)
+
+var auditCsvHeader = []string{"date", "actorPersonId", "actorServiceAccountId", "actorIp", "actorSystem",
+	"action", "targetType", "targetId", "changes"}
+
+type auditFiberHandler struct {
+	auditService    service.AuditService
+	securityService security.HttpSecurityService
+	log             *zap.Logger
+}
+
+func NewAuditFiberHandler(
+	auditService service.AuditService,
+	securityService security.HttpSecurityService,
+	log *zap.Logger) FiberHandler {
+	return &auditFiberHandler{auditService, securityService, log}
+}
+
+// RegisterRoutes implements FiberHandler
+func (port *auditFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/audit")
+	group.Get("/search", port.searchAudit)
+	group.Get("/export", port.exportAudit)
+}
+
+// privates
+
+// ShowAccount godoc
+// @Summary      Search Audit Entries
+// @Description  The audit entries of the filters, the newest first (admin)
+// @Tags         Audit
+// @Accept       json
+// @Produce      json
+// @Param        actorPersonId  query  string  false  "id of the person that did the action"
+// @Param        action         query  string  false  "action, as person.save"
+// @Param        targetType     query  string  false  "type of the target, as person"
+// @Param        targetId       query  string  false  "id of the target"
+// @Param        dateLower      query  string  false  "from date, RFC3339"
+// @Param        dateUpper      query  string  false  "to date, RFC3339"
+// @Param        first          query  int     false  "index of the first entry, 0 by default"
+// @Param        last           query  int     false  "index of the last entry (exclusive)"
+// @Success      200  {object}  rest.ApiResponse[domain.AuditSearchResponse]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/audit/search [get]
+func (port *auditFiberHandler) searchAudit(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	filters, err := auditFilterFromQuery(c)
+	if err != nil {
+		return err
+	}
+	first := c.QueryInt("first", 0)
+	last := c.QueryInt("last", first+auditDefaultPageSize)
+	if first < 0 || last < first || last-first > auditMaxPageSize {
+		return fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("invalid range first: %d, last: %d (max %d entries)", first, last, auditMaxPageSize))
+	}
+	port.log.Debug("-> searchAudit", zap.Any("filters", filters), zap.Int("first", first), zap.Int("last", last))
+	response, err := port.auditService.Search(filters, first, last)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(response))
+}
+
+// ShowAccount godoc
+// @Summary      Export Audit Entries
+// @Description  The audit entries of the filters as csv, the newest first (admin)
+// @Tags         Audit
+// @Produce      text/csv
+// @Param        actorPersonId  query  string  false  "id of the person that did the action"
+// @Param        action         query  string  false  "action, as person.save"
+// @Param        targetType     query  string  false  "type of the target, as person"
+// @Param        targetId       query  string  false  "id of the target"
+// @Param        dateLower      query  string  false  "from date, RFC3339"
+// @Param        dateUpper      query  string  false  "to date, RFC3339"
+// @Success      200  {object}  string
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/audit/export [get]
+func (port *auditFiberHandler) exportAudit(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	filters, err := auditFilterFromQuery(c)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> exportAudit", zap.Any("filters", filters))
+	entries, err := port.auditService.FindForExport(filters)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="audit-%s.csv"`, time.Now().Format("20060102150405")))
+
+	writer := csv.NewWriter(c)
+	err = writer.Write(auditCsvHeader)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return fmt.Errorf("error at marshal the changes of the audit entry %s: %w", entry.Id.Hex(), err)
+		}
+		err = writer.Write([]string{
+			entry.Date.Format(time.RFC3339),
+			entry.Actor.PersonId,
+			entry.Actor.ServiceAccountId,
+			entry.Actor.Ip,
+			entry.Actor.System,
+			entry.Action,
+			entry.TargetType,
+			entry.TargetId,
+			string(changes),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func auditFilterFromQuery(c *fiber.Ctx) (domain.AuditFilter, error) {
+	var filters domain.AuditFilter
+	filters.ActorPersonId = optionalQuery(c, "actorPersonId")
+	filters.Action = optionalQuery(c, "action")
+	filters.TargetType = optionalQuery(c, "targetType")
+	filters.TargetId = optionalQuery(c, "targetId")
+
+	var err error
+	filters.DateLower, err = optionalDateQuery(c, "dateLower")
+	if err != nil {
+		return filters, err
+	}
+	filters.DateUpper, err = optionalDateQuery(c, "dateUpper")
+	return filters, err
+}
+
+func optionalQuery(c *fiber.Ctx, key string) *string {
+	value := c.Query(key)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func optionalDateQuery(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the %s must be a RFC3339 date: %s", key, value))
+	}
+	return &date, nil
+}
@@ -20,18 +20,36 @@ type buyPackHandler struct {
 type BuyPackDetailsRequest struct {
 	ModelNickName string `json:"modelNickName"`
 	PackNumber    int    `json:"packNumber"`
+	CurrencyCode  string `json:"currencyCode"`
 }
 
 type BuyPackCreateOrderRequest struct {
-	PersonId      string `json:"personId"`
-	ModelNickName string `json:"modelNickName"`
-	PackNumber    int    `json:"packNumber"`
+	PersonId       string  `json:"personId"`
+	ModelNickName  string  `json:"modelNickName"`
+	PackNumber     int     `json:"packNumber"`
+	RecipientEmail *string `json:"recipientEmail,omitempty"`
+	CurrencyCode   string  `json:"currencyCode"`
 }
 
 type BuyPackCreateOrderResponse struct {
 	OrderId string `json:"orderId"`
 }
 
+type BuyBundleDetailsRequest struct {
+	ModelNickName  string  `json:"modelNickName"`
+	BundleNumber   int     `json:"bundleNumber"`
+	RecipientEmail *string `json:"recipientEmail,omitempty"`
+	CurrencyCode   string  `json:"currencyCode"`
+}
+
+type BuyBundleCreateOrderRequest struct {
+	PersonId       string  `json:"personId"`
+	ModelNickName  string  `json:"modelNickName"`
+	BundleNumber   int     `json:"bundleNumber"`
+	RecipientEmail *string `json:"recipientEmail,omitempty"`
+	CurrencyCode   string  `json:"currencyCode"`
+}
+
 type BuyPackCapturePaymentRequest struct {
 	OrderId string `json:"orderId"`
 }
@@ -54,6 +72,8 @@ func (port *buyPackHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 	group.Get("/info", port.getPaymentClientData)
 	group.Post("/details", port.getPackBuyDetails)
 	group.Post("/create-order", port.createBuyPackOrder)
+	group.Post("/bundle/details", port.getBundleBuyDetails)
+	group.Post("/bundle/create-order", port.createBuyBundleOrder)
 	group.Post("/capture-payment", port.capturePackPayment)
 }
 
@@ -78,6 +98,9 @@ func (port *buyPackHandler) getPaymentClientData(c *fiber.Ctx) error {
 	if identity == nil {
 		return fmt.Errorf("identity is nil")
 	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
 	clientData, err := port.buyPackService.GetPaymentClientData()
 	if err != nil {
 		return err
@@ -105,13 +128,16 @@ func (port *buyPackHandler) getPackBuyDetails(c *fiber.Ctx) error {
 	if identity == nil {
 		return fmt.Errorf("identity is nil")
 	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
 
 	var payload BuyPackDetailsRequest
 	err = c.BodyParser(&payload)
 	if err != nil {
 		return err
 	}
-	buyPackDto, err := port.buyPackService.GetPackBuyDetails(payload.ModelNickName, payload.PackNumber)
+	buyPackDto, err := port.buyPackService.GetPackBuyDetails(payload.ModelNickName, payload.PackNumber, payload.CurrencyCode)
 	if err != nil {
 		return err
 	}
@@ -120,7 +146,7 @@ func (port *buyPackHandler) getPackBuyDetails(c *fiber.Ctx) error {
 
 // ShowAccount godoc
 // @Summary      Create Buy Pack Order
-// @Description  Generate a new order for buy a pack
+// @Description  Generate a new order for buy a pack, optionally as a gift for the recipient email
 // @Tags         BuyPack
 // @Accept       json
 // @Produce      json
@@ -138,6 +164,9 @@ func (port *buyPackHandler) createBuyPackOrder(c *fiber.Ctx) error {
 	if identity == nil {
 		return fmt.Errorf("identity is nil")
 	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
 	var payload BuyPackCreateOrderRequest
 	err = c.BodyParser(&payload)
 	if err != nil {
@@ -148,7 +177,86 @@ func (port *buyPackHandler) createBuyPackOrder(c *fiber.Ctx) error {
 		return fiberidentity.NewAccessDeniedError(fmt.Errorf("incompatible personId with session data"))
 	}
 
-	orderId, err := port.buyPackService.CreateBuyPackOrder(payload.PersonId, payload.ModelNickName, payload.PackNumber)
+	orderId, err := port.buyPackService.CreateBuyPackOrder(payload.PersonId, payload.ModelNickName, payload.PackNumber, payload.RecipientEmail, payload.CurrencyCode)
+	if err != nil {
+		return err
+	}
+	responsePayload := BuyPackCreateOrderResponse{
+		OrderId: orderId,
+	}
+	return c.JSON(rest.ApiOk(&responsePayload))
+}
+
+// ShowAccount godoc
+// @Summary      Get Buy Bundle Details
+// @Description  Get info required for buy a bundle, priced without the packs already owned
+// @Tags         BuyPack
+// @Accept       json
+// @Produce      json
+// @Param        data body BuyBundleDetailsRequest true "details buy bundle dto"
+// @Success      200  {object}  dto.PackBundleBuyDetailDto
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/buy-pack/bundle/details [post]
+func (port *buyPackHandler) getBundleBuyDetails(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return fmt.Errorf("identity is nil")
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	var payload BuyBundleDetailsRequest
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	buyBundleDto, err := port.buyPackService.GetBundleBuyDetails(identity.PersonId, payload.ModelNickName, payload.BundleNumber, payload.RecipientEmail, payload.CurrencyCode)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(buyBundleDto))
+}
+
+// ShowAccount godoc
+// @Summary      Create Buy Bundle Order
+// @Description  Generate a new order for buy a bundle of packs, optionally as a gift for the recipient email
+// @Tags         BuyPack
+// @Accept       json
+// @Produce      json
+// @Param        data body BuyBundleCreateOrderRequest true "Create Order Data"
+// @Success      200  {object}  BuyPackCreateOrderResponse
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/buy-pack/bundle/create-order [post]
+func (port *buyPackHandler) createBuyBundleOrder(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return fmt.Errorf("identity is nil")
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+	var payload BuyBundleCreateOrderRequest
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	if identity.PersonId != payload.PersonId {
+		return fiberidentity.NewAccessDeniedError(fmt.Errorf("incompatible personId with session data"))
+	}
+
+	orderId, err := port.buyPackService.CreateBuyBundleOrder(payload.PersonId, payload.ModelNickName, payload.BundleNumber, payload.RecipientEmail, payload.CurrencyCode)
 	if err != nil {
 		return err
 	}
@@ -178,6 +286,9 @@ func (port *buyPackHandler) capturePackPayment(c *fiber.Ctx) error {
 	if identity == nil {
 		return fmt.Errorf("identity is nil")
 	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
 
 	var payload BuyPackCapturePaymentRequest
 	err = c.BodyParser(&payload)
@@ -185,7 +296,7 @@ func (port *buyPackHandler) capturePackPayment(c *fiber.Ctx) error {
 		return err
 	}
 
-	err = port.buyPackService.CapturePackPayment(payload.OrderId)
+	err = port.buyPackService.CapturePackPayment(payload.OrderId, identity.AuditActor(c.IP()))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type cacheMetricsFiberHandler struct {
+	securityService security.HttpSecurityService
+	caches          map[string]ttlcache.StatsProvider
+	log             *zap.Logger
+}
+
+func NewCacheMetricsFiberHandler(
+	securityService security.HttpSecurityService,
+	caches map[string]ttlcache.StatsProvider,
+	log *zap.Logger) FiberHandler {
+	return &cacheMetricsFiberHandler{securityService, caches, log}
+}
+
+// RegisterRoutes implements FiberHandler
+func (port *cacheMetricsFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/system")
+	group.Get("/cache-metrics", port.getCacheMetrics)
+}
+
+// privates
+
+// ShowAccount godoc
+// @Summary      Cache Metrics
+// @Description  Hits, misses and size of the caches by name (admin)
+// @Tags         System
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  map[string]ttlcache.Stats
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/system/cache-metrics [get]
+func (port *cacheMetricsFiberHandler) getCacheMetrics(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> getCacheMetrics")
+	metrics := make(map[string]ttlcache.Stats, len(port.caches))
+	for name, cache := range port.caches {
+		metrics[name] = cache.GetStats()
+	}
+	return c.JSON(metrics)
+}
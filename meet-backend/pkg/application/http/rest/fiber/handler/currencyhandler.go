@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type currencyFiberHandler struct {
+	currencyService service.CurrencyService
+	securityService security.HttpSecurityService
+	validate        *validator.Validate
+	log             *zap.Logger
+}
+
+type SaveExchangeRateDto struct {
+	CurrencyCode string  `json:"currencyCode" validate:"required,len=3"`
+	UnitsPerUSD  float64 `json:"unitsPerUsd" validate:"required,gt=0"`
+}
+
+type ImportExchangeRatesResultDto struct {
+	Imported int `json:"imported"`
+}
+
+func NewCurrencyFiberHandler(
+	currencyService service.CurrencyService,
+	securityService security.HttpSecurityService,
+	validate *validator.Validate,
+	log *zap.Logger,
+) FiberHandler {
+	return &currencyFiberHandler{currencyService, securityService, validate, log}
+}
+
+func (port *currencyFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/currency")
+	group.Get("/rates", port.findAllRates)
+	group.Post("/rates", port.saveRate)
+	group.Post("/rates/import", port.importRatesCsv)
+	group.Delete("/rates/:currencyCode", port.deleteRate)
+}
+
+// ShowAccount godoc
+// @Summary      Find Exchange Rates
+// @Description  Find all the exchange rates, expressed as units of the currency per one dollar
+// @Tags         Currency
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  rest.ApiResponse[[]domain.ExchangeRate]
+// @Failure      400  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/currency/rates [get]
+func (port *currencyFiberHandler) findAllRates(c *fiber.Ctx) error {
+	port.log.Debug("-> findAllRates")
+	rates, err := port.currencyService.FindAllRates()
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkArray(rates))
+}
+
+// ShowAccount godoc
+// @Summary      Save Exchange Rate
+// @Description  Create or update the exchange rate of one currency (admin)
+// @Tags         Currency
+// @Accept       json
+// @Produce      json
+// @Param        data body SaveExchangeRateDto true "Payload Data"
+// @Success      200  {object}  rest.ApiResponse[domain.ExchangeRate]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/currency/rates [post]
+func (port *currencyFiberHandler) saveRate(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+
+	var payload SaveExchangeRateDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> saveRate", zap.Any("payload", payload))
+	rate, err := port.currencyService.SaveRate(payload.CurrencyCode, payload.UnitsPerUSD)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(rate))
+}
+
+// ShowAccount godoc
+// @Summary      Import Exchange Rates
+// @Description  Import exchange rates from a csv file with lines "currencyCode,unitsPerUsd" (admin)
+// @Tags         Currency
+// @Accept       mpfd
+// @Produce      json
+// @Param        file formData file true "csv file"
+// @Success      200  {object}  rest.ApiResponse[ImportExchangeRatesResultDto]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/currency/rates/import [post]
+func (port *currencyFiberHandler) importRatesCsv(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	port.log.Debug("-> importRatesCsv", zap.String("fileName", fileHeader.Filename))
+	imported, err := port.currencyService.ImportRatesCsv(file)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(&ImportExchangeRatesResultDto{Imported: imported}))
+}
+
+// ShowAccount godoc
+// @Summary      Delete Exchange Rate
+// @Description  Delete the exchange rate of one currency (admin)
+// @Tags         Currency
+// @Accept       json
+// @Produce      json
+// @Param        currencyCode   path     string  true  "currency code"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/currency/rates/{currencyCode} [delete]
+func (port *currencyFiberHandler) deleteRate(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+
+	currencyCodeParam := c.Params("currencyCode")
+	port.log.Debug("-> deleteRate", zap.String("currencyCode", currencyCodeParam))
+	err = port.currencyService.DeleteRate(currencyCodeParam)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
@@ -1,25 +1,46 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
+	"time"
+
 	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/fiberidentity"
 	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 type fileFiberHandler struct {
 	fileService     service.FileService
+	packService     service.PackService
+	fileGcService   service.FileGcService
 	securityService security.HttpSecurityService
+	validate        *validator.Validate
 	log             *zap.Logger
 }
 
+type StartMultipartUploadRequestDto struct {
+	Size int64 `json:"size" validate:"required,gt=0"`
+}
+
+type RegisterMultipartPartRequestDto struct {
+	ETag string `json:"etag" validate:"required"`
+}
+
 func NewFileFiberHandler(
 	fileService service.FileService,
+	packService service.PackService,
+	fileGcService service.FileGcService,
 	securityService security.HttpSecurityService,
+	validate *validator.Validate,
 	log *zap.Logger,
 ) FiberHandler {
-	return &fileFiberHandler{fileService, securityService, log}
+	return &fileFiberHandler{fileService, packService, fileGcService, securityService, validate, log}
 }
 
 func (port *fileFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
@@ -29,6 +50,12 @@ func (port *fileFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 	group.Get("/redirect/:hash", port.redirectDownloadUrl)
 	group.Get("/get/:hash", port.getDownloadUrl)
 	group.Post("/confirm/:hash", port.confirmUploaded)
+	group.Post("/gc", port.collectGarbage)
+	group.Post("/multipart/:hash/start", port.startMultipartUpload)
+	group.Get("/multipart/:hash/part/:partNumber", port.getMultipartPartUploadUrl)
+	group.Post("/multipart/:hash/part/:partNumber", port.registerMultipartPart)
+	group.Post("/multipart/:hash/complete", port.completeMultipartUpload)
+	group.Delete("/multipart/:hash", port.abortMultipartUpload)
 }
 
 // ShowAccount godoc
@@ -50,7 +77,7 @@ func (port *fileFiberHandler) getStorageType(c *fiber.Ctx) error {
 
 // ShowAccount godoc
 // @Summary      Get Download Url
-// @Description  Get the download url from hash
+// @Description  Get the download url from hash, the url of paid resources is short-lived
 // @Tags         File
 // @Accept       json
 // @Param        hash  path     string  true  "unique hash for the file"
@@ -63,7 +90,7 @@ func (port *fileFiberHandler) getDownloadUrl(c *fiber.Ctx) error {
 	hashParam := c.Params("hash")
 	port.log.Debug("-> getDownloadUrl", zap.String("hash", hashParam))
 
-	downloadUrl, err := port.fileService.GetDownloadUrl(hashParam)
+	downloadUrl, err := port.getDownloadUrlForRequester(c, hashParam)
 	if err != nil {
 		return err
 	}
@@ -86,7 +113,7 @@ func (port *fileFiberHandler) redirectDownloadUrl(c *fiber.Ctx) error {
 	hashParam := c.Params("hash")
 	port.log.Debug("-> redirectDownloadUrl", zap.String("hash", hashParam))
 
-	downloadUrl, err := port.fileService.GetDownloadUrl(hashParam)
+	downloadUrl, err := port.getDownloadUrlForRequester(c, hashParam)
 	if err != nil {
 		return err
 	}
@@ -95,7 +122,7 @@ func (port *fileFiberHandler) redirectDownloadUrl(c *fiber.Ctx) error {
 
 // ShowAccount godoc
 // @Summary      Confirm File Uploaded
-// @Description  Confirm the file was uploaded
+// @Description  Confirm the file was uploaded, only by the person who requested the upload
 // @Tags         File
 // @Accept       json
 // @Produce      json
@@ -107,17 +134,266 @@ func (port *fileFiberHandler) redirectDownloadUrl(c *fiber.Ctx) error {
 // @Router       /v1/file/confirm/{hash} [post]
 func (port *fileFiberHandler) confirmUploaded(c *fiber.Ctx) error {
 
-	_, err := port.securityService.GetIdentity(c)
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	hashParam := c.Params("hash")
+
+	err = port.fileService.ConfirmUploaded(hashParam, identity.PersonId)
+	if err != nil {
+		return port.mapFileAccessError(err)
+	}
+
+	return c.JSON(rest.ApiOkEmpty())
+}
+
+// ShowAccount godoc
+// @Summary      Collect Garbage Files
+// @Description  Delete the abandoned uploads and the files not referenced by packs or models (admin). By default only reports them
+// @Tags         File
+// @Accept       json
+// @Produce      json
+// @Param        dryRun  query     bool  false  "only report, default true"
+// @Param        minAgeHours  query     int  false  "minimum age of the collected files, default 24"
+// @Success      200  {object}  rest.ApiResponse[dto.FileGcReportDto]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/file/gc [post]
+func (port *fileFiberHandler) collectGarbage(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+
+	dryRun := c.QueryBool("dryRun", true)
+	minAge := service.FileGcDefaultMinAge
+	if minAgeHours := c.QueryInt("minAgeHours", 0); minAgeHours > 0 {
+		minAge = time.Duration(minAgeHours) * time.Hour
+	}
+
+	port.log.Debug("-> collectGarbage", zap.Bool("dryRun", dryRun), zap.Duration("minAge", minAge))
+	report, err := port.fileGcService.CollectGarbage(dryRun, minAge)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(report))
+}
+
+// ShowAccount godoc
+// @Summary      Start Multipart Upload
+// @Description  Start the upload in parts of a large file, or get the state of the upload already started for resume it
+// @Tags         File
+// @Accept       json
+// @Produce      json
+// @Param        hash  path     string  true  "unique hash for the file"
+// @Param        data body StartMultipartUploadRequestDto true "Payload Data"
+// @Success      200  {object}  rest.ApiResponse[domain.FileMultipartUpload]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/file/multipart/{hash}/start [post]
+func (port *fileFiberHandler) startMultipartUpload(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	hashParam := c.Params("hash")
+	var payload StartMultipartUploadRequestDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> startMultipartUpload", zap.String("hash", hashParam), zap.Int64("size", payload.Size))
+	upload, err := port.fileService.StartMultipartUpload(hashParam, identity.PersonId, payload.Size)
+	if err != nil {
+		return port.mapFileAccessError(err)
+	}
+	return c.JSON(rest.ApiOk(upload))
+}
+
+// ShowAccount godoc
+// @Summary      Get Multipart Part Upload Url
+// @Description  Get the url for PUT one part of the file, the ETag header of the response must be registered
+// @Tags         File
+// @Accept       json
+// @Produce      json
+// @Param        hash  path     string  true  "unique hash for the file"
+// @Param        partNumber  path     int  true  "part number, from 1"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/file/multipart/{hash}/part/{partNumber} [get]
+func (port *fileFiberHandler) getMultipartPartUploadUrl(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	hashParam := c.Params("hash")
+	partNumber, err := strconv.Atoi(c.Params("partNumber"))
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> getMultipartPartUploadUrl", zap.String("hash", hashParam), zap.Int("partNumber", partNumber))
+	uploadUrl, err := port.fileService.GetMultipartPartUploadUrl(hashParam, identity.PersonId, partNumber)
+	if err != nil {
+		return port.mapFileAccessError(err)
+	}
+	output := map[string]string{"url": uploadUrl}
+	return c.JSON(rest.ApiOk(&output))
+}
+
+// ShowAccount godoc
+// @Summary      Register Multipart Part
+// @Description  Register the ETag of an uploaded part
+// @Tags         File
+// @Accept       json
+// @Produce      json
+// @Param        hash  path     string  true  "unique hash for the file"
+// @Param        partNumber  path     int  true  "part number, from 1"
+// @Param        data body RegisterMultipartPartRequestDto true "Payload Data"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/file/multipart/{hash}/part/{partNumber} [post]
+func (port *fileFiberHandler) registerMultipartPart(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	hashParam := c.Params("hash")
+	partNumber, err := strconv.Atoi(c.Params("partNumber"))
+	if err != nil {
+		return err
+	}
+	var payload RegisterMultipartPartRequestDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> registerMultipartPart", zap.String("hash", hashParam), zap.Int("partNumber", partNumber))
+	err = port.fileService.RegisterMultipartPart(hashParam, identity.PersonId,
+		domain.FileUploadPart{PartNumber: partNumber, ETag: payload.ETag})
+	if err != nil {
+		return port.mapFileAccessError(err)
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
+
+// ShowAccount godoc
+// @Summary      Complete Multipart Upload
+// @Description  Join the uploaded parts and confirm the file
+// @Tags         File
+// @Accept       json
+// @Produce      json
+// @Param        hash  path     string  true  "unique hash for the file"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/file/multipart/{hash}/complete [post]
+func (port *fileFiberHandler) completeMultipartUpload(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
 	if err != nil {
 		return err
 	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
 
 	hashParam := c.Params("hash")
+	port.log.Debug("-> completeMultipartUpload", zap.String("hash", hashParam))
+	err = port.fileService.CompleteMultipartUpload(hashParam, identity.PersonId)
+	if err != nil {
+		return port.mapFileAccessError(err)
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
 
-	err = port.fileService.ConfirmUploaded(hashParam)
+// ShowAccount godoc
+// @Summary      Abort Multipart Upload
+// @Description  Cancel the upload in parts and delete the uploaded parts
+// @Tags         File
+// @Accept       json
+// @Produce      json
+// @Param        hash  path     string  true  "unique hash for the file"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/file/multipart/{hash} [delete]
+func (port *fileFiberHandler) abortMultipartUpload(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
 	if err != nil {
 		return err
 	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
 
+	hashParam := c.Params("hash")
+	port.log.Debug("-> abortMultipartUpload", zap.String("hash", hashParam))
+	err = port.fileService.AbortMultipartUpload(hashParam, identity.PersonId)
+	if err != nil {
+		return port.mapFileAccessError(err)
+	}
 	return c.JSON(rest.ApiOkEmpty())
 }
+
+// private
+
+func (port *fileFiberHandler) mapFileAccessError(err error) error {
+	var accessErr *service.FileAccessDeniedError
+	if errors.As(err, &accessErr) {
+		return fiberidentity.NewAccessDeniedError(accessErr)
+	}
+	return err
+}
+
+func (port *fileFiberHandler) getDownloadUrlForRequester(c *fiber.Ctx, hash string) (string, error) {
+	var personIdRequester *string
+	identity, err := port.securityService.GetIdentity(c)
+	if err == nil {
+		personIdRequester = identity.PersonIdRequester()
+	}
+
+	downloadUrl, err := port.packService.GetFileDownloadUrl(hash, personIdRequester)
+	if err != nil {
+		var accessErr *service.PackFileAccessDeniedError
+		if errors.As(err, &accessErr) {
+			return "", fiberidentity.NewAccessDeniedError(accessErr)
+		}
+		return "", err
+	}
+	return downloadUrl, nil
+}
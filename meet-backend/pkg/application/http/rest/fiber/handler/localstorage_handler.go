@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// LocalStorageBackend is the part of the local filesystem storage needed for serve
+// the signed upload and download urls.
+type LocalStorageBackend interface {
+	VerifySignedUrl(method string, path string, expires string, signature string) error
+
+	WriteFile(path string, reader io.Reader) error
+
+	OpenFile(path string) (io.ReadCloser, error)
+}
+
+type localStorageFiberHandler struct {
+	storage LocalStorageBackend
+	log     *zap.Logger
+}
+
+func NewLocalStorageFiberHandler(
+	storage LocalStorageBackend,
+	log *zap.Logger,
+) FiberHandler {
+	return &localStorageFiberHandler{storage, log}
+}
+
+func (port *localStorageFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/local-storage")
+	group.Put("/*", port.uploadFile)
+	group.Get("/*", port.downloadFile)
+}
+
+// ShowAccount godoc
+// @Summary      Upload File
+// @Description  Upload a file to the local storage using a signed url
+// @Tags         LocalStorage
+// @Accept       octet-stream
+// @Param        path  path     string  true  "file path"
+// @Param        expires  query     int  true  "unix time of expiration"
+// @Param        signature  query     string  true  "url signature"
+// @Success      200  {object}  string
+// @Failure      403  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/local-storage/{path} [put]
+func (port *localStorageFiberHandler) uploadFile(c *fiber.Ctx) error {
+	path, err := port.verifiedPath(c)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> uploadFile", zap.String("path", path))
+	err = port.storage.WriteFile(path, bytes.NewReader(c.Body()))
+	if err != nil {
+		return err
+	}
+	// the etag identifies the parts of the multipart uploads
+	checksum := sha256.Sum256(c.Body())
+	c.Set(fiber.HeaderETag, `"`+hex.EncodeToString(checksum[:])+`"`)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// ShowAccount godoc
+// @Summary      Download File
+// @Description  Download a file from the local storage using a signed url
+// @Tags         LocalStorage
+// @Param        path  path     string  true  "file path"
+// @Param        expires  query     int  true  "unix time of expiration, 0 for no expiration"
+// @Param        signature  query     string  true  "url signature"
+// @Success      200  {object}  string
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/local-storage/{path} [get]
+func (port *localStorageFiberHandler) downloadFile(c *fiber.Ctx) error {
+	path, err := port.verifiedPath(c)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> downloadFile", zap.String("path", path))
+	file, err := port.storage.OpenFile(path)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "file not found")
+	}
+	c.Type(filepath.Ext(path))
+	return c.SendStream(file)
+}
+
+// private
+
+func (port *localStorageFiberHandler) verifiedPath(c *fiber.Ctx) (string, error) {
+	path, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "invalid path")
+	}
+	err = port.storage.VerifySignedUrl(c.Method(), path, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	return path, nil
+}
@@ -0,0 +1,68 @@
+package handler_test
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/handler"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository/repositorytest"
+	"github.com/erodriguezg/meet/pkg/infrastructure/localfs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func startLocalStorage(t *testing.T) localfs.LocalStorage {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	storage := localfs.NewStorageLocal(localfs.LocalStorageConfig{
+		BaseDir:    t.TempDir(),
+		PublicUrl:  "http://" + listener.Addr().String() + "/api/v1/local-storage",
+		SigningKey: []byte("test-signing-key"),
+	}, zap.NewNop())
+
+	app := fiber.New()
+	v1 := app.Group("/api/v1")
+	handler.NewLocalStorageFiberHandler(storage, zap.NewNop()).RegisterRoutes(&v1)
+
+	go func() {
+		_ = app.Listener(listener)
+	}()
+	t.Cleanup(func() {
+		_ = app.Shutdown()
+	})
+	return storage
+}
+
+func TestLocalStorageContract(t *testing.T) {
+	storage := startLocalStorage(t)
+	repositorytest.RunStorageRepositoryContract(t, storage, http.DefaultClient)
+}
+
+func TestLocalStorageRejectsTamperedUrl(t *testing.T) {
+	storage := startLocalStorage(t)
+
+	uploadUrl, err := storage.GetFileUploadUrl(domain.FileMetaData{Path: "dir/file.txt"})
+	require.NoError(t, err)
+
+	tamperedUrl := strings.Replace(uploadUrl, "dir/file.txt", "dir/other.txt", 1)
+	request, err := http.NewRequest(http.MethodPut, tamperedUrl, strings.NewReader("content"))
+	require.NoError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestLocalStorageRejectsPathTraversal(t *testing.T) {
+	storage := startLocalStorage(t)
+
+	_, err := storage.GetFileUploadUrl(domain.FileMetaData{Path: "dir/../../file.txt"})
+	assert.ErrorIs(t, err, localfs.ErrInvalidPath)
+}
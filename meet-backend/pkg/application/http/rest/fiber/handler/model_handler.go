@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type modelFiberHandler struct {
+	modelService    service.ModelService
+	securityService security.HttpSecurityService
+	validate        *validator.Validate
+	log             *zap.Logger
+}
+
+func NewModelFiberHandler(
+	modelService service.ModelService,
+	securityService security.HttpSecurityService,
+	validate *validator.Validate,
+	log *zap.Logger) FiberHandler {
+	return &modelFiberHandler{modelService, securityService, validate, log}
+}
+
+// RegisterRoutes implements FiberHandler
+func (port *modelFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/model")
+	group.Post("/:modelNickName/profile", port.updateModelProfile)
+	group.Post("/:modelNickName/profile-image/prepare-upload", port.prepareUploadProfileImage)
+	group.Post("/:modelNickName/profile-image/confirm", port.confirmProfileImage)
+	group.Post("/:modelNickName/nickname", port.changeNickName)
+}
+
+// privates
+
+// ShowAccount godoc
+// @Summary      Update Model Profile
+// @Description  Update the about me, country, city and zodiac sign of the own model, the empty fields are removed
+// @Tags         Model
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName  path  string  true  "nickname of the model"
+// @Param        data body dto.ModelProfileUpdateDto true "The profile"
+// @Success      200  {object}  rest.ApiResponse[domain.Model]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/model/{modelNickName}/profile [post]
+func (port *modelFiberHandler) updateModelProfile(c *fiber.Ctx) error {
+	// the service checks that the model is of the person
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+	var payload dto.ModelProfileUpdateDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	payload.Normalize()
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	modelNickName := c.Params("modelNickName")
+	port.log.Debug("-> updateModelProfile", zap.String("modelNickName", modelNickName), zap.Any("payload", payload))
+	model, err := port.modelService.UpdateModelProfile(modelNickName, payload, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(model))
+}
+
+// ShowAccount godoc
+// @Summary      Prepare Upload Profile Image
+// @Description  Prepare the upload of a new profile image (and its thumbnail) of the own model
+// @Tags         Model
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName  path  string  true  "nickname of the model"
+// @Success      200  {object}  rest.ApiResponse[[]dto.ResourceUploadUrlDto]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/model/{modelNickName}/profile-image/prepare-upload [post]
+func (port *modelFiberHandler) prepareUploadProfileImage(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+	modelNickName := c.Params("modelNickName")
+	port.log.Debug("-> prepareUploadProfileImage", zap.String("modelNickName", modelNickName))
+	uploadResources, err := port.modelService.PrepareUploadUrlForProfileImage(modelNickName, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkArray(uploadResources))
+}
+
+// ShowAccount godoc
+// @Summary      Confirm Profile Image
+// @Description  Confirm the upload of the new profile image, it replaces the current one
+// @Tags         Model
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName  path  string  true  "nickname of the model"
+// @Success      200  {object}  rest.ApiResponse[domain.Model]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/model/{modelNickName}/profile-image/confirm [post]
+func (port *modelFiberHandler) confirmProfileImage(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+	modelNickName := c.Params("modelNickName")
+	port.log.Debug("-> confirmProfileImage", zap.String("modelNickName", modelNickName))
+	model, err := port.modelService.ConfirmProfileImage(modelNickName, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(model))
+}
+
+// ShowAccount godoc
+// @Summary      Change Model Nickname
+// @Description  Change the nickname of the own model, the links with the previous nickname keep working
+// @Tags         Model
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName  path  string  true  "nickname of the model"
+// @Param        data body dto.ModelNickNameChangeDto true "The new nickname"
+// @Success      200  {object}  rest.ApiResponse[domain.Model]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/model/{modelNickName}/nickname [post]
+func (port *modelFiberHandler) changeNickName(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+	var payload dto.ModelNickNameChangeDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	modelNickName := c.Params("modelNickName")
+	port.log.Debug("-> changeNickName", zap.String("modelNickName", modelNickName), zap.String("newNickName", payload.NickName))
+	model, err := port.modelService.ChangeNickName(modelNickName, payload.NickName, identity.PersonId, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(model))
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type packBundleFiberHandler struct {
+	packBundleService service.PackBundleService
+	securityService   security.HttpSecurityService
+	validate          *validator.Validate
+	log               *zap.Logger
+}
+
+type PackBundleRequestDto struct {
+	ModelNickName string `json:"modelNickName"`
+	BundleNumber  int    `json:"bundleNumber"`
+}
+
+func NewPackBundleFiberHandler(
+	packBundleService service.PackBundleService,
+	securityService security.HttpSecurityService,
+	validate *validator.Validate,
+	log *zap.Logger,
+) FiberHandler {
+	return &packBundleFiberHandler{packBundleService, securityService, validate, log}
+}
+
+func (port *packBundleFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/pack-bundle")
+	group.Post("/publish", port.publishBundle)
+	group.Post("/:modelNickName/new", port.createBundle)
+	group.Post("/:modelNickName/:bundleNumber", port.updateBundle)
+	group.Delete("/:modelNickName/:bundleNumber", port.deleteBundle)
+	group.Get("/:modelNickName", port.getBundlesFromModel)
+}
+
+// ShowAccount godoc
+// @Summary      Create Pack Bundle
+// @Description  Create a new bundle with packs of the model sold at a combined price
+// @Tags         PackBundle
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName   path     string  true  "model nickname"
+// @Param        data body dto.SavePackBundleDto true "Payload Data"
+// @Success      200  {object}  rest.ApiResponse[dto.PackBundleDto]
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/pack-bundle/{modelNickName}/new [post]
+func (port *packBundleFiberHandler) createBundle(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	modelNickNameParam := c.Params("modelNickName")
+	var payload dto.SavePackBundleDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> createBundle", zap.String("modelNickName", modelNickNameParam), zap.Any("payload", payload))
+	bundle, err := port.packBundleService.CreateBundle(modelNickNameParam, payload, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(bundle))
+}
+
+// ShowAccount godoc
+// @Summary      Update Pack Bundle
+// @Description  Update the title, packs and price of a bundle
+// @Tags         PackBundle
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName   path     string  true  "model nickname"
+// @Param        bundleNumber   path     int  true  "bundle number"
+// @Param        data body dto.SavePackBundleDto true "Payload Data"
+// @Success      200  {object}  rest.ApiResponse[dto.PackBundleDto]
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/pack-bundle/{modelNickName}/{bundleNumber} [post]
+func (port *packBundleFiberHandler) updateBundle(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	modelNickNameParam := c.Params("modelNickName")
+	bundleNumber, err := strconv.Atoi(c.Params("bundleNumber"))
+	if err != nil {
+		return err
+	}
+
+	var payload dto.SavePackBundleDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> updateBundle",
+		zap.String("modelNickName", modelNickNameParam),
+		zap.Int("bundleNumber", bundleNumber),
+		zap.Any("payload", payload))
+	bundle, err := port.packBundleService.UpdateBundle(modelNickNameParam, bundleNumber, payload, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(bundle))
+}
+
+// ShowAccount godoc
+// @Summary      Publish Pack Bundle
+// @Description  Publish the bundle, all the packs of the bundle must be published
+// @Tags         PackBundle
+// @Accept       json
+// @Produce      json
+// @Param        data body PackBundleRequestDto true "Payload Data"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/pack-bundle/publish [post]
+func (port *packBundleFiberHandler) publishBundle(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	var payload PackBundleRequestDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> publishBundle", zap.Any("payload", payload))
+	err = port.packBundleService.PublishBundle(payload.ModelNickName, payload.BundleNumber, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
+
+// ShowAccount godoc
+// @Summary      Delete Pack Bundle
+// @Description  Delete one bundle, the packs are not modified
+// @Tags         PackBundle
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName   path     string  true  "model nickname"
+// @Param        bundleNumber   path     int  true  "bundle number"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/pack-bundle/{modelNickName}/{bundleNumber} [delete]
+func (port *packBundleFiberHandler) deleteBundle(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	modelNickNameParam := c.Params("modelNickName")
+	bundleNumber, err := strconv.Atoi(c.Params("bundleNumber"))
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> deleteBundle",
+		zap.String("modelNickName", modelNickNameParam),
+		zap.Int("bundleNumber", bundleNumber))
+	err = port.packBundleService.DeleteBundle(modelNickNameParam, bundleNumber, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
+
+// ShowAccount godoc
+// @Summary      Get Bundles From Model
+// @Description  Get the bundles of one model, unpublished bundles only for the model or admin
+// @Tags         PackBundle
+// @Accept       json
+// @Produce      json
+// @Param        modelNickName   path     string  true  "model nickname"
+// @Success      200  {object}  rest.ApiResponse[[]dto.PackBundleDto]
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/pack-bundle/{modelNickName} [get]
+func (port *packBundleFiberHandler) getBundlesFromModel(c *fiber.Ctx) error {
+	modelNickNameParam := c.Params("modelNickName")
+
+	var personIdRequester *string
+	identity, err := port.securityService.GetIdentity(c)
+	if err == nil {
+		personIdRequester = identity.PersonIdRequester()
+	}
+
+	port.log.Debug("-> getBundlesFromModel",
+		zap.String("modelNickName", modelNickNameParam),
+		zap.Any("personIdRequester", personIdRequester))
+
+	bundles, err := port.packBundleService.GetBundlesFromModel(modelNickNameParam, personIdRequester)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkArray(bundles))
+}
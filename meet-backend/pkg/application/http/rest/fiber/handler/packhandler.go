@@ -39,6 +39,11 @@ type EditPackDescriptionDto struct {
 	Description string `json:"description" validate:"max=280"`
 }
 
+type EditPackPricesDto struct {
+	DollarValue float64            `json:"dollarValue" validate:"required,gt=0"`
+	Prices      map[string]float64 `json:"prices" validate:"dive,keys,len=3,endkeys,gt=0"`
+}
+
 func NewPackFiberHandler(
 	packService service.PackService,
 	securityService security.HttpSecurityService,
@@ -59,6 +64,7 @@ func (port *packFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 	group.Post("/publish", port.publishPack)
 	group.Post("/:modelNickName/:packNumber/title", port.editPackTitle)
 	group.Post("/:modelNickName/:packNumber/description", port.editPackDescription)
+	group.Post("/:modelNickName/:packNumber/prices", port.editPackPrices)
 	group.Get("/:modelNickName/:packNumber/info", port.getPackInfo)
 	group.Get("/:modelNickName/:packNumber/items", port.getItemsFromPack)
 	group.Get("/:modelNickName", port.getPacksFromModel)
@@ -164,13 +170,22 @@ func (port *packFiberHandler) deletePack(c *fiber.Ctx) error {
 // @Failure      500  {object}  error
 // @Router       /v1/pack/prepare-upload-item [post]
 func (port *packFiberHandler) prepareUploadForPackItem(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
 	var payload PrepareUploadPackItemDto
-	err := c.BodyParser(&payload)
+	err = c.BodyParser(&payload)
 	if err != nil {
 		return err
 	}
 	port.log.Debug("-> prepareUploadForPackItem", zap.Any("payload", payload))
-	uploadResources, err := port.packService.PrepareUploadForPackItem(payload.ModelNickName, payload.PackNumber, payload.TypeCode, payload.IsPublic)
+	uploadResources, err := port.packService.PrepareUploadForPackItem(payload.ModelNickName, payload.PackNumber, payload.TypeCode, payload.IsPublic,
+		identity.PersonId)
 	if err != nil {
 		return err
 	}
@@ -219,15 +234,20 @@ func (port *packFiberHandler) readyToPublishPack(c *fiber.Ctx) error {
 // @Failure      500  {object}  error
 // @Router       /v1/pack/publish [post]
 func (port *packFiberHandler) publishPack(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+
 	var payload PackDto
-	err := c.BodyParser(&payload)
+	err = c.BodyParser(&payload)
 	if err != nil {
 		return err
 	}
 
 	port.log.Debug("-> publishPack", zap.Any("payload", payload))
 
-	err = port.packService.PublishPack(payload.ModelNickName, payload.PackNumber)
+	err = port.packService.PublishPack(payload.ModelNickName, payload.PackNumber, identity.AuditActor(c.IP()))
 	if err != nil {
 		return err
 	}
@@ -259,10 +279,8 @@ func (port *packFiberHandler) getPackInfo(c *fiber.Ctx) error {
 
 	var personIdRequester *string
 	identity, err := port.securityService.GetIdentity(c)
-	if err != nil {
-		personIdRequester = nil
-	} else {
-		personIdRequester = &identity.PersonId
+	if err == nil {
+		personIdRequester = identity.PersonIdRequester()
 	}
 
 	port.log.Debug("-> getPackInfo",
@@ -302,10 +320,8 @@ func (port *packFiberHandler) getItemsFromPack(c *fiber.Ctx) error {
 
 	var personIdRequester *string
 	identity, err := port.securityService.GetIdentity(c)
-	if err != nil {
-		personIdRequester = nil
-	} else {
-		personIdRequester = &identity.PersonId
+	if err == nil {
+		personIdRequester = identity.PersonIdRequester()
 	}
 
 	port.log.Debug("-> getItemsFromPack",
@@ -338,10 +354,8 @@ func (port *packFiberHandler) getPacksFromModel(c *fiber.Ctx) error {
 
 	var personIdRequester *string
 	identity, err := port.securityService.GetIdentity(c)
-	if err != nil {
-		personIdRequester = nil
-	} else {
-		personIdRequester = &identity.PersonId
+	if err == nil {
+		personIdRequester = identity.PersonIdRequester()
 	}
 
 	port.log.Debug("-> getPacksFromModel",
@@ -431,3 +445,49 @@ func (port *packFiberHandler) editPackDescription(c *fiber.Ctx) error {
 	}
 	return c.JSON(rest.ApiOkEmpty())
 }
+
+// ShowAccount godoc
+// @Summary      Edit Pack Prices
+// @Description  Edit the pack dollar value and the optional fixed prices by currency, by the model or admin
+// @Tags         Pack
+// @Accept       json
+// @Produce      json
+// @Param        data body EditPackPricesDto true "Payload Data"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/pack/{modelNickName}/{packNumber}/prices [post]
+func (port *packFiberHandler) editPackPrices(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+
+	modelNickNameParam := c.Params("modelNickName")
+	packNumberParam := c.Params("packNumber")
+	var payload EditPackPricesDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	packNumber, err := strconv.Atoi(packNumberParam)
+	if err != nil {
+		return err
+	}
+
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+
+	port.log.Debug("-> editPackPrices", zap.Any("payload", payload))
+	err = port.packService.EditPackPrices(modelNickNameParam, packNumber, payload.DollarValue, payload.Prices, identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type permissionFiberHandler struct {
+	permissionService service.PermissionService
+	securityService   security.HttpSecurityService
+	validate          *validator.Validate
+	log               *zap.Logger
+}
+
+func NewPermissionFiberHandler(
+	permissionService service.PermissionService,
+	securityService security.HttpSecurityService,
+	validate *validator.Validate,
+	log *zap.Logger) FiberHandler {
+	return &permissionFiberHandler{permissionService, securityService, validate, log}
+}
+
+// RegisterRoutes implements FiberHandler
+func (port *permissionFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/permission")
+	group.Get("/all", port.findAllPermissions)
+	group.Post("/save", port.savePermission)
+	group.Delete("/:code", port.deletePermission)
+}
+
+// privates
+
+// ShowAccount godoc
+// @Summary      Find All Permissions
+// @Description  Get all permissions (admin)
+// @Tags         Permission
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  rest.ApiResponse[[]domain.Permission]
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/permission/all [get]
+func (port *permissionFiberHandler) findAllPermissions(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> findAllPermissions")
+	permissions, err := port.permissionService.FindAll()
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkArray(permissions))
+}
+
+// ShowAccount godoc
+// @Summary      Save Permission
+// @Description  Create or update a permission (admin)
+// @Tags         Permission
+// @Accept       json
+// @Produce      json
+// @Param        data body dto.PermissionSaveDto true "The permission"
+// @Success      200  {object}  rest.ApiResponse[domain.Permission]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/permission/save [post]
+func (port *permissionFiberHandler) savePermission(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	var payload dto.PermissionSaveDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> savePermission", zap.Any("permission", payload))
+	permission, err := port.permissionService.Save(payload, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(permission))
+}
+
+// ShowAccount godoc
+// @Summary      Delete Permission
+// @Description  Delete a permission not assigned to profiles (admin)
+// @Tags         Permission
+// @Accept       json
+// @Produce      json
+// @Param        code   path     int  true  "code of the permission"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/permission/{code} [delete]
+func (port *permissionFiberHandler) deletePermission(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	code, err := c.ParamsInt("code")
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> deletePermission", zap.Int("code", code))
+	err = port.permissionService.Delete(code, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
@@ -2,34 +2,111 @@ package handler
 
 import (
 	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
 	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
 	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 type personFiberHandler struct {
-	personService service.PersonService
-	log           *zap.Logger
+	personService   service.PersonService
+	securityService security.HttpSecurityService
+	validate        *validator.Validate
+	log             *zap.Logger
 }
 
-func NewPersonFiberHandler(personService service.PersonService, log *zap.Logger) FiberHandler {
-	return &personFiberHandler{personService, log}
+func NewPersonFiberHandler(
+	personService service.PersonService,
+	securityService security.HttpSecurityService,
+	validate *validator.Validate,
+	log *zap.Logger,
+) FiberHandler {
+	return &personFiberHandler{personService, securityService, validate, log}
 }
 
 func (port *personFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 	router := *fiberRouter
 	group := router.Group("/person")
+	// before /:uuid
+	group.Get("/me", port.findMe)
+	group.Post("/me", port.updateMe)
 	group.Get("/all", port.findAllPersons)
 	group.Get("/filter", port.filterPersons)
 	group.Get("/:uuid", port.findById)
 	group.Post("/save", port.savePerson)
+	group.Post("/:uuid/profile", port.assignProfile)
 	group.Delete("/:uuid", port.deletePerson)
 }
 
+// ShowAccount godoc
+// @Summary      Find Me
+// @Description  Get the person of the authenticated identity
+// @Tags         Person
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  rest.ApiResponse[domain.Person]
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/person/me [get]
+func (port *personFiberHandler) findMe(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+	port.log.Debug("-> findMe", zap.String("personId", identity.PersonId))
+	person, err := port.personService.FindById(identity.PersonId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(person))
+}
+
+// ShowAccount godoc
+// @Summary      Update Me
+// @Description  Update the name and birthday of the person of the authenticated identity
+// @Tags         Person
+// @Accept       json
+// @Produce      json
+// @Param        data body dto.PersonSelfUpdateDto true "The new data"
+// @Success      200  {object}  rest.ApiResponse[domain.Person]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/person/me [post]
+func (port *personFiberHandler) updateMe(c *fiber.Ctx) error {
+	identity, err := port.securityService.GetIdentity(c)
+	if err != nil {
+		return err
+	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
+	var payload dto.PersonSelfUpdateDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> updateMe", zap.String("personId", identity.PersonId))
+	person, err := port.personService.UpdateOwnData(identity.PersonId, payload, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(person))
+}
+
 // ShowAccount godoc
 // @Summary      Filter Persons
-// @Description  Search persons filtered
+// @Description  Search persons filtered (admin)
 // @Tags         Person
 // @Accept       json
 // @Produce      json
@@ -40,12 +117,17 @@ func (port *personFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 // @Param        birthdayUpper   query     time.Time  false "birthdayUpper"
 // @Success      200  {object}  rest.ApiResponse[[]domain.Person]
 // @Failure      400  {object}  error
+// @Failure      401  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /v1/person/filter [get]
 func (port *personFiberHandler) filterPersons(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
 	var filters domain.PersonFilter
-	err := c.QueryParser(&filters)
+	err = c.QueryParser(&filters)
 	if err != nil {
 		return err
 	}
@@ -59,16 +141,21 @@ func (port *personFiberHandler) filterPersons(c *fiber.Ctx) error {
 
 // ShowAccount godoc
 // @Summary      Find All Persons
-// @Description  Get all persons
+// @Description  Get all persons (admin)
 // @Tags         Person
 // @Accept       json
 // @Produce      json
 // @Success      200  {object}  rest.ApiResponse[[]domain.Person]
 // @Failure      400  {object}  error
+// @Failure      401  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /v1/person/all [get]
 func (port *personFiberHandler) findAllPersons(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
 	port.log.Debug("-> findAllPersons")
 	persons, err := port.personService.FindAll()
 	if err != nil {
@@ -79,17 +166,22 @@ func (port *personFiberHandler) findAllPersons(c *fiber.Ctx) error {
 
 // ShowAccount godoc
 // @Summary      Find By Id
-// @Description  Find person by uuid id
+// @Description  Find person by uuid id (admin)
 // @Tags         Person
 // @Accept       json
 // @Produce      json
 // @Param        uuid   path     string  false  "id of person"
 // @Success      200  {object}  rest.ApiResponse[domain.Person]
 // @Failure      400  {object}  error
+// @Failure      401  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /v1/person/{uuid} [get]
 func (port *personFiberHandler) findById(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
 	uuidParam := c.Params("uuid")
 	port.log.Debug("-> findById", zap.String("uuid", uuidParam))
 	person, err := port.personService.FindById(uuidParam)
@@ -101,46 +193,92 @@ func (port *personFiberHandler) findById(c *fiber.Ctx) error {
 
 // ShowAccount godoc
 // @Summary      Save Person
-// @Description  Insert or update a person
+// @Description  Insert or update a person (admin)
 // @Tags         Person
 // @Accept       json
 // @Produce      json
 // @Param        data body domain.Person true "The input person"
 // @Success      200  {object}  rest.ApiResponse[domain.Person]
 // @Failure      400  {object}  error
+// @Failure      401  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /v1/person/save [post]
 func (port *personFiberHandler) savePerson(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
 	var person domain.Person
-	err := c.BodyParser(&person)
+	err = c.BodyParser(&person)
 	if err != nil {
 		return err
 	}
 	port.log.Debug("-> savePerson", zap.Any("person", &person))
-	updatedPerson, err := port.personService.Save(person)
+	updatedPerson, err := port.personService.Save(person, identity.AuditActor(c.IP()))
 	if err != nil {
 		return err
 	}
 	return c.JSON(rest.ApiOk(&updatedPerson))
 }
 
+// ShowAccount godoc
+// @Summary      Assign Profile
+// @Description  Change the profile of a person (admin)
+// @Tags         Person
+// @Accept       json
+// @Produce      json
+// @Param        uuid   path     string  true  "id of person"
+// @Param        data body dto.PersonProfileAssignDto true "The profile"
+// @Success      200  {object}  rest.ApiResponse[domain.Person]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/person/{uuid}/profile [post]
+func (port *personFiberHandler) assignProfile(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	var payload dto.PersonProfileAssignDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	uuidParam := c.Params("uuid")
+	port.log.Debug("-> assignProfile", zap.String("uuid", uuidParam), zap.Int("profileCode", payload.ProfileCode))
+	person, err := port.personService.AssignProfile(uuidParam, payload.ProfileCode, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(person))
+}
+
 // ShowAccount godoc
 // @Summary      Delete Person By Id
-// @Description  Delete one person by his uuid
+// @Description  Delete one person by his uuid (admin)
 // @Tags         Person
 // @Accept       json
 // @Produce      json
 // @Param        uuid   path     string  false  "id of person"
 // @Success      200  {object}  rest.ApiResponse[string]
 // @Failure      400  {object}  error
+// @Failure      401  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /v1/person/{uuid} [delete]
 func (port *personFiberHandler) deletePerson(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
 	uuidParam := c.Params("uuid")
 	port.log.Debug("-> deletePerson", zap.String("uuid", uuidParam))
-	err := port.personService.Delete(uuidParam)
+	err = port.personService.Delete(uuidParam, identity.AuditActor(c.IP()))
 	if err != nil {
 		return err
 	}
@@ -2,20 +2,28 @@ package handler
 
 import (
 	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
 	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 type profileFiberHandler struct {
-	profileService service.ProfileService
-	log            *zap.Logger
+	profileService  service.ProfileService
+	securityService security.HttpSecurityService
+	validate        *validator.Validate
+	log             *zap.Logger
 }
 
 func NewProfileFiberHandler(
 	profileService service.ProfileService,
+	securityService security.HttpSecurityService,
+	validate *validator.Validate,
 	log *zap.Logger) FiberHandler {
-	return &profileFiberHandler{profileService, log}
+	return &profileFiberHandler{profileService, securityService, validate, log}
 }
 
 // RegisterRoutes implements FiberHandler
@@ -23,6 +31,8 @@ func (port *profileFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 	router := *fiberRouter
 	group := router.Group("/profile")
 	group.Get("/all", port.findAllProfiles)
+	group.Post("/save", port.saveProfile)
+	group.Delete("/:code", port.deleteProfile)
 }
 
 // privates
@@ -46,3 +56,66 @@ func (port *profileFiberHandler) findAllProfiles(c *fiber.Ctx) error {
 	}
 	return c.JSON(rest.ApiOk(&profiles))
 }
+
+// ShowAccount godoc
+// @Summary      Save Profile
+// @Description  Create or update a profile with its permissions (admin)
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        data body dto.ProfileSaveDto true "The profile"
+// @Success      200  {object}  rest.ApiResponse[domain.Profile]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/profile/save [post]
+func (port *profileFiberHandler) saveProfile(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	var payload dto.ProfileSaveDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> saveProfile", zap.Any("profile", payload))
+	profile, err := port.profileService.Save(payload, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(profile))
+}
+
+// ShowAccount godoc
+// @Summary      Delete Profile
+// @Description  Delete a profile not assigned to persons (admin)
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        code   path     int  true  "code of the profile"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/profile/{code} [delete]
+func (port *profileFiberHandler) deleteProfile(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	code, err := c.ParamsInt("code")
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> deleteProfile", zap.Int("code", code))
+	err = port.profileService.Delete(code, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
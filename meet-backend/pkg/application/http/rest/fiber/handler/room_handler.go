@@ -60,10 +60,7 @@ func (port *roomFiberHandler) findRoomByHash(c *fiber.Ctx) error {
 		return err
 	}
 
-	var personIdRequester *string
-	if identity != nil {
-		personIdRequester = &identity.PersonId
-	}
+	personIdRequester := identity.PersonIdRequester()
 
 	port.log.Debug("-> findRoomByHash", zap.String("hash", roomHash), zap.Any("personIdRequester", personIdRequester))
 
@@ -119,6 +116,9 @@ func (port *roomFiberHandler) findOwnedRooms(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	if err = identity.MustBePerson(); err != nil {
+		return err
+	}
 	port.log.Debug("-> findOwnedRooms")
 	rooms, err := port.roomService.FindByOwnerPersonId(identity.PersonId)
 	if err != nil {
@@ -159,6 +159,9 @@ func (port *roomFiberHandler) createRoom(c *fiber.Ctx) error {
 		if !identity.HasPermission(domain.PermissionCodeCreateRoom) {
 			return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
 		}
+		if err = identity.MustBePerson(); err != nil {
+			return err
+		}
 		payload.OwnerPersonId = identity.PersonId
 	}
 
@@ -202,10 +205,13 @@ func (port *roomFiberHandler) changeVisibilityRoom(c *fiber.Ctx) error {
 		if !identity.HasPermission(domain.PermissionCodeCreateRoom) {
 			return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
 		}
+		if err = identity.MustBePerson(); err != nil {
+			return err
+		}
 
-		roomDTO, err = port.roomService.ChangeRoomVisibilityOwnRoom(payload, identity.PersonId)
+		roomDTO, err = port.roomService.ChangeRoomVisibilityOwnRoom(payload, identity.PersonId, identity.AuditActor(c.IP()))
 	} else {
-		roomDTO, err = port.roomService.ChangeRoomVisibility(payload)
+		roomDTO, err = port.roomService.ChangeRoomVisibility(payload, identity.AuditActor(c.IP()))
 	}
 
 	if err != nil {
@@ -243,10 +249,13 @@ func (port *roomFiberHandler) deleteRoom(c *fiber.Ctx) error {
 		if !identity.HasPermission(domain.PermissionCodeCreateRoom) {
 			return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
 		}
+		if err = identity.MustBePerson(); err != nil {
+			return err
+		}
 
-		err = port.roomService.DeleteOwnRoom(roomHash, identity.PersonId)
+		err = port.roomService.DeleteOwnRoom(roomHash, identity.PersonId, identity.AuditActor(c.IP()))
 	} else {
-		err = port.roomService.DeleteRoom(roomHash)
+		err = port.roomService.DeleteRoom(roomHash, identity.AuditActor(c.IP()))
 	}
 
 	if err != nil {
@@ -3,20 +3,27 @@ package handler
 import (
 	"github.com/erodriguezg/meet/pkg/application/http/rest"
 	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 type securityHandler struct {
-	securityService security.HttpSecurityService
-	log             *zap.Logger
+	securityService    security.HttpSecurityService
+	bypassLoginEnabled bool
+	validate           *validator.Validate
+	log                *zap.Logger
 }
 
 func NewSecurityHandler(
 	securityService security.HttpSecurityService,
+	bypassLoginEnabled bool,
+	validate *validator.Validate,
 	log *zap.Logger) FiberHandler {
 	return &securityHandler{
 		securityService,
+		bypassLoginEnabled,
+		validate,
 		log,
 	}
 }
@@ -27,8 +34,15 @@ func (port *securityHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 	group := router.Group("/security")
 	group.Get("/redirect-to-openid-login-url", port.redirectToOpenIdLoginUrl)
 	group.Get("/login-url", port.getLoginUrl)
+	group.Get("/providers", port.getProviders)
 	group.Post("/token", port.getToken)
+	group.Post("/refresh", port.refreshToken)
+	group.Post("/logout", port.logout)
 	group.Get("/identity", port.getIdentity)
+	// never registered in production, see configBypassLoginEnabled
+	if port.bypassLoginEnabled {
+		group.Post("/bypass-login", port.bypassLogin)
+	}
 }
 
 // ShowAccount godoc
@@ -36,16 +50,19 @@ func (port *securityHandler) RegisterRoutes(fiberRouter *fiber.Router) {
 // @Description  Redirect To OpenId Login Url
 // @Tags         Security
 // @Accept       json
-// @Param        state  query     string  false  "state from frontend"
+// @Param        provider  query     string  false  "name of the login provider, the default when empty"
 // @Success      301  {object}  string
 // @Failure      400  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /v1/security/redirect-to-openid-login-url [get]
 func (port *securityHandler) redirectToOpenIdLoginUrl(c *fiber.Ctx) error {
-	port.log.Debug("-> getOpenIdLoginUrl")
-	state := c.Query("state")
-	loginUrl := port.securityService.GetOpenIdLoginUrl(state)
+	provider := c.Query("provider")
+	port.log.Debug("-> getOpenIdLoginUrl", zap.String("provider", provider))
+	loginUrl, err := port.securityService.GetOpenIdLoginUrl(provider)
+	if err != nil {
+		return err
+	}
 	return c.Redirect(loginUrl)
 }
 
@@ -54,21 +71,37 @@ func (port *securityHandler) redirectToOpenIdLoginUrl(c *fiber.Ctx) error {
 // @Description  Get the login url of the sso
 // @Tags         Security
 // @Accept       json
-// @Param        state  query     string  false  "state from frontend"
+// @Param        provider  query     string  false  "name of the login provider, the default when empty"
 // @Success      200  {object}  map[string]string
 // @Failure      400  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /v1/security/login-url [get]
 func (port *securityHandler) getLoginUrl(c *fiber.Ctx) error {
-	port.log.Debug("-> getLoginUrl")
-	state := c.Query("state")
-	loginUrl := port.securityService.GetOpenIdLoginUrl(state)
+	provider := c.Query("provider")
+	port.log.Debug("-> getLoginUrl", zap.String("provider", provider))
+	loginUrl, err := port.securityService.GetOpenIdLoginUrl(provider)
+	if err != nil {
+		return err
+	}
 	return c.JSON(map[string]string{
 		"loginUrl": loginUrl,
 	})
 }
 
+// ShowAccount godoc
+// @Summary      Get Login Providers
+// @Description  Get the names of the login providers, the first is the default
+// @Tags         Security
+// @Accept       json
+// @Success      200  {object}  rest.ApiResponse[[]string]
+// @Failure      500  {object}  error
+// @Router       /v1/security/providers [get]
+func (port *securityHandler) getProviders(c *fiber.Ctx) error {
+	port.log.Debug("-> getProviders")
+	return c.JSON(rest.ApiOkArray(port.securityService.GetOpenIdProviders()))
+}
+
 // ShowAccount godoc
 // @Summary      Get Token
 // @Description  Get the token from OpenId Response Data
@@ -76,7 +109,7 @@ func (port *securityHandler) getLoginUrl(c *fiber.Ctx) error {
 // @Accept       json
 // @Param        code  query     string  false  "code from openid"
 // @Param        state  query     string  false  "state from openid"
-// @Success      200  {object}  map[string]string
+// @Success      200  {object}  security.TokenResponse
 // @Failure      400  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
@@ -89,9 +122,94 @@ func (port *securityHandler) getToken(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
-	return c.JSON(map[string]string{
-		"jwt": token,
-	})
+	return c.JSON(token)
+}
+
+// ShowAccount godoc
+// @Summary      Bypass Login
+// @Description  Get the token of the email without the openid provider, only in development
+// @Tags         Security
+// @Accept       json
+// @Produce      json
+// @Param        request body security.BypassRequest true "email and profile of the person"
+// @Success      200  {object}  security.TokenResponse
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/security/bypass-login [post]
+func (port *securityHandler) bypassLogin(c *fiber.Ctx) error {
+	var payload security.BypassRequest
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	port.log.Warn("-> bypassLogin", zap.String("email", payload.Email))
+	token, err := port.securityService.BypassLogin(payload)
+	if err != nil {
+		return err
+	}
+	return c.JSON(token)
+}
+
+// ShowAccount godoc
+// @Summary      Refresh Token
+// @Description  Get a new jwt with the refresh token, the refresh token is rotated
+// @Tags         Security
+// @Accept       json
+// @Produce      json
+// @Param        request body security.RefreshTokenRequest true "refresh token"
+// @Success      200  {object}  security.TokenResponse
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/security/refresh [post]
+func (port *securityHandler) refreshToken(c *fiber.Ctx) error {
+	port.log.Debug("-> refreshToken")
+	var payload security.RefreshTokenRequest
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	token, err := port.securityService.RefreshToken(payload.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return c.JSON(token)
+}
+
+// ShowAccount godoc
+// @Summary      Logout
+// @Description  Revoke the refresh token and the jwt of the request
+// @Tags         Security
+// @Accept       json
+// @Produce      json
+// @Param        request body security.LogoutRequest false "refresh token"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      400  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/security/logout [post]
+func (port *securityHandler) logout(c *fiber.Ctx) error {
+	port.log.Debug("-> logout")
+	var payload security.LogoutRequest
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&payload)
+		if err != nil {
+			return err
+		}
+	}
+	err := port.securityService.Logout(payload.RefreshToken, c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
 }
 
 // ShowAccount godoc
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type serviceAccountFiberHandler struct {
+	serviceAccountService service.ServiceAccountService
+	securityService       security.HttpSecurityService
+	validate              *validator.Validate
+	log                   *zap.Logger
+}
+
+func NewServiceAccountFiberHandler(
+	serviceAccountService service.ServiceAccountService,
+	securityService security.HttpSecurityService,
+	validate *validator.Validate,
+	log *zap.Logger) FiberHandler {
+	return &serviceAccountFiberHandler{serviceAccountService, securityService, validate, log}
+}
+
+// RegisterRoutes implements FiberHandler
+func (port *serviceAccountFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/service-account")
+	group.Get("/all", port.findAllServiceAccounts)
+	group.Post("/save", port.saveServiceAccount)
+	group.Get("/:id", port.findServiceAccount)
+	group.Delete("/:id", port.deleteServiceAccount)
+	group.Get("/:id/api-key", port.findApiKeys)
+	group.Post("/:id/api-key", port.createApiKey)
+	group.Delete("/:id/api-key/:keyId", port.revokeApiKey)
+}
+
+// privates
+
+// ShowAccount godoc
+// @Summary      Find All Service Accounts
+// @Description  Get all the service accounts (admin)
+// @Tags         Service Account
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  rest.ApiResponse[[]domain.ServiceAccount]
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/service-account/all [get]
+func (port *serviceAccountFiberHandler) findAllServiceAccounts(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> findAllServiceAccounts")
+	serviceAccounts, err := port.serviceAccountService.FindAll()
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkArray(serviceAccounts))
+}
+
+// ShowAccount godoc
+// @Summary      Find Service Account
+// @Description  Get the service account by id (admin)
+// @Tags         Service Account
+// @Accept       json
+// @Produce      json
+// @Param        id   path     string  true  "id of the service account"
+// @Success      200  {object}  rest.ApiResponse[domain.ServiceAccount]
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/service-account/{id} [get]
+func (port *serviceAccountFiberHandler) findServiceAccount(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	idParam := c.Params("id")
+	port.log.Debug("-> findServiceAccount", zap.String("id", idParam))
+	serviceAccount, err := port.serviceAccountService.FindById(idParam)
+	if err != nil {
+		return err
+	}
+	if serviceAccount == nil {
+		return exception.NewServiceAccountNotFoundException(idParam)
+	}
+	return c.JSON(rest.ApiOk(serviceAccount))
+}
+
+// ShowAccount godoc
+// @Summary      Save Service Account
+// @Description  Create (without id) or update a service account and its permissions (admin)
+// @Tags         Service Account
+// @Accept       json
+// @Produce      json
+// @Param        data body dto.ServiceAccountSaveDto true "The service account"
+// @Success      200  {object}  rest.ApiResponse[domain.ServiceAccount]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/service-account/save [post]
+func (port *serviceAccountFiberHandler) saveServiceAccount(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	var payload dto.ServiceAccountSaveDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	port.log.Debug("-> saveServiceAccount", zap.Any("serviceAccount", payload))
+	serviceAccount, err := port.serviceAccountService.Save(payload, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(serviceAccount))
+}
+
+// ShowAccount godoc
+// @Summary      Delete Service Account
+// @Description  Delete the service account and revoke its api keys (admin)
+// @Tags         Service Account
+// @Accept       json
+// @Produce      json
+// @Param        id   path     string  true  "id of the service account"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/service-account/{id} [delete]
+func (port *serviceAccountFiberHandler) deleteServiceAccount(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	idParam := c.Params("id")
+	port.log.Debug("-> deleteServiceAccount", zap.String("id", idParam))
+	err = port.serviceAccountService.Delete(idParam, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
+
+// ShowAccount godoc
+// @Summary      Find Api Keys
+// @Description  Get the api keys of the service account, without the keys (admin)
+// @Tags         Service Account
+// @Accept       json
+// @Produce      json
+// @Param        id   path     string  true  "id of the service account"
+// @Success      200  {object}  rest.ApiResponse[[]domain.ApiKey]
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/service-account/{id}/api-key [get]
+func (port *serviceAccountFiberHandler) findApiKeys(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	idParam := c.Params("id")
+	port.log.Debug("-> findApiKeys", zap.String("id", idParam))
+	apiKeys, err := port.serviceAccountService.FindApiKeys(idParam)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkArray(apiKeys))
+}
+
+// ShowAccount godoc
+// @Summary      Create Api Key
+// @Description  Create an api key for the service account, the key is returned only once (admin)
+// @Tags         Service Account
+// @Accept       json
+// @Produce      json
+// @Param        id   path     string  true  "id of the service account"
+// @Param        data body dto.ApiKeyCreateDto true "The api key"
+// @Success      200  {object}  rest.ApiResponse[dto.ApiKeyCreatedDto]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/service-account/{id}/api-key [post]
+func (port *serviceAccountFiberHandler) createApiKey(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	var payload dto.ApiKeyCreateDto
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+	err = port.validate.Struct(payload)
+	if err != nil {
+		return err
+	}
+	idParam := c.Params("id")
+	port.log.Debug("-> createApiKey", zap.String("id", idParam), zap.String("name", payload.Name))
+	created, err := port.serviceAccountService.CreateApiKey(idParam, payload, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(created))
+}
+
+// ShowAccount godoc
+// @Summary      Revoke Api Key
+// @Description  Revoke an api key of the service account (admin)
+// @Tags         Service Account
+// @Accept       json
+// @Produce      json
+// @Param        id   path     string  true  "id of the service account"
+// @Param        keyId   path     string  true  "id of the api key"
+// @Success      200  {object}  rest.ApiResponse[string]
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/service-account/{id}/api-key/{keyId} [delete]
+func (port *serviceAccountFiberHandler) revokeApiKey(c *fiber.Ctx) error {
+	identity, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+	idParam := c.Params("id")
+	keyIdParam := c.Params("keyId")
+	port.log.Debug("-> revokeApiKey", zap.String("id", idParam), zap.String("keyId", keyIdParam))
+	err = port.serviceAccountService.RevokeApiKey(idParam, keyIdParam, identity.AuditActor(c.IP()))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOkEmpty())
+}
@@ -0,0 +1,61 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/addons"
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/fiberidentity"
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/handler"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSecurityService authenticates every request with the identity, only GetIdentity is used
+// by the person handlers
+type fakeSecurityService struct {
+	security.HttpSecurityService
+	identity *fiberidentity.FiberIdentity
+}
+
+func (port *fakeSecurityService) GetIdentity(c *fiber.Ctx) (*fiberidentity.FiberIdentity, error) {
+	return port.identity, nil
+}
+
+func newServiceAccountApp() *fiber.App {
+	securityService := &fakeSecurityService{identity: &fiberidentity.FiberIdentity{
+		FirstName:        "billing",
+		ServiceAccountId: "650000000000000000000001",
+	}}
+	app := fiber.New(fiber.Config{
+		ErrorHandler: addons.NewCustomFiberErrorHandler(zap.NewNop()).CustomFiberErrorHandler,
+	})
+	v1 := app.Group("/api/v1")
+	// the services are not reached by the service accounts
+	handler.NewPersonFiberHandler(nil, securityService, validator.New(), zap.NewNop()).RegisterRoutes(&v1)
+	handler.NewBuyPackHandler(nil, securityService, zap.NewNop()).RegisterRoutes(&v1)
+	return app
+}
+
+func TestServiceAccountCanNotUsePersonEndpoints(t *testing.T) {
+	app := newServiceAccountApp()
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/person/me", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/person/me", strings.NewReader(`{"firstName":"name"}`)),
+		httptest.NewRequest(http.MethodPost, "/api/v1/buy-pack/create-order",
+			strings.NewReader(`{"personId":"","modelNickName":"model","packNumber":1}`)),
+	}
+	for _, request := range requests {
+		request.Header.Set("Content-Type", "application/json")
+		response, err := app.Test(request)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode, request.URL.Path)
+	}
+}
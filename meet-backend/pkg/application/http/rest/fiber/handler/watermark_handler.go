@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"github.com/erodriguezg/meet/pkg/application/http/rest"
+	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type watermarkFiberHandler struct {
+	watermarkService service.WatermarkService
+	securityService  security.HttpSecurityService
+	log              *zap.Logger
+}
+
+func NewWatermarkFiberHandler(
+	watermarkService service.WatermarkService,
+	securityService security.HttpSecurityService,
+	log *zap.Logger,
+) FiberHandler {
+	return &watermarkFiberHandler{watermarkService, securityService, log}
+}
+
+func (port *watermarkFiberHandler) RegisterRoutes(fiberRouter *fiber.Router) {
+	router := *fiberRouter
+	group := router.Group("/watermark")
+	group.Post("/extract", port.extractWatermark)
+}
+
+// ShowAccount godoc
+// @Summary      Extract Watermark
+// @Description  Identify the buyer and the order of a leaked pack image (admin)
+// @Tags         Watermark
+// @Accept       mpfd
+// @Produce      json
+// @Param        file formData file true "leaked image"
+// @Success      200  {object}  rest.ApiResponse[dto.WatermarkExtractDto]
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /v1/watermark/extract [post]
+func (port *watermarkFiberHandler) extractWatermark(c *fiber.Ctx) error {
+	_, err := port.securityService.MustHavePermission(domain.PermissionCodeManageSystem, c)
+	if err != nil {
+		return err
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	port.log.Debug("-> extractWatermark", zap.String("fileName", fileHeader.Filename))
+	result, err := port.watermarkService.ExtractWatermark(file)
+	if err != nil {
+		return err
+	}
+	return c.JSON(rest.ApiOk(result))
+}
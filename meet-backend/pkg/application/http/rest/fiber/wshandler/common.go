@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/addons"
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/fiberidentity"
 	"github.com/erodriguezg/meet/pkg/application/http/security"
+	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/gofiber/contrib/socketio"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
@@ -48,7 +51,7 @@ func NewMiddlewareFunction(httpSecurityService security.HttpSecurityService) fun
 	}
 }
 
-func InitWebSocketsHandlers(wsRoot string, appFiber *fiber.App, log *zap.Logger) {
+func InitWebSocketsHandlers(wsRoot string, appFiber *fiber.App, rateLimiter *addons.RateLimiter, eventRateLimit domain.RateLimit, log *zap.Logger) {
 	wsClients = make(map[string]string)
 
 	// Multiple event handling supported
@@ -71,6 +74,14 @@ func InitWebSocketsHandlers(wsRoot string, appFiber *fiber.App, log *zap.Logger)
 
 		log.Debug(fmt.Sprintf("Message event - User: %s - Message: %s \n", ep.Kws.GetStringAttribute("user_id"), string(ep.Data)))
 
+		// the custom events are fired from here, the limit applies to all of them
+		rateLimitKey := "ws:" + wsRequesterKey(ep.Kws)
+		if allowed, _ := rateLimiter.Allow(rateLimitKey, eventRateLimit); !allowed {
+			log.Debug("websocket event rate limited", zap.String("key", rateLimitKey))
+			emitRateLimited(ep.Kws)
+			return
+		}
+
 		message := MessageObject{}
 
 		// Unmarshal the json message
@@ -145,3 +156,22 @@ func InitWebSocketsHandlers(wsRoot string, appFiber *fiber.App, log *zap.Logger)
 	}
 
 }
+
+// wsRequesterKey the identity resolved on the upgrade or the ip of the connection
+func wsRequesterKey(kws *socketio.Websocket) string {
+	identity, _ := kws.Conn.Locals("IDENTITY").(*fiberidentity.FiberIdentity)
+	return addons.RequesterKey(identity, kws.Conn.IP())
+}
+
+func emitRateLimited(kws *socketio.Websocket) {
+	msg := chatEventMessage{
+		Event:   eventRateLimited,
+		From:    systemChatUser,
+		Message: "Too many messages, wait a moment",
+	}
+	msgJson, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	kws.Emit(msgJson, socketio.TextMessage)
+}
@@ -9,10 +9,12 @@ import (
 )
 
 const (
-	eventChatMsg   = "CHAT_MSG"
-	eventChatInfo  = "CHAT_INFO"
-	eventWebRTC    = "WEBRTC_SIGNALING"
-	systemChatUser = "System"
+	eventChatMsg  = "CHAT_MSG"
+	eventChatInfo = "CHAT_INFO"
+	eventWebRTC   = "WEBRTC_SIGNALING"
+	// sent to the client when its messages are dropped by the rate limit
+	eventRateLimited = "RATE_LIMITED"
+	systemChatUser   = "System"
 )
 
 type chatEventMessage struct {
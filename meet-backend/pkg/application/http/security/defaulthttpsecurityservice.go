@@ -1,33 +1,61 @@
 package security
 
 import (
+	"crypto/rand"
 	"crypto/rsa"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/fiberidentity"
 	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
 	"github.com/erodriguezg/meet/pkg/core/service"
 	"github.com/erodriguezg/meet/pkg/util/openid"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	// time for complete the login in the provider
+	openIdLoginStateDuration = 10 * time.Minute
+)
+
 type DefaultHttpSecurityService struct {
-	openIdService     openid.OpenIdService
-	personService     service.PersonService
-	fiberIdentityUtil *fiberidentity.FiberIdentityUtil
-	rsaPrivateKey     *rsa.PrivateKey
-	statePassPhrase   string
+	openIdProviderRegistry     openid.OpenIdProviderRegistry
+	personService              service.PersonService
+	profileService             service.ProfileService
+	fiberIdentityUtil          *fiberidentity.FiberIdentityUtil
+	openIdLoginStateRepository repository.OpenIdLoginStateRepository
+	authSessionService         service.AuthSessionService
+	rsaPrivateKey              *rsa.PrivateKey
+	statePassPhrase            string
+	accessTokenDuration        time.Duration
+	bypassLoginEnabled         bool
 }
 
 func NewDefaultHttpSecurityService(
-	openIdService openid.OpenIdService,
+	openIdProviderRegistry openid.OpenIdProviderRegistry,
 	personService service.PersonService,
+	profileService service.ProfileService,
 	fiberIdentityUtil *fiberidentity.FiberIdentityUtil,
+	openIdLoginStateRepository repository.OpenIdLoginStateRepository,
+	authSessionService service.AuthSessionService,
 	rsaPrivateKeyBytes []byte,
-	statePassPhrase string) HttpSecurityService {
+	statePassPhrase string,
+	accessTokenDuration time.Duration,
+	bypassLoginEnabled bool) HttpSecurityService {
+
+	if statePassPhrase == "" {
+		panic("the openid state pass phrase is required")
+	}
+
+	if accessTokenDuration <= 0 {
+		panic("the access token duration must be positive")
+	}
 
 	rsaPrivateKey, err := jwt.ParseRSAPrivateKeyFromPEM(rsaPrivateKeyBytes)
 	if err != nil {
@@ -35,60 +63,146 @@ func NewDefaultHttpSecurityService(
 	}
 
 	return &DefaultHttpSecurityService{
-		openIdService,
+		openIdProviderRegistry,
 		personService,
+		profileService,
 		fiberIdentityUtil,
+		openIdLoginStateRepository,
+		authSessionService,
 		rsaPrivateKey,
 		statePassPhrase,
+		accessTokenDuration,
+		bypassLoginEnabled,
 	}
 }
 
-func (port *DefaultHttpSecurityService) GetOpenIdLoginUrl(state string) string {
-	return port.openIdService.GetLoginUrl(state)
+func (port *DefaultHttpSecurityService) GetOpenIdProviders() []string {
+	return port.openIdProviderRegistry.GetProviderNames()
 }
 
-func (port *DefaultHttpSecurityService) GetToken(code string, state string) (string, error) {
+func (port *DefaultHttpSecurityService) GetOpenIdLoginUrl(providerName string) (string, error) {
+	if providerName == "" {
+		providerName = port.openIdProviderRegistry.GetDefaultProviderName()
+	}
+	provider, found := port.openIdProviderRegistry.GetProvider(providerName)
+	if !found {
+		return "", exception.NewLoginProviderNotFoundException(providerName)
+	}
 
-	stateFunc := func(s string) bool {
-		return true
+	request, err := openid.NewLoginRequest(port.statePassPhrase)
+	if err != nil {
+		return "", fmt.Errorf("error at creating openid login request: %w", err)
 	}
 
-	openIdUser, err := port.openIdService.ProcessCallback(code, state, stateFunc)
+	err = port.openIdLoginStateRepository.Save(domain.OpenIdLoginState{
+		StateId:        request.StateId,
+		Provider:       providerName,
+		Nonce:          request.Nonce,
+		CodeVerifier:   request.CodeVerifier,
+		ExpirationDate: time.Now().Add(openIdLoginStateDuration),
+	})
 	if err != nil {
-		return "", fmt.Errorf("error at processing openid: %w", err)
+		return "", fmt.Errorf("error at saving openid login state: %w", err)
 	}
 
-	person, err := port.personService.FindByEmail(openIdUser.Email)
+	return provider.GetLoginUrl(request)
+}
+
+func (port *DefaultHttpSecurityService) GetToken(code string, state string) (*TokenResponse, error) {
+
+	providerName, request, err := port.consumeLoginRequest(state)
 	if err != nil {
-		return "", fmt.Errorf("error at finding person by email: %w", err)
+		return nil, err
+	}
+
+	provider, found := port.openIdProviderRegistry.GetProvider(providerName)
+	if !found {
+		return nil, exception.NewLoginProviderNotFoundException(providerName)
+	}
+
+	openIdUser, err := provider.ProcessCallback(code, *request)
+	if err != nil {
+		return nil, fmt.Errorf("error at processing openid: %w", err)
+	}
+
+	person, err := port.findOrCreatePerson(openIdUser)
+	if err != nil {
+		return nil, err
+	}
+
+	if !person.Active {
+		return nil, exception.NewPersonIsNotActiveException(person)
+	}
+
+	refreshToken, err := port.authSessionService.CreateRefreshToken(*person)
+	if err != nil {
+		return nil, fmt.Errorf("error at creating refresh token: %w", err)
+	}
+	return port.newTokenResponse(person, refreshToken)
+}
+
+func (port *DefaultHttpSecurityService) BypassLogin(request BypassRequest) (*TokenResponse, error) {
+	if !port.bypassLoginEnabled {
+		return nil, fiberidentity.NewAccessDeniedError(fmt.Errorf("the bypass login is not enabled"))
+	}
+
+	person, err := port.personService.FindByEmail(request.Email)
+	if err != nil {
+		return nil, fmt.Errorf("error at finding person by email: %w", err)
 	}
 
 	if person == nil {
-		newPerson := domain.Person{
-			Email:       openIdUser.Email,
-			FirstName:   openIdUser.FirstName,
-			LastName:    openIdUser.LastName,
-			ProfileCode: domain.ProfileCodeUser,
-		}
-		person, err = port.personService.Save(newPerson)
+		person, err = port.createBypassPerson(request)
 		if err != nil {
-			return "", fmt.Errorf("error at saving first time person: %w", err)
+			return nil, err
 		}
 	}
 
 	if !person.Active {
-		return "", exception.NewPersonIsNotActiveException(person)
+		return nil, exception.NewPersonIsNotActiveException(person)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
-		"email":       person.Email,
-		"firstName":   person.FirstName,
-		"lastName":    person.LastName,
-		"profileCode": person.ProfileCode,
-		"exp":         time.Now().Add(time.Hour * 24).Unix(),
-	})
+	refreshToken, err := port.authSessionService.CreateRefreshToken(*person)
+	if err != nil {
+		return nil, fmt.Errorf("error at creating refresh token: %w", err)
+	}
+	return port.newTokenResponse(person, refreshToken)
+}
+
+func (port *DefaultHttpSecurityService) RefreshToken(refreshToken string) (*TokenResponse, error) {
+	person, newRefreshToken, err := port.authSessionService.RotateRefreshToken(refreshToken)
+	if err != nil {
+		var businessException *exception.BusinessException
+		if errors.As(err, &businessException) {
+			return nil, fiberidentity.NewAccessDeniedError(err)
+		}
+		return nil, fmt.Errorf("error at rotating refresh token: %w", err)
+	}
+	return port.newTokenResponse(person, newRefreshToken)
+}
+
+func (port *DefaultHttpSecurityService) Logout(refreshToken string, c *fiber.Ctx) error {
+	if refreshToken != "" {
+		err := port.authSessionService.RevokeRefreshToken(refreshToken)
+		if err != nil {
+			return fmt.Errorf("error at revoking refresh token: %w", err)
+		}
+	}
 
-	return token.SignedString(port.rsaPrivateKey)
+	// an expired or invalid access token does not need the revocation
+	identity, err := port.fiberIdentityUtil.GetIdentity(c)
+	if err != nil {
+		var accessDeniedError *fiberidentity.FiberAccessDeniedError
+		if errors.As(err, &accessDeniedError) {
+			return nil
+		}
+		return err
+	}
+	if identity.IsServiceAccount() {
+		// the api keys are revoked by the administrators
+		return nil
+	}
+	return port.authSessionService.RevokeAccessToken(identity.TokenId, identity.TokenExpiration)
 }
 
 func (port *DefaultHttpSecurityService) GetIdentity(c *fiber.Ctx) (*fiberidentity.FiberIdentity, error) {
@@ -132,3 +246,153 @@ func (port *DefaultHttpSecurityService) HasProfile(profileCode int, c *fiber.Ctx
 	}
 	return identity, identity.HasProfile(profileCode), nil
 }
+
+// private
+
+func (port *DefaultHttpSecurityService) newTokenResponse(person *domain.Person, refreshToken string) (*TokenResponse, error) {
+	tokenId, err := newTokenId()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"jti":         tokenId,
+		"sub":         person.Id.Hex(),
+		"email":       person.Email,
+		"firstName":   person.FirstName,
+		"lastName":    person.LastName,
+		"profileCode": person.ProfileCode,
+		"iat":         now.Unix(),
+		"exp":         now.Add(port.accessTokenDuration).Unix(),
+	})
+
+	signedToken, err := token.SignedString(port.rsaPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResponse{
+		Jwt:          signedToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(port.accessTokenDuration.Seconds()),
+	}, nil
+}
+
+// consumeLoginRequest the state is valid only once, for the login started in this backend
+// and before it expires.
+func (port *DefaultHttpSecurityService) consumeLoginRequest(state string) (string, *openid.LoginRequest, error) {
+	stateId, ok := openid.VerifyState(state, port.statePassPhrase)
+	if !ok {
+		return "", nil, fiberidentity.NewAccessDeniedError(fmt.Errorf("invalid openid state: '%s'", state))
+	}
+
+	loginState, err := port.openIdLoginStateRepository.Consume(stateId)
+	if err != nil {
+		return "", nil, fmt.Errorf("error at consuming openid login state: %w", err)
+	}
+	if loginState == nil {
+		return "", nil, fiberidentity.NewAccessDeniedError(fmt.Errorf("the openid state is expired or was already used"))
+	}
+
+	return loginState.Provider, &openid.LoginRequest{
+		State:        state,
+		StateId:      loginState.StateId,
+		Nonce:        loginState.Nonce,
+		CodeVerifier: loginState.CodeVerifier,
+	}, nil
+}
+
+// findOrCreatePerson the person of the provider account, or the person with the same email
+// (compared without case) and the account is linked to it. Only the emails verified by the
+// provider are linked, the trusted emails only create new persons.
+func (port *DefaultHttpSecurityService) findOrCreatePerson(openIdUser openid.OpenIdUser) (*domain.Person, error) {
+	person, err := port.personService.FindByExternalIdentity(openIdUser.Provider, openIdUser.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("error at finding person by external identity: %w", err)
+	}
+	if person != nil {
+		return person, nil
+	}
+
+	person, err = port.personService.FindByEmail(openIdUser.Email)
+	if err != nil {
+		return nil, fmt.Errorf("error at finding person by email: %w", err)
+	}
+
+	identity := domain.ExternalIdentity{
+		Provider:   openIdUser.Provider,
+		Subject:    openIdUser.Subject,
+		Email:      openIdUser.Email,
+		LinkedDate: time.Now(),
+	}
+
+	if person == nil {
+		newPerson := domain.Person{
+			Email:       openIdUser.Email,
+			FirstName:   openIdUser.FirstName,
+			LastName:    openIdUser.LastName,
+			ProfileCode: domain.ProfileCodeUser,
+			Identities:  []domain.ExternalIdentity{identity},
+		}
+		person, err = port.personService.Save(newPerson, domain.NewSystemAuditActor("openid-login"))
+		if err != nil {
+			return nil, fmt.Errorf("error at saving first time person: %w", err)
+		}
+		return person, nil
+	}
+
+	if !openIdUser.EmailVerified {
+		return nil, exception.NewUnverifiedEmailLinkException(openIdUser.Provider, openIdUser.Email)
+	}
+
+	if person.Pending {
+		// claim the person created for a gift before the first login
+		person.FirstName = openIdUser.FirstName
+		person.LastName = openIdUser.LastName
+		person.Pending = false
+	}
+	person.Identities = append(person.Identities, identity)
+	person, err = port.personService.Save(*person, domain.NewSystemAuditActor("openid-login"))
+	if err != nil {
+		return nil, fmt.Errorf("error at linking external identity to person: %w", err)
+	}
+	return person, nil
+}
+
+func (port *DefaultHttpSecurityService) createBypassPerson(request BypassRequest) (*domain.Person, error) {
+	profileCode := request.ProfileCode
+	if profileCode == 0 {
+		profileCode = domain.ProfileCodeUser
+	}
+	profile, err := port.profileService.FindByCode(profileCode)
+	if err != nil {
+		return nil, fmt.Errorf("error at finding profile: %w", err)
+	}
+	if profile == nil {
+		return nil, exception.NewProfileNotFoundException(profileCode)
+	}
+
+	firstName := request.FirstName
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(request.Email, "@")
+	}
+	person, err := port.personService.Save(domain.Person{
+		Email:       request.Email,
+		FirstName:   firstName,
+		LastName:    request.LastName,
+		ProfileCode: profileCode,
+	}, domain.NewSystemAuditActor("bypass-login"))
+	if err != nil {
+		return nil, fmt.Errorf("error at saving bypass person: %w", err)
+	}
+	return person, nil
+}
+
+func newTokenId() (string, error) {
+	value := make([]byte, 16)
+	_, err := rand.Read(value)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(value), nil
+}
@@ -0,0 +1,136 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/fiberidentity"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/service"
+	"github.com/erodriguezg/meet/pkg/util/openid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakePersonService keeps the persons in memory, only the methods used by the login are
+// implemented
+type fakePersonService struct {
+	service.PersonService
+	persons []domain.Person
+}
+
+func (port *fakePersonService) FindByEmail(email string) (*domain.Person, error) {
+	for _, person := range port.persons {
+		if strings.EqualFold(person.Email, email) {
+			return &person, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakePersonService) FindByExternalIdentity(provider string, subject string) (*domain.Person, error) {
+	for _, person := range port.persons {
+		if person.HasExternalIdentity(provider, subject) {
+			return &person, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakePersonService) Save(person domain.Person, actor domain.AuditActor) (*domain.Person, error) {
+	if person.Id == nil {
+		id := primitive.NewObjectID()
+		person.Id = &id
+		person.Active = true
+		port.persons = append(port.persons, person)
+		return &person, nil
+	}
+	for i := range port.persons {
+		if *port.persons[i].Id == *person.Id {
+			port.persons[i] = person
+		}
+	}
+	return &person, nil
+}
+
+func newPendingGiftRecipient(email string) domain.Person {
+	id := primitive.NewObjectID()
+	return domain.Person{Id: &id, Email: email, ProfileCode: domain.ProfileCodeUser, Active: true, Pending: true}
+}
+
+func TestFindOrCreatePersonClaimsPendingPerson(t *testing.T) {
+	pending := newPendingGiftRecipient("friend@meet.com")
+	personService := &fakePersonService{persons: []domain.Person{pending}}
+	securityService := &DefaultHttpSecurityService{personService: personService}
+
+	person, err := securityService.findOrCreatePerson(openid.OpenIdUser{
+		Provider:      "google",
+		Subject:       "subject",
+		Email:         "Friend@Meet.com",
+		EmailVerified: true,
+		FirstName:     "First",
+		LastName:      "Last",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, *pending.Id, *person.Id)
+	assert.False(t, person.Pending)
+	assert.Equal(t, "First", person.FirstName)
+	assert.Equal(t, "Last", person.LastName)
+	assert.True(t, person.HasExternalIdentity("google", "subject"))
+	assert.Len(t, personService.persons, 1)
+}
+
+func TestFindOrCreatePersonDoesNotClaimWithUnverifiedEmail(t *testing.T) {
+	pending := newPendingGiftRecipient("friend@meet.com")
+	personService := &fakePersonService{persons: []domain.Person{pending}}
+	securityService := &DefaultHttpSecurityService{personService: personService}
+
+	_, err := securityService.findOrCreatePerson(openid.OpenIdUser{
+		Provider: "other",
+		Subject:  "subject",
+		Email:    "friend@meet.com",
+	})
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	stored, _ := personService.FindByEmail("friend@meet.com")
+	assert.True(t, stored.Pending)
+	assert.Empty(t, stored.Identities)
+}
+
+func TestNewTokenResponseExpiresAfterTheAccessTokenDuration(t *testing.T) {
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	securityService := &DefaultHttpSecurityService{rsaPrivateKey: rsaPrivateKey, accessTokenDuration: 15 * time.Minute}
+	person := newPendingGiftRecipient("person@mail.com")
+
+	tokenResponse, err := securityService.newTokenResponse(&person, "refresh")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(15*60), tokenResponse.ExpiresIn)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(tokenResponse.Jwt, claims, func(token *jwt.Token) (interface{}, error) {
+		return &rsaPrivateKey.PublicKey, nil
+	})
+	require.NoError(t, err)
+	issuedAt, _ := claims.GetIssuedAt()
+	expiration, _ := claims.GetExpirationTime()
+	assert.Equal(t, 15*time.Minute, expiration.Sub(issuedAt.Time))
+}
+
+func TestBypassLoginDeniedWhenDisabled(t *testing.T) {
+	personService := &fakePersonService{}
+	securityService := &DefaultHttpSecurityService{personService: personService}
+
+	tokenResponse, err := securityService.BypassLogin(BypassRequest{Email: "person@mail.com"})
+
+	assert.Nil(t, tokenResponse)
+	assert.IsType(t, &fiberidentity.FiberAccessDeniedError{}, err)
+	assert.Empty(t, personService.persons)
+}
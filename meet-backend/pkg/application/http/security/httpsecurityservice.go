@@ -6,8 +6,15 @@ import (
 )
 
 type HttpSecurityService interface {
-	GetOpenIdLoginUrl(state string) string
-	GetToken(code string, state string) (string, error)
+	GetOpenIdProviders() []string
+	GetOpenIdLoginUrl(provider string) (string, error)
+	GetToken(code string, state string) (*TokenResponse, error)
+	// BypassLogin issues the tokens of the email without the openid provider, the person is
+	// created when it does not exist. It fails when the bypass is not enabled.
+	BypassLogin(request BypassRequest) (*TokenResponse, error)
+	RefreshToken(refreshToken string) (*TokenResponse, error)
+	// Logout revokes the refresh token family and the access token of the request
+	Logout(refreshToken string, c *fiber.Ctx) error
 	GetIdentity(c *fiber.Ctx) (*fiberidentity.FiberIdentity, error)
 	MustHavePermission(permissionCode int, c *fiber.Ctx) (*fiberidentity.FiberIdentity, error)
 	MustHaveProfile(profileCode int, c *fiber.Ctx) (*fiberidentity.FiberIdentity, error)
@@ -24,11 +24,30 @@ type Profile struct {
 	Name string `json:"name"`
 }
 
+// BypassRequest login without the openid provider, only for development and testing
 type BypassRequest struct {
-	Email    string  `json:"email"`
-	Password *string `json:"password"`
+	Email     string `json:"email" validate:"required,email"`
+	FirstName string `json:"firstName" validate:"max=100"`
+	LastName  string `json:"lastName" validate:"max=100"`
+	// profile of the person when it is created, user when empty
+	ProfileCode int `json:"profileCode" validate:"min=0"`
 }
 
 type LoginSsoRequest struct {
 	Token string `json:"token"`
 }
+
+type TokenResponse struct {
+	Jwt          string `json:"jwt"`
+	RefreshToken string `json:"refreshToken"`
+	// seconds until the jwt expires
+	ExpiresIn int64 `json:"expiresIn"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
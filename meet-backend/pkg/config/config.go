@@ -4,11 +4,22 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/erodriguezg/meet/pkg/core/service"
+
 	"go.uber.org/zap"
 )
 
 func ConfigAll() {
 
+	ConfigCore()
+
+	configHttp()
+
+}
+
+// ConfigCore configures all except the http server, for the commands.
+func ConfigCore() {
+
 	configBase()
 
 	configLoggers()
@@ -21,8 +32,6 @@ func ConfigAll() {
 
 	configServices()
 
-	configHttp()
-
 }
 
 func CloseAll() {
@@ -53,6 +62,10 @@ func CloseAll() {
 
 }
 
+func GetStorageMigrationService() service.StorageMigrationService {
+	return storageMigrationService
+}
+
 func StartFiber() {
 	fiberPort := propUtils.GetIntProp("FIBER_PORT")
 	log.Info("starting! ", zap.String("app", appName), zap.String("version", version))
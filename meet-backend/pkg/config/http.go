@@ -1,10 +1,18 @@
 package config
 
 import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/erodriguezg/meet/docs"
 	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/addons"
 	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/handler"
 	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/wshandler"
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -14,7 +22,24 @@ import (
 	"github.com/gofiber/swagger"
 )
 
+const (
+	// the limit of all the api, the other names are the route groups (/security, /buy-pack)
+	apiRateLimitName = "api"
+	// the limit of the messages of each websocket client
+	wsRateLimitName = "ws"
+)
+
+// defaultRateLimits each one can be changed with RATE_LIMITS, as "security=20/1m,ws=20/10s"
+var defaultRateLimits = map[string]domain.RateLimit{
+	apiRateLimitName: {Capacity: 600, Period: time.Minute},
+	"security":       {Capacity: 20, Period: time.Minute},
+	"buy-pack":       {Capacity: 10, Period: time.Minute},
+	wsRateLimitName:  {Capacity: 20, Period: 10 * time.Second},
+}
+
 var (
+	rateLimiter *addons.RateLimiter
+	rateLimits  map[string]domain.RateLimit
 	validate    *validator.Validate
 	configFiber *fiber.Config
 	appFiber    *fiber.App
@@ -27,8 +52,9 @@ func configHttp() {
 	appFiber = configAppFiber()
 	v1Router := appFiber.Group("/api/v1")
 	configFiberMiddlewares()
+	configRateLimits(v1Router)
 	configFiberHandlers(&v1Router)
-	wshandler.InitWebSocketsHandlers(wsRoot, appFiber, log)
+	wshandler.InitWebSocketsHandlers(wsRoot, appFiber, rateLimiter, rateLimits[wsRateLimitName], log)
 	configFiberStatic()
 }
 
@@ -46,6 +72,25 @@ func configAppFiber() *fiber.App {
 		JSONDecoder:  json.Unmarshal,
 	}
 
+	// the client ip behind the load balancer, for the rate limits. The header is read only
+	// from the trusted proxies, any other client could spoof it
+	if proxyHeader := propUtils.GetProp("FIBER_PROXY_HEADER"); proxyHeader != "" {
+		trustedProxies := propUtils.GetProp("FIBER_TRUSTED_PROXIES")
+		if trustedProxies == "" {
+			panic("FIBER_TRUSTED_PROXIES is required with FIBER_PROXY_HEADER")
+		}
+		auxConfig.ProxyHeader = proxyHeader
+		auxConfig.EnableTrustedProxyCheck = true
+		for _, trustedProxy := range strings.Split(trustedProxies, ",") {
+			auxConfig.TrustedProxies = append(auxConfig.TrustedProxies, strings.TrimSpace(trustedProxy))
+		}
+	}
+
+	// the local storage receives the uploads in the backend
+	if propUtils.GetProp("STORAGE_TYPE") == "LOCAL" {
+		auxConfig.BodyLimit = propUtils.GetIntProp("LOCAL_STORAGE_MAX_UPLOAD_MB") * 1024 * 1024
+	}
+
 	configFiber = &auxConfig
 
 	return fiber.New(*configFiber)
@@ -67,6 +112,7 @@ func configFiberMiddlewares() {
 			AllowOrigins:     propUtils.GetProp("FIBER_CORS_ORIGINS"),
 			AllowCredentials: true,
 			AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+			ExposeHeaders:    "ETag",
 		}))
 
 	// swagger
@@ -76,25 +122,84 @@ func configFiberMiddlewares() {
 	appFiber.Use(wsRoot, wshandler.NewMiddlewareFunction(httpSecurityService))
 }
 
+func configRateLimits(v1 fiber.Router) {
+	panicIfAnyNil(rateLimitRepository, httpSecurityService, log)
+	rateLimiter = addons.NewRateLimiter(rateLimitRepository, httpSecurityService, log)
+	rateLimits = configRateLimitValues()
+
+	v1.Use(rateLimiter.Middleware(apiRateLimitName, rateLimits[apiRateLimitName]))
+	for name, limit := range rateLimits {
+		if name == apiRateLimitName || name == wsRateLimitName {
+			continue
+		}
+		v1.Use("/"+name, rateLimiter.Middleware(name, limit))
+	}
+}
+
+func configRateLimitValues() map[string]domain.RateLimit {
+	limits := maps.Clone(defaultRateLimits)
+	text := propUtils.GetProp("RATE_LIMITS")
+	if text == "" {
+		return limits
+	}
+	for _, item := range strings.Split(text, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found {
+			panic(fmt.Sprintf("invalid rate limit '%s', the format is name=capacity/period", item))
+		}
+		limits[name] = parseRateLimit(value)
+	}
+	return limits
+}
+
+// parseRateLimit as "20/1m", 20 requests by minute
+func parseRateLimit(text string) domain.RateLimit {
+	capacityText, periodText, found := strings.Cut(text, "/")
+	capacity, err := strconv.Atoi(capacityText)
+	if !found || err != nil || capacity <= 0 {
+		panic(fmt.Sprintf("invalid rate limit capacity '%s'", text))
+	}
+	period, err := time.ParseDuration(periodText)
+	if err != nil || period <= 0 {
+		panic(fmt.Sprintf("invalid rate limit period '%s'", text))
+	}
+	return domain.RateLimit{Capacity: capacity, Period: period}
+}
+
 func configFiberHandlers(v1 *fiber.Router) {
 
 	panicIfAnyNil(personService, httpSecurityService, profileService, modelService,
-		fileService, packService, buyPackService, chiliBankService, packPaymentMethodService,
-		roomService, log)
+		fileService, packService, packBundleService, currencyService, buyPackService, chiliBankService, packPaymentMethodService,
+		roomService, watermarkService, fileGcService, log)
 
 	v1Handlers := [...]handler.FiberHandler{
 		handler.NewHealthCheckHandler(log),
-		handler.NewPersonFiberHandler(personService, log),
-		handler.NewSecurityHandler(httpSecurityService, log),
-		handler.NewProfileFiberHandler(profileService, log),
-		handler.NewFileFiberHandler(fileService, httpSecurityService, log),
+		handler.NewPersonFiberHandler(personService, httpSecurityService, validate, log),
+		handler.NewSecurityHandler(httpSecurityService, bypassLoginEnabled, validate, log),
+		handler.NewProfileFiberHandler(profileService, httpSecurityService, validate, log),
+		handler.NewPermissionFiberHandler(permissionService, httpSecurityService, validate, log),
+		handler.NewServiceAccountFiberHandler(serviceAccountService, httpSecurityService, validate, log),
+		handler.NewAuditFiberHandler(auditService, httpSecurityService, log),
+		handler.NewCacheMetricsFiberHandler(httpSecurityService, map[string]ttlcache.StatsProvider{
+			"persons":  personCache,
+			"profiles": profileCache,
+		}, log),
+		handler.NewFileFiberHandler(fileService, packService, fileGcService, httpSecurityService, validate, log),
+		handler.NewModelFiberHandler(modelService, httpSecurityService, validate, log),
 		handler.NewPackFiberHandler(packService, httpSecurityService, validate, log),
+		handler.NewPackBundleFiberHandler(packBundleService, httpSecurityService, validate, log),
+		handler.NewCurrencyFiberHandler(currencyService, httpSecurityService, validate, log),
 		handler.NewBuyPackHandler(buyPackService, httpSecurityService, log),
 		handler.NewRoomFiberHandler(roomService, httpSecurityService, log),
+		handler.NewWatermarkFiberHandler(watermarkService, httpSecurityService, log),
 	}
 	for _, fHandler := range v1Handlers {
 		fHandler.RegisterRoutes(v1)
 	}
+
+	if localStorage != nil {
+		handler.NewLocalStorageFiberHandler(localStorage, log).RegisterRoutes(v1)
+	}
 }
 
 func configFiberStatic() {
@@ -1,41 +1,74 @@
 package config
 
 import (
+	"strings"
+
 	"github.com/erodriguezg/meet/pkg/core/repository"
 	"github.com/erodriguezg/meet/pkg/infrastructure/awscli"
 	"github.com/erodriguezg/meet/pkg/infrastructure/dropboxcli"
+	"github.com/erodriguezg/meet/pkg/infrastructure/localfs"
+	"github.com/erodriguezg/meet/pkg/infrastructure/memory"
 	"github.com/erodriguezg/meet/pkg/infrastructure/mongodb"
 	"github.com/erodriguezg/meet/pkg/infrastructure/paypalcli"
 )
 
 var (
-	personRepository            repository.PersonRepository
-	profileRepository           repository.ProfileRepository
-	storageRepository           repository.StorageRepository
-	modelRepository             repository.ModelRepository
-	fileMetaDataRepository      repository.FileMetaDataRepository
-	ownedResourceRepository     repository.OwnedResourceRepository
-	packRepository              repository.PackRepository
-	paymentClientRepository     repository.PaymentClientRepository
-	paymentOrderRepository      repository.PaymentOrderRepository
-	chiliBankRepository         repository.ChiliBankAccountRepository
-	packPaymentMethodRepository repository.PackPaymentMethodRepository
-	roomRepository              repository.RoomRepository
+	personRepository             repository.PersonRepository
+	profileRepository            repository.ProfileRepository
+	permissionRepository         repository.PermissionRepository
+	storageRepository            repository.StorageRepository
+	extraStorageRepositories     []repository.StorageRepository
+	modelRepository              repository.ModelRepository
+	fileMetaDataRepository       repository.FileMetaDataRepository
+	ownedResourceRepository      repository.OwnedResourceRepository
+	packRepository               repository.PackRepository
+	packBundleRepository         repository.PackBundleRepository
+	exchangeRateRepository       repository.ExchangeRateRepository
+	paymentClientRepository      repository.PaymentClientRepository
+	paymentOrderRepository       repository.PaymentOrderRepository
+	chiliBankRepository          repository.ChiliBankAccountRepository
+	packPaymentMethodRepository  repository.PackPaymentMethodRepository
+	roomRepository               repository.RoomRepository
+	watermarkedFileRepository    repository.WatermarkedFileRepository
+	fileBlobRepository           repository.FileBlobRepository
+	openIdLoginStateRepository   repository.OpenIdLoginStateRepository
+	refreshTokenRepository       repository.RefreshTokenRepository
+	revokedAccessTokenRepository repository.RevokedAccessTokenRepository
+	serviceAccountRepository     repository.ServiceAccountRepository
+	apiKeyRepository             repository.ApiKeyRepository
+	rateLimitRepository          repository.RateLimitRepository
+	auditEntryRepository         repository.AuditEntryRepository
+
+	// only when the storage type is LOCAL, for serve the signed urls
+	localStorage localfs.LocalStorage
 )
 
 func configRepositories() {
 	personRepository = configPersonRepository()
 	profileRepository = configProfileRepository()
-	storageRepository = configStorageRepository()
+	permissionRepository = configPermissionRepository()
+	storageRepository = configStorageRepository(propUtils.GetProp("STORAGE_TYPE"))
+	extraStorageRepositories = configExtraStorageRepositories()
 	modelRepository = configModelRepository()
 	fileMetaDataRepository = configFileMetaDataRepository()
 	ownedResourceRepository = configOwnedResourceRepository()
 	packRepository = configPackRepository()
+	packBundleRepository = configPackBundleRepository()
+	exchangeRateRepository = configExchangeRateRepository()
 	paymentClientRepository = configPaymentClientRepository()
 	paymentOrderRepository = configPaymentOrderRepository()
 	chiliBankRepository = configChiliBankRepository()
 	packPaymentMethodRepository = configPackPaymentMethodRepository()
 	roomRepository = configRoomRepository()
+	watermarkedFileRepository = configWatermarkedFileRepository()
+	fileBlobRepository = configFileBlobRepository()
+	openIdLoginStateRepository = configOpenIdLoginStateRepository()
+	refreshTokenRepository = configRefreshTokenRepository()
+	revokedAccessTokenRepository = configRevokedAccessTokenRepository()
+	serviceAccountRepository = configServiceAccountRepository()
+	apiKeyRepository = configApiKeyRepository()
+	rateLimitRepository = configRateLimitRepository()
+	auditEntryRepository = configAuditEntryRepository()
 }
 
 func configPersonRepository() repository.PersonRepository {
@@ -50,12 +83,28 @@ func configModelRepository() repository.ModelRepository {
 	return mongodb.NewModelMongoDB(mongoDB)
 }
 
-func configStorageRepository() repository.StorageRepository {
-	storageType := propUtils.GetProp("STORAGE_TYPE")
+// configExtraStorageRepositories the files are read also from these storages, used while
+// the files are migrated between storages.
+func configExtraStorageRepositories() []repository.StorageRepository {
+	var storages []repository.StorageRepository
+	for _, storageType := range propUtils.GetStringArray("STORAGE_EXTRA_TYPES", ",") {
+		storageType = strings.TrimSpace(storageType)
+		if storageType == "" || storageType == storageRepository.GetStorageType() {
+			continue
+		}
+		storages = append(storages, configStorageRepository(storageType))
+	}
+	return storages
+}
+
+func configStorageRepository(storageType string) repository.StorageRepository {
 	if storageType == "S3" {
 		return configS3StorageRepository()
 	} else if storageType == "DROPBOX" {
 		return configDropboxStorageRepository()
+	} else if storageType == "LOCAL" {
+		localStorage = configLocalStorageRepository()
+		return localStorage
 	} else {
 		panic("incompatible storage type: " + storageType)
 	}
@@ -75,6 +124,16 @@ func configDropboxStorageRepository() repository.StorageRepository {
 	)
 }
 
+func configLocalStorageRepository() localfs.LocalStorage {
+	config := localfs.LocalStorageConfig{
+		BaseDir:    propUtils.GetProp("LOCAL_STORAGE_DIR"),
+		PublicUrl:  propUtils.GetProp("LOCAL_STORAGE_PUBLIC_URL"),
+		SigningKey: []byte(propUtils.GetProp("LOCAL_STORAGE_SIGNING_KEY")),
+	}
+	panicIfAnyNil(log)
+	return localfs.NewStorageLocal(config, log)
+}
+
 func configS3StorageRepository() repository.StorageRepository {
 	config := awscli.S3StorageConfig{
 		RestEndPoint:           propUtils.GetProp("S3_REST_ENDPOINT"),
@@ -103,6 +162,16 @@ func configPackRepository() repository.PackRepository {
 	return mongodb.NewPackMongoDB(mongoDB)
 }
 
+func configPackBundleRepository() repository.PackBundleRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewPackBundleMongoDB(mongoDB)
+}
+
+func configExchangeRateRepository() repository.ExchangeRateRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewExchangeRateMongoDB(mongoDB)
+}
+
 func configPaymentClientRepository() repository.PaymentClientRepository {
 	panicIfAnyNil(httpClient)
 	apiUrl := propUtils.GetProp("PAYPAL_API_URL")
@@ -130,3 +199,62 @@ func configRoomRepository() repository.RoomRepository {
 	panicIfAnyNil(mongoDB)
 	return mongodb.NewRoomMongoDB(mongoDB)
 }
+
+func configWatermarkedFileRepository() repository.WatermarkedFileRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewWatermarkedFileMongoDB(mongoDB)
+}
+
+func configFileBlobRepository() repository.FileBlobRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewFileBlobMongoDB(mongoDB)
+}
+
+func configOpenIdLoginStateRepository() repository.OpenIdLoginStateRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewOpenIdLoginStateMongoDB(mongoDB)
+}
+
+func configRefreshTokenRepository() repository.RefreshTokenRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewRefreshTokenMongoDB(mongoDB)
+}
+
+func configRevokedAccessTokenRepository() repository.RevokedAccessTokenRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewRevokedAccessTokenMongoDB(mongoDB)
+}
+
+func configServiceAccountRepository() repository.ServiceAccountRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewServiceAccountMongoDB(mongoDB)
+}
+
+func configApiKeyRepository() repository.ApiKeyRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewApiKeyMongoDB(mongoDB)
+}
+
+func configAuditEntryRepository() repository.AuditEntryRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewAuditEntryMongoDB(mongoDB)
+}
+
+// configRateLimitRepository MEMORY (default) for a single replica, MONGODB for share the
+// limits between the replicas
+func configRateLimitRepository() repository.RateLimitRepository {
+	store := propUtils.GetProp("RATE_LIMIT_STORE")
+	if store == "" || store == "MEMORY" {
+		return memory.NewRateLimitMemory()
+	} else if store == "MONGODB" {
+		panicIfAnyNil(mongoDB)
+		return mongodb.NewRateLimitMongoDB(mongoDB)
+	} else {
+		panic("incompatible rate limit store: " + store)
+	}
+}
+
+func configPermissionRepository() repository.PermissionRepository {
+	panicIfAnyNil(mongoDB)
+	return mongodb.NewPermissionMongoDB(mongoDB)
+}
@@ -1,88 +1,211 @@
 package config
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/erodriguezg/meet/pkg/application/http/rest/fiber/fiberidentity"
 	"github.com/erodriguezg/meet/pkg/application/http/security"
 	"github.com/erodriguezg/meet/pkg/core/service"
 	"github.com/erodriguezg/meet/pkg/util/openid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultModelNickNameChangeCooldownDays = 30
+	defaultModelNickNameReservationDays    = 180
+	// the web client refreshes the jwt with the refresh token before it expires
+	defaultAccessTokenDurationMinutes = 15
 )
 
 var (
-	openIdService            openid.OpenIdService
+	openIdProviderRegistry   openid.OpenIdProviderRegistry
 	personService            service.PersonService
 	profileService           service.ProfileService
+	permissionService        service.PermissionService
 	modelService             service.ModelService
 	httpSecurityService      security.HttpSecurityService
 	storageService           service.StorageService
 	fileService              service.FileService
 	ownedResourceService     service.OwnedResourceService
 	packService              service.PackService
+	packBundleService        service.PackBundleService
+	currencyService          service.CurrencyService
 	buyPackService           service.BuyPackService
 	chiliBankService         service.ChiliBankAccountService
 	packPaymentMethodService service.PackPaymentMethodService
 	roomService              service.RoomService
+	watermarkService         service.WatermarkService
+	fileGcService            service.FileGcService
+	storageMigrationService  service.StorageMigrationService
+	authSessionService       service.AuthSessionService
+	serviceAccountService    service.ServiceAccountService
+	auditService             service.AuditService
+	bypassLoginEnabled       bool
 )
 
 func configServices() {
+	auditService = configAuditService()
 	storageService = configStorageService()
 	fileService = configFileService()
 	personService = configPersonService()
 	modelService = configModelService()
-	openIdService = configOpenIdService()
+	openIdProviderRegistry = configOpenIdProviderRegistry()
 	profileService = configProfileService()
+	permissionService = configPermissionService()
+	authSessionService = configAuthSessionService()
+	serviceAccountService = configServiceAccountService()
+	bypassLoginEnabled = configBypassLoginEnabled()
 	httpSecurityService = configHttpSecurityService()
 	ownedResourceService = configOwnedResourceService()
+	watermarkService = configWatermarkService()
 	packService = configPackService()
+	packBundleService = configPackBundleService()
+	currencyService = configCurrencyService()
 	buyPackService = configBuyPackService()
 	chiliBankService = configChileBankService()
 	packPaymentMethodService = configPackPaymentMethodService()
 	roomService = configRoomService()
+	fileGcService = configFileGcService()
+	storageMigrationService = configStorageMigrationService()
+}
+
+// configOpenIdProviderRegistry the providers of OPENID_PROVIDERS (google by default), each one
+// configured with the OPENID_<NAME>_* properties. The google provider keeps reading the
+// GOOGLE_OPENID_* properties.
+func configOpenIdProviderRegistry() openid.OpenIdProviderRegistry {
+	panicIfAnyNil(httpClient, jwtUtil, log)
+
+	var providers []openid.OpenIdService
+	for _, name := range propUtils.GetStringArray("OPENID_PROVIDERS", ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		providers = append(providers, openid.NewOidcOpenIdService(configOpenIdProvider(name), httpClient, jwtUtil, log))
+	}
+	if len(providers) == 0 {
+		providers = append(providers, openid.NewOidcOpenIdService(configOpenIdProvider("google"), httpClient, jwtUtil, log))
+	}
+	return openid.NewOpenIdProviderRegistry(providers...)
 }
 
-func configOpenIdService() openid.OpenIdService {
+func configOpenIdProvider(name string) openid.OpenIdConfig {
+	prefix := "OPENID_" + strings.ToUpper(name) + "_"
 	config := openid.OpenIdConfig{
-		ResponseType: "code",
-		Scope:        "email openid profile",
-		ClientId:     propUtils.GetProp("GOOGLE_OPENID_CLIENT_ID"),
-		ClientSecret: propUtils.GetProp("GOOGLE_OPENID_CLIENT_SECRET"),
-		RedirectUri:  propUtils.GetProp("GOOGLE_OPENID_REDIRECT_URL"),
+		Name:         name,
+		Issuer:       propUtils.GetProp(prefix + "ISSUER"),
+		ClientId:     propUtils.GetProp(prefix + "CLIENT_ID"),
+		ClientSecret: propUtils.GetProp(prefix + "CLIENT_SECRET"),
+		RedirectUri:  propUtils.GetProp(prefix + "REDIRECT_URL"),
+		Scope:        propUtils.GetProp(prefix + "SCOPE"),
+		TrustEmails:  propUtils.GetProp(prefix+"TRUST_EMAILS") == "true",
+	}
+	if name == "google" {
+		if config.Issuer == "" {
+			config.Issuer = openid.GoogleIssuer
+		}
+		if config.ClientId == "" {
+			config.ClientId = propUtils.GetProp("GOOGLE_OPENID_CLIENT_ID")
+			config.ClientSecret = propUtils.GetProp("GOOGLE_OPENID_CLIENT_SECRET")
+			config.RedirectUri = propUtils.GetProp("GOOGLE_OPENID_REDIRECT_URL")
+		}
+	}
+	if config.Issuer == "" || config.ClientId == "" {
+		panic(fmt.Sprintf("the openid provider %s requires the issuer and the client id", name))
 	}
-	panicIfAnyNil(config, googleOAuth2Api, jwtUtil, log)
-	return openid.NewGoogleOpenIdService(config, googleOAuth2Api, jwtUtil, log)
+	return config
 }
 
 func configPersonService() service.PersonService {
-	panicIfAnyNil(personRepository)
-	return service.NewDomainPersonService(personRepository)
+	panicIfAnyNil(personRepository, profileRepository, personCache, auditService)
+	return service.NewDomainPersonService(personRepository, profileRepository, personCache, auditService)
+}
+
+func configAuditService() service.AuditService {
+	panicIfAnyNil(auditEntryRepository, log)
+	return service.NewDomainAuditService(auditEntryRepository, log)
 }
 
 func configProfileService() service.ProfileService {
-	panicIfAnyNil(profileRepository)
-	return service.NewDomainProfileService(profileRepository)
+	panicIfAnyNil(profileRepository, permissionRepository, personRepository, profileCache, auditService)
+	return service.NewDomainProfileService(profileRepository, permissionRepository, personRepository, profileCache, auditService)
+}
+
+func configPermissionService() service.PermissionService {
+	panicIfAnyNil(permissionRepository, profileRepository, auditService)
+	return service.NewDomainPermissionService(permissionRepository, profileRepository, auditService)
 }
 
 func configHttpSecurityService() security.HttpSecurityService {
-	panicIfAnyNil(openIdService, personService, profileService, rsaPrivateKeyBytes, rsaPublicKeyBytes)
+	panicIfAnyNil(openIdProviderRegistry, personService, profileService, authSessionService, openIdLoginStateRepository, rsaPrivateKeyBytes, rsaPublicKeyBytes)
 
 	openIdPassPhrase := propUtils.GetProp("SECURE_PASSPHRASE_OPENID")
 
-	identityUtil := fiberidentity.NewFiberIdentityUtil(personService, profileService, modelService, rsaPublicKeyBytes)
-	return security.NewDefaultHttpSecurityService(openIdService, personService, identityUtil, rsaPrivateKeyBytes, openIdPassPhrase)
+	identityUtil := fiberidentity.NewFiberIdentityUtil(personService, profileService, modelService, authSessionService, serviceAccountService, rsaPublicKeyBytes)
+	return security.NewDefaultHttpSecurityService(openIdProviderRegistry, personService, profileService, identityUtil, openIdLoginStateRepository, authSessionService, rsaPrivateKeyBytes, openIdPassPhrase, configAccessTokenDuration(), bypassLoginEnabled)
+}
+
+// configAccessTokenDuration a revoked access token is accepted until it expires in the other
+// instances, keep it short
+func configAccessTokenDuration() time.Duration {
+	minutes := defaultAccessTokenDurationMinutes
+	if propUtils.GetProp("SECURITY_ACCESS_TOKEN_MINUTES") != "" {
+		minutes = propUtils.GetIntProp("SECURITY_ACCESS_TOKEN_MINUTES")
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// configBypassLoginEnabled the login without openid is for the local and end to end tests,
+// the application does not start with it in production.
+func configBypassLoginEnabled() bool {
+	if propUtils.GetProp("SECURITY_BYPASS_LOGIN_ENABLED") == "" || !propUtils.GetBoolProp("SECURITY_BYPASS_LOGIN_ENABLED") {
+		return false
+	}
+	if env == "PROD" {
+		panic("the bypass login can not be enabled in the PROD environment")
+	}
+	log.Warn("the bypass login is enabled, any email can get a token", zap.String("env", env))
+	return true
+}
+
+func configAuthSessionService() service.AuthSessionService {
+	panicIfAnyNil(personRepository, refreshTokenRepository, revokedAccessTokenRepository, log)
+	return service.NewDomainAuthSessionService(personRepository, refreshTokenRepository, revokedAccessTokenRepository, log)
+}
+
+func configServiceAccountService() service.ServiceAccountService {
+	panicIfAnyNil(serviceAccountRepository, apiKeyRepository, permissionRepository, auditService, log)
+	return service.NewDomainServiceAccountService(serviceAccountRepository, apiKeyRepository, permissionRepository, auditService, log)
 }
 
 func configStorageService() service.StorageService {
 	panicIfAnyNil(storageRepository)
-	return service.NewDomainStorageService(storageRepository)
+	legacyStorageType := propUtils.GetProp("STORAGE_LEGACY_TYPE")
+	return service.NewDomainStorageService(storageRepository, legacyStorageType, extraStorageRepositories...)
 }
 
 func configFileService() service.FileService {
-	panicIfAnyNil(storageService, fileMetaDataRepository)
-	return service.NewFileService(storageService, fileMetaDataRepository)
+	panicIfAnyNil(storageService, fileMetaDataRepository, fileBlobRepository)
+	return service.NewFileService(storageService, fileMetaDataRepository, fileBlobRepository)
 }
 
 func configModelService() service.ModelService {
-	panicIfAnyNil(personService, fileService, modelRepository)
-	return service.NewDomainModelService(personService, fileService, modelRepository)
+	panicIfAnyNil(personService, fileService, auditService, modelRepository, log)
+	return service.NewDomainModelService(personService, fileService, auditService, modelRepository,
+		configDaysProp("MODEL_NICKNAME_CHANGE_COOLDOWN_DAYS", defaultModelNickNameChangeCooldownDays),
+		configDaysProp("MODEL_NICKNAME_RESERVATION_DAYS", defaultModelNickNameReservationDays),
+		log)
+}
+
+// configDaysProp the optional property of a period in days
+func configDaysProp(name string, defaultDays int) time.Duration {
+	days := defaultDays
+	if propUtils.GetProp(name) != "" {
+		days = propUtils.GetIntProp(name)
+	}
+	return time.Duration(days) * 24 * time.Hour
 }
 
 func configOwnedResourceService() service.OwnedResourceService {
@@ -90,18 +213,36 @@ func configOwnedResourceService() service.OwnedResourceService {
 	return service.NewDomainOwnedResourceService(ownedResourceRepository)
 }
 
+func configWatermarkService() service.WatermarkService {
+	panicIfAnyNil(personService, fileService, paymentOrderRepository, watermarkedFileRepository)
+	key := []byte(propUtils.GetProp("WATERMARK_KEY"))
+	return service.NewDomainWatermarkService(personService, fileService, paymentOrderRepository,
+		watermarkedFileRepository, key)
+}
+
 func configPackService() service.PackService {
 	panicIfAnyNil(personService, profileService, modelService, ownedResourceService,
-		fileService, packRepository)
+		fileService, watermarkService, packRepository, auditService, log)
 	return service.NewDomainPackService(personService, profileService, modelService, ownedResourceService,
-		fileService, packRepository)
+		fileService, watermarkService, packRepository, auditService, log)
+}
+
+func configPackBundleService() service.PackBundleService {
+	panicIfAnyNil(personService, profileService, modelService, packService, packBundleRepository)
+	return service.NewDomainPackBundleService(personService, profileService, modelService, packService,
+		packBundleRepository)
+}
+
+func configCurrencyService() service.CurrencyService {
+	panicIfAnyNil(exchangeRateRepository)
+	return service.NewDomainCurrencyService(exchangeRateRepository)
 }
 
 func configBuyPackService() service.BuyPackService {
-	panicIfAnyNil(personService, modelService, packService, ownedResourceService,
-		paymentClientRepository, paymentOrderRepository)
-	return service.NewDomainBuyPackService(personService, modelService, packService, ownedResourceService,
-		paymentClientRepository, paymentOrderRepository)
+	panicIfAnyNil(personService, modelService, packService, packBundleService, ownedResourceService,
+		currencyService, paymentClientRepository, paymentOrderRepository, auditService)
+	return service.NewDomainBuyPackService(personService, modelService, packService, packBundleService,
+		ownedResourceService, currencyService, paymentClientRepository, paymentOrderRepository, auditService)
 }
 
 func configChileBankService() service.ChiliBankAccountService {
@@ -112,11 +253,22 @@ func configChileBankService() service.ChiliBankAccountService {
 }
 
 func configPackPaymentMethodService() service.PackPaymentMethodService {
-	panicIfAnyNil(packService, packPaymentMethodRepository)
-	return service.NewPackPaymentMethodService(packService, packPaymentMethodRepository)
+	panicIfAnyNil(packService, currencyService, packRepository, packPaymentMethodRepository)
+	return service.NewPackPaymentMethodService(packService, currencyService, packRepository, packPaymentMethodRepository)
 }
 
 func configRoomService() service.RoomService {
-	panicIfAnyNil(roomRepository, personRepository)
-	return service.NewDomainRoomService(roomRepository, personService)
+	panicIfAnyNil(roomRepository, personRepository, auditService)
+	return service.NewDomainRoomService(roomRepository, personService, auditService)
+}
+
+func configFileGcService() service.FileGcService {
+	panicIfAnyNil(fileService, fileMetaDataRepository, packRepository, modelRepository, watermarkedFileRepository)
+	return service.NewDomainFileGcService(fileService, fileMetaDataRepository, packRepository, modelRepository,
+		watermarkedFileRepository)
+}
+
+func configStorageMigrationService() service.StorageMigrationService {
+	panicIfAnyNil(storageService, fileMetaDataRepository, fileBlobRepository, log)
+	return service.NewDomainStorageMigrationService(storageService, fileMetaDataRepository, fileBlobRepository, log)
 }
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/util/configpropertyutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func setBypassLoginConfig(t *testing.T, environment string, enabled string) {
+	previousPropUtils, previousEnv, previousLog := propUtils, env, log
+	t.Cleanup(func() {
+		propUtils, env, log = previousPropUtils, previousEnv, previousLog
+	})
+	t.Setenv("SECURITY_BYPASS_LOGIN_ENABLED", enabled)
+	propUtils = &configpropertyutil.GoEnvConfigPropertiesUtil{}
+	env = environment
+	log = zap.NewNop()
+}
+
+func TestConfigBypassLoginEnabledIsDisabledByDefault(t *testing.T) {
+	setBypassLoginConfig(t, "dev", "")
+	assert.False(t, configBypassLoginEnabled())
+
+	setBypassLoginConfig(t, "PROD", "false")
+	assert.False(t, configBypassLoginEnabled())
+}
+
+func TestConfigBypassLoginEnabled(t *testing.T) {
+	setBypassLoginConfig(t, "dev", "true")
+	assert.True(t, configBypassLoginEnabled())
+}
+
+func TestConfigBypassLoginEnabledPanicsInProd(t *testing.T) {
+	setBypassLoginConfig(t, "PROD", "true")
+	assert.Panics(t, func() {
+		configBypassLoginEnabled()
+	})
+}
@@ -2,21 +2,30 @@ package config
 
 import (
 	"net/http"
+	"time"
 
-	"github.com/erodriguezg/meet/pkg/util/googleapi"
+	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/util/jwtutil"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
+)
+
+const (
+	defaultIdentityCacheTtl     = 30 * time.Second
+	defaultIdentityCacheMaxSize = 1000
 )
 
 var (
-	httpClient      *http.Client
-	jwtUtil         jwtutil.JwtUtil
-	googleOAuth2Api googleapi.OAuth2Api
+	httpClient   *http.Client
+	jwtUtil      jwtutil.JwtUtil
+	personCache  *ttlcache.Cache[string, domain.Person]
+	profileCache *ttlcache.Cache[int, domain.Profile]
 )
 
 func configUtils() {
 	httpClient = configHttpClient()
 	jwtUtil = configGolangJwtUtil()
-	googleOAuth2Api = configGoogleOAuth2Api()
+	personCache = ttlcache.New[string, domain.Person](configIdentityCacheTtl(), configIdentityCacheMaxSize())
+	profileCache = ttlcache.New[int, domain.Profile](configIdentityCacheTtl(), configIdentityCacheMaxSize())
 }
 
 func configHttpClient() *http.Client {
@@ -27,7 +36,17 @@ func configGolangJwtUtil() jwtutil.JwtUtil {
 	return jwtutil.NewGolangJwtUtil()
 }
 
-func configGoogleOAuth2Api() googleapi.OAuth2Api {
-	panicIfAnyNil(httpClient)
-	return googleapi.NewNetHttpOauth2Api(httpClient)
+// configIdentityCacheTtl with several instances a change is seen by the others after the ttl
+func configIdentityCacheTtl() time.Duration {
+	if propUtils.GetProp("IDENTITY_CACHE_TTL_SECONDS") == "" {
+		return defaultIdentityCacheTtl
+	}
+	return time.Duration(propUtils.GetIntProp("IDENTITY_CACHE_TTL_SECONDS")) * time.Second
+}
+
+func configIdentityCacheMaxSize() int {
+	if propUtils.GetProp("IDENTITY_CACHE_MAX_SIZE") == "" {
+		return defaultIdentityCacheMaxSize
+	}
+	return propUtils.GetIntProp("IDENTITY_CACHE_MAX_SIZE")
 }
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditActionPersonSave           = "person.save"
+	AuditActionPersonDelete         = "person.delete"
+	AuditActionPackPublish          = "pack.publish"
+	AuditActionRoomChangeVisibility = "room.change-visibility"
+	AuditActionRoomDelete           = "room.delete"
+	AuditActionPaymentCapture       = "payment.capture"
+	AuditActionModelChangeNickName  = "model.change-nickname"
+	AuditActionProfileSave          = "profile.save"
+	AuditActionProfileDelete        = "profile.delete"
+	AuditActionPermissionSave       = "permission.save"
+	AuditActionPermissionDelete     = "permission.delete"
+	AuditActionServiceAccountSave   = "service-account.save"
+	AuditActionServiceAccountDelete = "service-account.delete"
+	AuditActionApiKeyCreate         = "api-key.create"
+	AuditActionApiKeyRevoke         = "api-key.revoke"
+)
+
+const (
+	AuditTargetPerson         = "person"
+	AuditTargetPack           = "pack"
+	AuditTargetRoom           = "room"
+	AuditTargetPaymentOrder   = "payment-order"
+	AuditTargetModel          = "model"
+	AuditTargetProfile        = "profile"
+	AuditTargetPermission     = "permission"
+	AuditTargetServiceAccount = "service-account"
+	AuditTargetApiKey         = "api-key"
+)
+
+// AuditActor who did the action, a person or a service account with the ip of the request,
+// or a process of the system (as the openid login) without request.
+type AuditActor struct {
+	PersonId         string `json:"personId,omitempty" bson:"personId,omitempty"`
+	ServiceAccountId string `json:"serviceAccountId,omitempty" bson:"serviceAccountId,omitempty"`
+	Ip               string `json:"ip,omitempty" bson:"ip,omitempty"`
+	System           string `json:"system,omitempty" bson:"system,omitempty"`
+}
+
+func NewSystemAuditActor(system string) AuditActor {
+	return AuditActor{System: system}
+}
+
+// AuditChange the value of a field before and after the action, nil when the field did not
+// exist (creation) or does not exist anymore (deletion).
+type AuditChange struct {
+	Field  string `json:"field" bson:"field"`
+	Before any    `json:"before" bson:"before"`
+	After  any    `json:"after" bson:"after"`
+}
+
+// AuditEntry the entries are only appended, never updated nor deleted.
+type AuditEntry struct {
+	Id         *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Date       time.Time           `json:"date" bson:"date"`
+	Actor      AuditActor          `json:"actor" bson:"actor"`
+	Action     string              `json:"action" bson:"action"`
+	TargetType string              `json:"targetType" bson:"targetType"`
+	TargetId   string              `json:"targetId" bson:"targetId"`
+	Changes    []AuditChange       `json:"changes" bson:"changes"`
+}
+
+type AuditFilter struct {
+	ActorPersonId *string    `json:"actorPersonId"`
+	Action        *string    `json:"action"`
+	TargetType    *string    `json:"targetType"`
+	TargetId      *string    `json:"targetId"`
+	DateLower     *time.Time `json:"dateLower"`
+	DateUpper     *time.Time `json:"dateUpper"`
+}
+
+type AuditSearchResponse struct {
+	TotalCount int          `json:"totalCount"`
+	Entries    []AuditEntry `json:"entries"`
+}
+
+// NewAuditChanges the fields (by their json names) with different values in before and after,
+// the fields hidden from the json (as the secrets) are not recorded.
+func NewAuditChanges(before any, after any) ([]AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, found := beforeFields[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []AuditChange{}
+	for _, name := range names {
+		beforeValue, afterValue := beforeFields[name], afterFields[name]
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changes = append(changes, AuditChange{Field: name, Before: beforeValue, After: afterValue})
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(value any) (map[string]any, error) {
+	fields := map[string]any{}
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil()) {
+		return fields, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
@@ -0,0 +1,37 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuditChangesOnlyTheChangedFields(t *testing.T) {
+	before := domain.Person{Email: "a@meet.com", FirstName: "Ana", ProfileCode: domain.ProfileCodeUser, Active: true}
+	after := before
+	after.ProfileCode = domain.ProfileCodeModel
+	after.Active = false
+
+	changes, err := domain.NewAuditChanges(&before, &after)
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.AuditChange{
+		{Field: "active", Before: true, After: false},
+		{Field: "profileCode", Before: float64(domain.ProfileCodeUser), After: float64(domain.ProfileCodeModel)},
+	}, changes)
+}
+
+func TestNewAuditChangesOfCreation(t *testing.T) {
+	var before *domain.Permission
+	after := domain.Permission{Code: 9, Name: "Export"}
+
+	changes, err := domain.NewAuditChanges(before, after)
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.AuditChange{
+		{Field: "code", Before: nil, After: float64(9)},
+		{Field: "name", Before: nil, After: "Export"},
+	}, changes)
+}
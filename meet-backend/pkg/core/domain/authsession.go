@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken is stored by the sha256 of its value, every use rotates it to a new token of
+// the same family and a reused token revokes the whole family.
+type RefreshToken struct {
+	Id        *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	TokenHash string              `json:"tokenHash" bson:"tokenHash"`
+	FamilyId  string              `json:"familyId" bson:"familyId"`
+	PersonId  primitive.ObjectID  `json:"personId" bson:"personId"`
+	// date of the login that started the family
+	AuthDate       time.Time  `json:"authDate" bson:"authDate"`
+	CreationDate   time.Time  `json:"creationDate" bson:"creationDate"`
+	ExpirationDate time.Time  `json:"expirationDate" bson:"expirationDate"`
+	UsedDate       *time.Time `json:"usedDate,omitempty" bson:"usedDate,omitempty"`
+	RevokedDate    *time.Time `json:"revokedDate,omitempty" bson:"revokedDate,omitempty"`
+}
+
+func (model *RefreshToken) IsUsable(now time.Time) bool {
+	return model.UsedDate == nil && model.RevokedDate == nil && now.Before(model.ExpirationDate)
+}
+
+// RevokedAccessToken an access token (by its jti) rejected until it expires.
+type RevokedAccessToken struct {
+	Id             *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Jti            string              `json:"jti" bson:"jti"`
+	ExpirationDate time.Time           `json:"expirationDate" bson:"expirationDate"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CurrencyCodeUSD = "USD"
+	CurrencyCodeCLP = "CLP"
+
+	ExchangeRateSourceAdmin = "ADMIN"
+	ExchangeRateSourceCsv   = "CSV"
+)
+
+// currencies without minor units, following ISO 4217
+var zeroDecimalCurrencies = map[string]bool{
+	"CLP": true,
+	"JPY": true,
+	"KRW": true,
+	"PYG": true,
+	"VND": true,
+}
+
+type ExchangeRate struct {
+	Id           *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	CurrencyCode string              `json:"currencyCode" bson:"currencyCode"`
+	UnitsPerUSD  float64             `json:"unitsPerUsd" bson:"unitsPerUsd"`
+	Source       string              `json:"source" bson:"source"`
+	UpdateDate   time.Time           `json:"updateDate" bson:"updateDate"`
+}
+
+// RoundCurrency rounds the value to the minor units of the currency.
+func RoundCurrency(value float64, currencyCode string) float64 {
+	if zeroDecimalCurrencies[currencyCode] {
+		return math.Round(value)
+	}
+	return math.Round(value*100) / 100
+}
+
+// FormatCurrency the value rounded to the minor units of the currency, without decimals for the
+// zero decimal currencies, as the payment providers expect the amounts.
+func FormatCurrency(value float64, currencyCode string) string {
+	decimals := 2
+	if zeroDecimalCurrencies[currencyCode] {
+		decimals = 0
+	}
+	return strconv.FormatFloat(RoundCurrency(value, currencyCode), 'f', decimals, 64)
+}
+
+// ConvertFromUSD converts a dollar value using the rate expressed as units of the
+// currency per one dollar.
+func ConvertFromUSD(dollarValue float64, unitsPerUSD float64, currencyCode string) float64 {
+	return RoundCurrency(dollarValue*unitsPerUSD, currencyCode)
+}
+
+// ConvertToUSD normalizes a value in the currency to dollars.
+func ConvertToUSD(value float64, unitsPerUSD float64) float64 {
+	if unitsPerUSD <= 0 {
+		return 0
+	}
+	return RoundCurrency(value/unitsPerUSD, CurrencyCodeUSD)
+}
@@ -0,0 +1,33 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertFromUSDZeroDecimalCurrency(t *testing.T) {
+	actual := domain.ConvertFromUSD(9.99, 945.37, "CLP")
+
+	assert.Equal(t, 9444.0, actual)
+}
+
+func TestConvertFromUSD(t *testing.T) {
+	actual := domain.ConvertFromUSD(10, 0.9234, "EUR")
+
+	assert.Equal(t, 9.23, actual)
+}
+
+func TestConvertToUSD(t *testing.T) {
+	actual := domain.ConvertToUSD(9444, 945.37)
+
+	assert.Equal(t, 9.99, actual)
+}
+
+func TestFormatCurrency(t *testing.T) {
+	assert.Equal(t, "9444", domain.FormatCurrency(9443.6, "CLP"))
+	assert.Equal(t, "1500", domain.FormatCurrency(1500, "JPY"))
+	assert.Equal(t, "9.23", domain.FormatCurrency(9.234, "EUR"))
+	assert.Equal(t, "10.00", domain.FormatCurrency(10, "USD"))
+}
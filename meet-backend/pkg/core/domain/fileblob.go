@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FileBlob is a stored object shared by all the uploaded files with the same content,
+// the object is deleted when the last file that references it is deleted.
+type FileBlob struct {
+	Id           *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Digest       string              `json:"digest" bson:"digest"`
+	StorageType  string              `json:"storageType" bson:"storageType"`
+	Path         string              `json:"path" bson:"path"`
+	Size         int64               `json:"size" bson:"size"`
+	RefCount     int                 `json:"refCount" bson:"refCount"`
+	CreationDate time.Time           `json:"creationDate" bson:"creationDate"`
+	// tombstone of the blob without references, it can't be acquired until its object and
+	// then its record are deleted
+	DeletingDate *time.Time `json:"deletingDate,omitempty" bson:"deletingDate,omitempty"`
+}
+
+// FileBlobPath is the path of the object for the sha256 digest (hex) of the content,
+// the extension is kept for the storages that infer the content type from it.
+func FileBlobPath(digest string, extension string) string {
+	return "blobs/" + digest[:2] + "/" + digest + extension
+}
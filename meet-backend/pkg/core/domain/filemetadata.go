@@ -2,16 +2,103 @@ package domain
 
 import (
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	FilePreviewThumbnail = "thumbnail"
+	FilePreviewLocked    = "locked"
+
+	// the storages accept parts of 5MB at least (except the last) and 10000 parts
+	MultipartMinPartSize  = 8 * 1024 * 1024
+	MultipartMaxPartCount = 10000
+)
+
 type FileMetaData struct {
 	Id          *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Hash        string              `json:"hash" bson:"hash"`
 	Path        string              `json:"path" bson:"path"`
 	Uploaded    bool                `json:"uploaded" bson:"uploaded"`
 	DownloadUrl *string             `json:"downloadUrl,omitempty" bson:"downloadUrl,omitempty"`
+	// storage where the file is, empty for the files written before it was recorded
+	StorageType      string              `json:"storageType,omitempty" bson:"storageType,omitempty"`
+	TypeCode         string              `json:"typeCode,omitempty" bson:"typeCode,omitempty"`
+	UploaderPersonId *primitive.ObjectID `json:"uploaderPersonId,omitempty" bson:"uploaderPersonId,omitempty"`
+	Size             int64               `json:"size,omitempty" bson:"size,omitempty"`
+	ContentType      string              `json:"contentType,omitempty" bson:"contentType,omitempty"`
+	Checksum         string              `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	// sha256 (hex) of the content of the uploaded files, the object is a shared FileBlob
+	ContentDigest string     `json:"contentDigest,omitempty" bson:"contentDigest,omitempty"`
+	UploadedDate  *time.Time `json:"uploadedDate,omitempty" bson:"uploadedDate,omitempty"`
+	// hashes of the previews generated by the backend, by preview kind
+	Previews map[string]string `json:"previews,omitempty" bson:"previews,omitempty"`
+	// for the previews, the hash of the file used for generate it
+	SourceFileHash *string `json:"sourceFileHash,omitempty" bson:"sourceFileHash,omitempty"`
+	// the image was re-encoded without metadata (exif, gps, etc) when confirmed
+	Sanitized bool `json:"sanitized,omitempty" bson:"sanitized,omitempty"`
+	// the width and height that the uploaded image must have at least, 0 without minimum
+	MinImageSize int `json:"minImageSize,omitempty" bson:"minImageSize,omitempty"`
+	// state of the upload in parts, removed when completed (not omitempty for allow unset it)
+	MultipartUpload *FileMultipartUpload `json:"multipartUpload,omitempty" bson:"multipartUpload"`
+}
+
+// FileMultipartUpload is the state of a large file uploaded in parts, persisted so the
+// client can resume the upload uploading only the missing parts.
+type FileMultipartUpload struct {
+	UploadId       string           `json:"uploadId" bson:"uploadId"`
+	Size           int64            `json:"size" bson:"size"`
+	PartSize       int64            `json:"partSize" bson:"partSize"`
+	PartCount      int              `json:"partCount" bson:"partCount"`
+	CompletedParts []FileUploadPart `json:"completedParts" bson:"completedParts"`
+	StartDate      time.Time        `json:"startDate" bson:"startDate"`
+}
+
+type FileUploadPart struct {
+	PartNumber int    `json:"partNumber" bson:"partNumber"`
+	ETag       string `json:"etag" bson:"etag"`
+}
+
+// FileStat is the information of a stored file reported by the storage.
+// The checksum is prefixed with the algorithm, ex: "sha256:...", "etag:...".
+type FileStat struct {
+	Size        int64
+	ContentType string
+	Checksum    string
+}
+
+// MultipartPartSize returns the size of the parts for upload a file of the size, the
+// parts grow when the file would need more than MultipartMaxPartCount parts.
+func MultipartPartSize(size int64) int64 {
+	partSize := int64(MultipartMinPartSize)
+	for size > partSize*MultipartMaxPartCount {
+		partSize *= 2
+	}
+	return partSize
+}
+
+// MultipartPartCount returns the number of parts of partSize needed for the size.
+func MultipartPartCount(size int64, partSize int64) int {
+	if size <= 0 {
+		return 1
+	}
+	return int((size + partSize - 1) / partSize)
+}
+
+// MissingParts returns the part numbers not uploaded yet.
+func (upload *FileMultipartUpload) MissingParts() []int {
+	completed := map[int]bool{}
+	for _, part := range upload.CompletedParts {
+		completed[part.PartNumber] = true
+	}
+	missing := []int{}
+	for partNumber := 1; partNumber <= upload.PartCount; partNumber++ {
+		if !completed[partNumber] {
+			missing = append(missing, partNumber)
+		}
+	}
+	return missing
 }
 
 func (meta *FileMetaData) GetFileName() string {
@@ -52,7 +139,7 @@ func (meta *FileMetaData) GetExtension() string {
 }
 
 func (meta *FileMetaData) GetContentType() string {
-	return ""
+	return meta.ContentType
 }
 
 // privates
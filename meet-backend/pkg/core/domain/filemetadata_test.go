@@ -50,3 +50,36 @@ func TestGetFoldersPath(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestMultipartPartSize(t *testing.T) {
+	const megabyte = 1024 * 1024
+
+	assert.Equal(t, int64(domain.MultipartMinPartSize), domain.MultipartPartSize(100*megabyte))
+
+	// 100GB does not fit in 10000 parts of the minimum size
+	size := int64(100 * 1024 * megabyte)
+	partSize := domain.MultipartPartSize(size)
+	assert.Greater(t, partSize, int64(domain.MultipartMinPartSize))
+	assert.LessOrEqual(t, domain.MultipartPartCount(size, partSize), domain.MultipartMaxPartCount)
+}
+
+func TestMultipartPartCount(t *testing.T) {
+	assert.Equal(t, 1, domain.MultipartPartCount(0, 10))
+	assert.Equal(t, 1, domain.MultipartPartCount(10, 10))
+	assert.Equal(t, 2, domain.MultipartPartCount(11, 10))
+}
+
+func TestMultipartMissingParts(t *testing.T) {
+	upload := domain.FileMultipartUpload{
+		PartCount: 4,
+		CompletedParts: []domain.FileUploadPart{
+			{PartNumber: 1, ETag: "a"},
+			{PartNumber: 3, ETag: "c"},
+		},
+	}
+	assert.Equal(t, []int{2, 4}, upload.MissingParts())
+
+	upload.CompletedParts = append(upload.CompletedParts,
+		domain.FileUploadPart{PartNumber: 2}, domain.FileUploadPart{PartNumber: 4})
+	assert.Empty(t, upload.MissingParts())
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "bytes"
+
+// FileHeadLength is the amount of bytes needed for recognize the type of a file.
+const FileHeadLength = 16
+
+var maxFileSizeByTypeCode = map[string]int64{
+	PackItemTypeCodeImgJpg:   20 * 1024 * 1024,
+	PackItemTypeCodeImgPng:   20 * 1024 * 1024,
+	PackItemTypeCodeVideoMp4: 500 * 1024 * 1024,
+	PackItemTypeCodeVideoOgg: 500 * 1024 * 1024,
+}
+
+// MaxFileSize returns the max size allowed for the type code, zero if the type has no limit.
+func MaxFileSize(typeCode string) int64 {
+	return maxFileSizeByTypeCode[typeCode]
+}
+
+// MatchesTypeCode checks the magic bytes of the file head against the type code.
+func MatchesTypeCode(typeCode string, head []byte) bool {
+	switch typeCode {
+	case PackItemTypeCodeImgJpg:
+		return bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF})
+	case PackItemTypeCodeImgPng:
+		return bytes.HasPrefix(head, []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'})
+	case PackItemTypeCodeVideoMp4:
+		return len(head) >= 8 && bytes.Equal(head[4:8], []byte("ftyp"))
+	case PackItemTypeCodeVideoOgg:
+		return bytes.HasPrefix(head, []byte("OggS"))
+	default:
+		return false
+	}
+}
@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesTypeCode(t *testing.T) {
+	jpg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'}
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n', 0x00}
+	mp4 := []byte{0x00, 0x00, 0x00, 0x20, 'f', 't', 'y', 'p', 'i', 's', 'o', 'm'}
+	ogg := []byte{'O', 'g', 'g', 'S', 0x00, 0x02}
+
+	assert.True(t, domain.MatchesTypeCode(domain.PackItemTypeCodeImgJpg, jpg))
+	assert.True(t, domain.MatchesTypeCode(domain.PackItemTypeCodeImgPng, png))
+	assert.True(t, domain.MatchesTypeCode(domain.PackItemTypeCodeVideoMp4, mp4))
+	assert.True(t, domain.MatchesTypeCode(domain.PackItemTypeCodeVideoOgg, ogg))
+
+	assert.False(t, domain.MatchesTypeCode(domain.PackItemTypeCodeImgJpg, png))
+	assert.False(t, domain.MatchesTypeCode(domain.PackItemTypeCodeImgPng, jpg))
+	assert.False(t, domain.MatchesTypeCode(domain.PackItemTypeCodeVideoMp4, ogg))
+	assert.False(t, domain.MatchesTypeCode(domain.PackItemTypeCodeVideoOgg, mp4))
+	assert.False(t, domain.MatchesTypeCode(domain.PackItemTypeCodeVideoMp4, []byte{0x00}))
+	assert.False(t, domain.MatchesTypeCode("unknown", jpg))
+}
+
+func TestMaxFileSize(t *testing.T) {
+	assert.Greater(t, domain.MaxFileSize(domain.PackItemTypeCodeVideoMp4), domain.MaxFileSize(domain.PackItemTypeCodeImgJpg))
+	assert.Equal(t, int64(0), domain.MaxFileSize("unknown"))
+}
@@ -1,17 +1,55 @@
 package domain
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"slices"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ZodiacSignCodeAries       = "aries"
+	ZodiacSignCodeTaurus      = "taurus"
+	ZodiacSignCodeGemini      = "gemini"
+	ZodiacSignCodeCancer      = "cancer"
+	ZodiacSignCodeLeo         = "leo"
+	ZodiacSignCodeVirgo       = "virgo"
+	ZodiacSignCodeLibra       = "libra"
+	ZodiacSignCodeScorpio     = "scorpio"
+	ZodiacSignCodeSagittarius = "sagittarius"
+	ZodiacSignCodeCapricorn   = "capricorn"
+	ZodiacSignCodeAquarius    = "aquarius"
+	ZodiacSignCodePisces      = "pisces"
+)
+
+var ZodiacSignCodes = []string{
+	ZodiacSignCodeAries, ZodiacSignCodeTaurus, ZodiacSignCodeGemini, ZodiacSignCodeCancer,
+	ZodiacSignCodeLeo, ZodiacSignCodeVirgo, ZodiacSignCodeLibra, ZodiacSignCodeScorpio,
+	ZodiacSignCodeSagittarius, ZodiacSignCodeCapricorn, ZodiacSignCodeAquarius, ZodiacSignCodePisces,
+}
+
+// the fields of the profile are not omitempty for allow unset them when the model edits it
 type Model struct {
 	Id                            *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	PersonId                      primitive.ObjectID  `json:"personId" bson:"personId"`
 	NickName                      string              `json:"nickName" bson:"nickName"`
 	ProfileImageFileHash          *string             `json:"profileImageFileHash,omitempty" bson:"profileImageFileHash,omitempty"`
 	ProfileImageThumbnailFileHash *string             `json:"profileImageThumbnailFileHash,omitempty" bson:"profileImageThumbnailFileHash,omitempty"`
-	AboutMe                       *string             `json:"aboutMe,omitempty" bson:"aboutMe,omitempty"`
-	CountryCode                   *string             `json:"countryCode,omitempty" bson:"countryCode,omitempty"`
-	City                          *string             `json:"city,omitempty" bson:"city,omitempty"`
-	ZodiacSignCode                *string             `json:"zodiacSignCode,omitempty" bson:"ZodiacSignCode,omitempty"`
+	// the new profile image while it is uploaded, it replaces the current one when confirmed
+	PendingProfileImageFileHash          *string `json:"pendingProfileImageFileHash,omitempty" bson:"pendingProfileImageFileHash"`
+	PendingProfileImageThumbnailFileHash *string `json:"pendingProfileImageThumbnailFileHash,omitempty" bson:"pendingProfileImageThumbnailFileHash"`
+	AboutMe                              *string `json:"aboutMe,omitempty" bson:"aboutMe"`
+	CountryCode                          *string `json:"countryCode,omitempty" bson:"countryCode"`
+	City                                 *string `json:"city,omitempty" bson:"city"`
+	ZodiacSignCode                       *string `json:"zodiacSignCode,omitempty" bson:"ZodiacSignCode"`
+	// the nicknames used before, the links with them are still resolved to the model
+	PreviousNickNames   []ModelPreviousNickName `json:"previousNickNames,omitempty" bson:"previousNickNames"`
+	NickNameChangedDate *time.Time              `json:"nickNameChangedDate,omitempty" bson:"nickNameChangedDate"`
+}
+
+type ModelPreviousNickName struct {
+	NickName    string    `json:"nickName" bson:"nickName"`
+	ChangedDate time.Time `json:"changedDate" bson:"changedDate"`
 }
 
 type FilterSearchModel struct {
@@ -25,3 +63,50 @@ type SearchModelResponse struct {
 	TotalCount int     `json:"totalCount"`
 	Models     []Model `json:"models"`
 }
+
+func IsZodiacSignCode(code string) bool {
+	return slices.Contains(ZodiacSignCodes, code)
+}
+
+// NextNickNameChangeDate the date from which the nickname can be changed again, nil when it
+// was never changed
+func (model *Model) NextNickNameChangeDate(cooldown time.Duration) *time.Time {
+	if model.NickNameChangedDate == nil {
+		return nil
+	}
+	nextDate := model.NickNameChangedDate.Add(cooldown)
+	return &nextDate
+}
+
+// ReservesNickName the previous nicknames are reserved to the model for a period after the
+// change, no other model can take them meanwhile
+func (model *Model) ReservesNickName(nickName string, reservation time.Duration, now time.Time) bool {
+	changedDate := model.PreviousNickNameChangedDate(nickName)
+	return changedDate != nil && now.Before(changedDate.Add(reservation))
+}
+
+// PreviousNickNameChangedDate when the model stopped using the nickname, nil when it was not
+// one of its nicknames
+func (model *Model) PreviousNickNameChangedDate(nickName string) *time.Time {
+	for _, previous := range model.PreviousNickNames {
+		if previous.NickName == nickName {
+			changedDate := previous.ChangedDate
+			return &changedDate
+		}
+	}
+	return nil
+}
+
+// ChangeNickName the current nickname goes to the history, a previous nickname taken again
+// leaves it
+func (model *Model) ChangeNickName(newNickName string, now time.Time) {
+	model.PreviousNickNames = slices.DeleteFunc(model.PreviousNickNames, func(previous ModelPreviousNickName) bool {
+		return previous.NickName == newNickName || previous.NickName == model.NickName
+	})
+	model.PreviousNickNames = append(model.PreviousNickNames, ModelPreviousNickName{
+		NickName:    model.NickName,
+		ChangedDate: now,
+	})
+	model.NickName = newNickName
+	model.NickNameChangedDate = &now
+}
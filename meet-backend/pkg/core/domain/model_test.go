@@ -0,0 +1,48 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsZodiacSignCode(t *testing.T) {
+	assert.Len(t, domain.ZodiacSignCodes, 12)
+	assert.True(t, domain.IsZodiacSignCode(domain.ZodiacSignCodeAries))
+	assert.True(t, domain.IsZodiacSignCode("pisces"))
+	assert.False(t, domain.IsZodiacSignCode("Pisces"))
+	assert.False(t, domain.IsZodiacSignCode("ophiuchus"))
+	assert.False(t, domain.IsZodiacSignCode(""))
+}
+
+func TestModelChangeNickName(t *testing.T) {
+	firstChange := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secondChange := firstChange.Add(60 * 24 * time.Hour)
+	model := domain.Model{NickName: "first"}
+
+	assert.Nil(t, model.NextNickNameChangeDate(30*24*time.Hour))
+
+	model.ChangeNickName("second", firstChange)
+	assert.Equal(t, "second", model.NickName)
+	assert.Equal(t, firstChange.Add(30*24*time.Hour), *model.NextNickNameChangeDate(30 * 24 * time.Hour))
+	assert.Equal(t, firstChange, *model.PreviousNickNameChangedDate("first"))
+	assert.Nil(t, model.PreviousNickNameChangedDate("second"))
+
+	// taking again a previous nickname leaves the history
+	model.ChangeNickName("first", secondChange)
+	assert.Equal(t, "first", model.NickName)
+	assert.Equal(t, []domain.ModelPreviousNickName{{NickName: "second", ChangedDate: secondChange}}, model.PreviousNickNames)
+}
+
+func TestModelReservesNickName(t *testing.T) {
+	changedDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	model := domain.Model{NickName: "first"}
+	model.ChangeNickName("second", changedDate)
+	reservation := 180 * 24 * time.Hour
+
+	assert.True(t, model.ReservesNickName("first", reservation, changedDate.Add(reservation-time.Second)))
+	assert.False(t, model.ReservesNickName("first", reservation, changedDate.Add(reservation)))
+	assert.False(t, model.ReservesNickName("other", reservation, changedDate))
+}
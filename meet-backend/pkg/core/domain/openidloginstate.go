@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OpenIdLoginState keeps the secrets of a started openid login until the provider
+// redirects back, it can be consumed only once.
+type OpenIdLoginState struct {
+	Id             *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	StateId        string              `json:"stateId" bson:"stateId"`
+	Provider       string              `json:"provider" bson:"provider"`
+	Nonce          string              `json:"nonce" bson:"nonce"`
+	CodeVerifier   string              `json:"codeVerifier" bson:"codeVerifier"`
+	ExpirationDate time.Time           `json:"expirationDate" bson:"expirationDate"`
+}
@@ -20,6 +20,7 @@ type Pack struct {
 	Title              *string             `json:"title,omitempty" bson:"title,omitempty"`
 	Description        *string             `json:"description,omitempty" bson:"description,omitempty"`
 	DollarValue        *float64            `json:"dollarValue,omitempty" bson:"dollarValue,omitempty"`
+	Prices             map[string]float64  `json:"prices,omitempty" bson:"prices,omitempty"`
 	ReadyToPublish     bool                `json:"readyToPublish" bson:"readyToPublish"`
 	Published          bool                `json:"published" bson:"published"`
 	CreationDate       time.Time           `json:"creationDate" bson:"creationDate"`
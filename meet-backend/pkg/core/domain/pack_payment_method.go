@@ -6,12 +6,12 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// PackPaymentMethod the price of the bank receipts is the CLP price of the pack
 type PackPaymentMethod struct {
 	Id                            *primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	PackId                        primitive.ObjectID  `json:"packId" bson:"packId"`
 	ChiliBankReceiptMethodEnabled bool                `json:"chiliBankReceiptMethodEnabled" bson:"chiliBankReceiptMethodEnabled"`
 	ChiliBankReceiptAccountId     *primitive.ObjectID `json:"chiliBankReceiptAccountId,omitempty" bson:"chiliBankReceiptAccountId,omitempty"`
-	ChiliBankReceiptCLPPrice      *int                `json:"chiliBankReceiptCLPPrice,omitempty" bson:"chiliBankReceiptCLPPrice,omitempty"`
 	PaypalReceiptMethodEnabled    bool                `json:"paypalReceiptMethodEnabled" bson:"paypalReceiptMethodEnabled"`
 	PaypalReceiptRecipientEmail   *string             `json:"paypalReceiptRecipientEmail,omitempty" bson:"paypalReceiptRecipientEmail,omitempty"`
 	PaypalReceiptUSDPrice         *float64            `json:"paypalReceiptUSDPrice,omitempty" bson:"paypalReceiptUSDPrice,omitempty"`
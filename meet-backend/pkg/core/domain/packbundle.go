@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PackBundle struct {
+	Id            *primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	ModelId       primitive.ObjectID   `json:"modelId" bson:"modelId"`
+	BundleNumber  int                  `json:"bundleNumber" bson:"bundleNumber"`
+	Title         *string              `json:"title,omitempty" bson:"title,omitempty"`
+	PacksId       []primitive.ObjectID `json:"packsId" bson:"packsId"`
+	DollarValue   float64              `json:"dollarValue" bson:"dollarValue"`
+	Published     bool                 `json:"published" bson:"published"`
+	CreationDate  time.Time            `json:"creationDate" bson:"creationDate"`
+	PublishedDate *time.Time           `json:"publishedDate,omitempty" bson:"publishedDate,omitempty"`
+	Active        bool                 `json:"active" bson:"active"`
+}
+
+// BundlePriceExcludingOwned returns the bundle price discounting the share of the
+// packs already owned by the buyer. The share is proportional to the individual
+// pack values and the result is never higher than buying the missing packs alone.
+func BundlePriceExcludingOwned(bundleValue float64, packsTotalValue float64, missingPacksValue float64) float64 {
+	if missingPacksValue <= 0 || packsTotalValue <= 0 {
+		return 0
+	}
+	if missingPacksValue >= packsTotalValue {
+		return bundleValue
+	}
+	price := bundleValue * missingPacksValue / packsTotalValue
+	price = math.Round(price*100) / 100
+	if price > missingPacksValue {
+		return missingPacksValue
+	}
+	return price
+}
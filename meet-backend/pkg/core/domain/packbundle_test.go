@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundlePriceNothingOwned(t *testing.T) {
+	actual := domain.BundlePriceExcludingOwned(25, 40, 40)
+
+	assert.Equal(t, 25.0, actual)
+}
+
+func TestBundlePriceSomeOwned(t *testing.T) {
+	actual := domain.BundlePriceExcludingOwned(30, 40, 10)
+
+	assert.Equal(t, 7.5, actual)
+}
+
+func TestBundlePriceAllOwned(t *testing.T) {
+	actual := domain.BundlePriceExcludingOwned(30, 40, 0)
+
+	assert.Equal(t, 0.0, actual)
+}
+
+func TestBundlePriceNeverHigherThanMissingPacks(t *testing.T) {
+	actual := domain.BundlePriceExcludingOwned(50, 40, 10)
+
+	assert.Equal(t, 10.0, actual)
+}
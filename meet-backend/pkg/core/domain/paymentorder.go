@@ -7,14 +7,34 @@ import (
 )
 
 type PaymentOrder struct {
-	Id                 *primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	OrderId            string              `json:"orderId" bson:"orderId"`
-	PersonId           primitive.ObjectID  `json:"personId" bson:"personId"`
-	PackId             primitive.ObjectID  `json:"packId" bson:"packId"`
-	ModelId            primitive.ObjectID  `json:"modelId" bson:"modelId"`
-	PaymentDollarValue float64             `json:"paymentDollarValue" bson:"paymentDollarValue"`
-	CreatedAt          time.Time           `json:"createdAt" bson:"createdAt"`
-	CapturedAt         *time.Time          `json:"capturedAt,omitempty" bson:"capturedAt,omitempty"`
-	ModelPaidAt        *time.Time          `json:"modelPaidAt,omitempty" bson:"modelPaidAt,omitempty"`
-	PaymentDetails     map[string]any      `json:"paymentDetails,omitempty" bson:"paymentDetails,omitempty"`
+	Id       *primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrderId  string              `json:"orderId" bson:"orderId"`
+	PersonId primitive.ObjectID  `json:"personId" bson:"personId"`
+	// the pack of the order, empty for the orders of a bundle
+	PackId             *primitive.ObjectID  `json:"packId,omitempty" bson:"packId,omitempty"`
+	BundleId           *primitive.ObjectID  `json:"bundleId,omitempty" bson:"bundleId,omitempty"`
+	BundlePacksId      []primitive.ObjectID `json:"bundlePacksId,omitempty" bson:"bundlePacksId,omitempty"`
+	ModelId            primitive.ObjectID   `json:"modelId" bson:"modelId"`
+	RecipientEmail     *string              `json:"recipientEmail,omitempty" bson:"recipientEmail,omitempty"`
+	RecipientPersonId  *primitive.ObjectID  `json:"recipientPersonId,omitempty" bson:"recipientPersonId,omitempty"`
+	PaymentDollarValue float64              `json:"paymentDollarValue" bson:"paymentDollarValue"`
+	PaymentValue       float64              `json:"paymentValue" bson:"paymentValue"`
+	CurrencyCode       string               `json:"currencyCode" bson:"currencyCode"`
+	ExchangeRate       float64              `json:"exchangeRate" bson:"exchangeRate"`
+	CreatedAt          time.Time            `json:"createdAt" bson:"createdAt"`
+	CapturedAt         *time.Time           `json:"capturedAt,omitempty" bson:"capturedAt,omitempty"`
+	ModelPaidAt        *time.Time           `json:"modelPaidAt,omitempty" bson:"modelPaidAt,omitempty"`
+	PaymentDetails     map[string]any       `json:"paymentDetails,omitempty" bson:"paymentDetails,omitempty"`
+}
+
+// PacksId the packs given by the order, the pack bought alone or the packs of the bundle
+// that the owner did not have
+func (model *PaymentOrder) PacksId() []primitive.ObjectID {
+	if model.BundleId != nil {
+		return model.BundlePacksId
+	}
+	if model.PackId == nil {
+		return nil
+	}
+	return []primitive.ObjectID{*model.PackId}
 }
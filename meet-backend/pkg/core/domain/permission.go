@@ -6,6 +6,9 @@ const (
 	PermissionCodeCreateRoom     = 3
 )
 
+// SystemPermissionCodes the permissions checked by the code, they can not be deleted
+var SystemPermissionCodes = []int{PermissionCodeManageSystem, PermissionCodeEditOwnProfile, PermissionCodeCreateRoom}
+
 type Permission struct {
 	Code int    `json:"code" bson:"code"`
 	Name string `json:"name" bson:"name"`
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/erodriguezg/meet/pkg/util/datetime"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -13,4 +15,26 @@ type Person struct {
 	LastName    string              `json:"lastName" bson:"lastName"`
 	BirthDay    *datetime.Date      `json:"birthday,omitempty" bson:"birthday,omitempty"`
 	Active      bool                `json:"active" bson:"active"`
+	Pending     bool                `json:"pending" bson:"pending"`
+	// accounts of the openid providers linked to the person
+	Identities []ExternalIdentity `json:"identities,omitempty" bson:"identities,omitempty"`
+	// the access tokens issued before are rejected, set when the person is deactivated
+	// or its profile changes
+	TokensNotBefore *time.Time `json:"-" bson:"tokensNotBefore,omitempty"`
+}
+
+type ExternalIdentity struct {
+	Provider   string    `json:"provider" bson:"provider"`
+	Subject    string    `json:"subject" bson:"subject"`
+	Email      string    `json:"email" bson:"email"`
+	LinkedDate time.Time `json:"linkedDate" bson:"linkedDate"`
+}
+
+func (model *Person) HasExternalIdentity(provider string, subject string) bool {
+	for _, identity := range model.Identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return true
+		}
+	}
+	return false
 }
@@ -1,5 +1,7 @@
 package domain
 
+import "slices"
+
 const (
 	ProfileCodeAdministrator = 1
 	ProfileCodeUser          = 2
@@ -7,8 +9,15 @@ const (
 	ProfileCodeModerator     = 4
 )
 
+// SystemProfileCodes the profiles assigned by the code, they can not be deleted
+var SystemProfileCodes = []int{ProfileCodeAdministrator, ProfileCodeUser, ProfileCodeModel, ProfileCodeModerator}
+
 type Profile struct {
 	Code             int    `json:"code" bson:"code"`
 	Name             string `json:"name" bson:"name"`
 	PermissionsCodes []int  `json:"permissionsCodes" bson:"permissionsCodes"`
 }
+
+func (model *Profile) HasPermission(permissionCode int) bool {
+	return slices.Contains(model.PermissionsCodes, permissionCode)
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// RateLimit a token bucket, it allows bursts of Capacity requests and refills the whole
+// bucket in the Period.
+type RateLimit struct {
+	Capacity int
+	Period   time.Duration
+}
+
+func (model RateLimit) TokensPerSecond() float64 {
+	return float64(model.Capacity) / model.Period.Seconds()
+}
+
+// RetryAfter the time until the bucket has a token again
+func (model RateLimit) RetryAfter(tokens float64) time.Duration {
+	if tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / model.TokensPerSecond() * float64(time.Second))
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ServiceAccount a machine client (internal tools, batch jobs), it authenticates with its
+// api keys and has only the permissions assigned to it.
+type ServiceAccount struct {
+	Id               *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name             string              `json:"name" bson:"name"`
+	Description      string              `json:"description" bson:"description"`
+	PermissionsCodes []int               `json:"permissionsCodes" bson:"permissionsCodes"`
+	Active           bool                `json:"active" bson:"active"`
+	CreationDate     time.Time           `json:"creationDate" bson:"creationDate"`
+}
+
+// ApiKey the key is shown once at its creation, only its prefix (for identify it) and the
+// sha256 of the whole key are stored.
+type ApiKey struct {
+	Id               *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ServiceAccountId primitive.ObjectID  `json:"serviceAccountId" bson:"serviceAccountId"`
+	Name             string              `json:"name" bson:"name"`
+	Prefix           string              `json:"prefix" bson:"prefix"`
+	KeyHash          string              `json:"-" bson:"keyHash"`
+	// the permissions of the service account that the key can use, all of them when empty
+	PermissionsCodes []int      `json:"permissionsCodes" bson:"permissionsCodes"`
+	CreationDate     time.Time  `json:"creationDate" bson:"creationDate"`
+	ExpirationDate   *time.Time `json:"expirationDate,omitempty" bson:"expirationDate,omitempty"`
+	RevokedDate      *time.Time `json:"revokedDate,omitempty" bson:"revokedDate,omitempty"`
+}
+
+func (model *ApiKey) IsUsable(now time.Time) bool {
+	return model.RevokedDate == nil && (model.ExpirationDate == nil || now.Before(*model.ExpirationDate))
+}
+
+// GrantedPermissions the permissions of the key that the service account still has
+func (model *ApiKey) GrantedPermissions(serviceAccount ServiceAccount) []int {
+	if len(model.PermissionsCodes) == 0 {
+		return slices.Clone(serviceAccount.PermissionsCodes)
+	}
+	granted := []int{}
+	for _, permissionCode := range model.PermissionsCodes {
+		if slices.Contains(serviceAccount.PermissionsCodes, permissionCode) {
+			granted = append(granted, permissionCode)
+		}
+	}
+	return granted
+}
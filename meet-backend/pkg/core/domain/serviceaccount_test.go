@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeyGrantedPermissions(t *testing.T) {
+	serviceAccount := domain.ServiceAccount{PermissionsCodes: []int{1, 3}}
+
+	allPermissions := domain.ApiKey{PermissionsCodes: []int{}}
+	assert.Equal(t, []int{1, 3}, allPermissions.GrantedPermissions(serviceAccount))
+
+	// the permission 2 was removed from the service account after the key creation
+	scoped := domain.ApiKey{PermissionsCodes: []int{2, 3}}
+	assert.Equal(t, []int{3}, scoped.GrantedPermissions(serviceAccount))
+}
+
+func TestApiKeyIsUsable(t *testing.T) {
+	now := time.Now()
+	expiration := now.Add(time.Hour)
+	apiKey := domain.ApiKey{ExpirationDate: &expiration}
+
+	assert.True(t, apiKey.IsUsable(now))
+	assert.False(t, apiKey.IsUsable(expiration))
+
+	apiKey.RevokedDate = &now
+	assert.False(t, apiKey.IsUsable(now))
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WatermarkedFile is the copy of a pack resource delivered to one buyer, with a
+// watermark that encodes the person id and the payment order id.
+type WatermarkedFile struct {
+	Id             *primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	SourceFileHash string              `json:"sourceFileHash" bson:"sourceFileHash"`
+	FileHash       string              `json:"fileHash" bson:"fileHash"`
+	PackId         primitive.ObjectID  `json:"packId" bson:"packId"`
+	PersonId       primitive.ObjectID  `json:"personId" bson:"personId"`
+	PaymentOrderId *primitive.ObjectID `json:"paymentOrderId,omitempty" bson:"paymentOrderId,omitempty"`
+	CreationDate   time.Time           `json:"creationDate" bson:"creationDate"`
+}
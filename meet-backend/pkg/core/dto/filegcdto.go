@@ -0,0 +1,16 @@
+package dto
+
+type FileGcReportDto struct {
+	DryRun       bool             `json:"dryRun"`
+	ScannedFiles int              `json:"scannedFiles"`
+	Garbage      []FileGcEntryDto `json:"garbage"`
+	FreedBytes   int64            `json:"freedBytes"`
+	Errors       []string         `json:"errors,omitempty"`
+}
+
+type FileGcEntryDto struct {
+	Hash   string `json:"hash"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Size   int64  `json:"size"`
+}
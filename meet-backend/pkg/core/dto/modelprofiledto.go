@@ -0,0 +1,25 @@
+package dto
+
+import "strings"
+
+// ModelProfileUpdateDto the editable fields of the model profile, the empty fields are
+// removed from the profile. The country is an ISO 3166-1 alpha-2 code (as CL) and the
+// zodiac sign one of domain.ZodiacSignCodes.
+type ModelProfileUpdateDto struct {
+	AboutMe        *string `json:"aboutMe" validate:"omitempty,max=1000"`
+	CountryCode    *string `json:"countryCode" validate:"omitempty,iso3166_1_alpha2"`
+	City           *string `json:"city" validate:"omitempty,max=80"`
+	ZodiacSignCode *string `json:"zodiacSignCode"`
+}
+
+// Normalize uppercases the country code, the validation accepts only the uppercase codes.
+func (port *ModelProfileUpdateDto) Normalize() {
+	if port.CountryCode != nil {
+		countryCode := strings.ToUpper(strings.TrimSpace(*port.CountryCode))
+		port.CountryCode = &countryCode
+	}
+}
+
+type ModelNickNameChangeDto struct {
+	NickName string `json:"nickName" validate:"required,min=3,max=30,alphanum"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+type PackBundleDto struct {
+	BundleNumber int     `json:"bundleNumber"`
+	Title        *string `json:"title,omitempty"`
+	PackNumbers  []int   `json:"packNumbers"`
+	DollarValue  float64 `json:"dollarValue"`
+	Published    bool    `json:"published"`
+}
+
+type SavePackBundleDto struct {
+	Title       *string `json:"title,omitempty" validate:"omitempty,max=30"`
+	PackNumbers []int   `json:"packNumbers" validate:"required,min=2,unique"`
+	DollarValue float64 `json:"dollarValue" validate:"required,gt=0"`
+}
+
+type PackBundleBuyDetailDto struct {
+	ModelNickName           string  `json:"modelNickName"`
+	BundleTitle             *string `json:"bundleTitle,omitempty"`
+	BundleDollarValue       float64 `json:"bundleDollarValue"`
+	PackNumbers             []int   `json:"packNumbers"`
+	AlreadyOwnedPackNumbers []int   `json:"alreadyOwnedPackNumbers"`
+	PaymentDollarValue      float64 `json:"paymentDollarValue"`
+	CurrencyCode            string  `json:"currencyCode"`
+	PaymentValue            float64 `json:"paymentValue"`
+}
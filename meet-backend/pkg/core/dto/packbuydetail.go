@@ -4,4 +4,6 @@ type PackBuyDetailDto struct {
 	ModelNickName   string  `json:"modelNickName"`
 	PackTitle       *string `json:"packTitle,omitempty"`
 	PackDollarValue float64 `json:"packDollarValue"`
+	CurrencyCode    string  `json:"currencyCode"`
+	PackValue       float64 `json:"packValue"`
 }
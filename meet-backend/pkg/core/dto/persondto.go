@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/erodriguezg/meet/pkg/util/datetime"
+
+// PersonSelfUpdateDto the data of the person that the person can change by itself
+type PersonSelfUpdateDto struct {
+	FirstName string         `json:"firstName" validate:"required,max=100"`
+	LastName  string         `json:"lastName" validate:"required,max=100"`
+	BirthDay  *datetime.Date `json:"birthday,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+type ProfileSaveDto struct {
+	Code             int    `json:"code" validate:"required,min=1"`
+	Name             string `json:"name" validate:"required,max=100"`
+	PermissionsCodes []int  `json:"permissionsCodes" validate:"dive,min=1"`
+}
+
+type PermissionSaveDto struct {
+	Code int    `json:"code" validate:"required,min=1"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type PersonProfileAssignDto struct {
+	ProfileCode int `json:"profileCode" validate:"required,min=1"`
+}
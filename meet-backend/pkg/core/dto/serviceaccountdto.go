@@ -0,0 +1,25 @@
+package dto
+
+import "github.com/erodriguezg/meet/pkg/core/domain"
+
+type ServiceAccountSaveDto struct {
+	// empty for create the service account
+	Id               string `json:"id"`
+	Name             string `json:"name" validate:"required,max=100"`
+	Description      string `json:"description" validate:"max=500"`
+	PermissionsCodes []int  `json:"permissionsCodes" validate:"dive,min=1"`
+	Active           bool   `json:"active"`
+}
+
+type ApiKeyCreateDto struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// a subset of the permissions of the service account, all of them when empty
+	PermissionsCodes []int `json:"permissionsCodes" validate:"dive,min=1"`
+	ExpirationDays   int   `json:"expirationDays" validate:"required,min=1,max=730"`
+}
+
+// ApiKeyCreatedDto the key is returned only here, it can not be recovered later
+type ApiKeyCreatedDto struct {
+	ApiKey domain.ApiKey `json:"apiKey"`
+	Key    string        `json:"key"`
+}
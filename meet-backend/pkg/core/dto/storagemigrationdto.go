@@ -0,0 +1,10 @@
+package dto
+
+type StorageMigrationReportDto struct {
+	TargetStorageType string   `json:"targetStorageType"`
+	DryRun            bool     `json:"dryRun"`
+	Batches           int      `json:"batches"`
+	MigratedFiles     int      `json:"migratedFiles"`
+	CopiedBytes       int64    `json:"copiedBytes"`
+	Errors            []string `json:"errors,omitempty"`
+}
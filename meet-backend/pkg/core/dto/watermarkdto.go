@@ -0,0 +1,8 @@
+package dto
+
+type WatermarkExtractDto struct {
+	PersonId       string  `json:"personId"`
+	PersonEmail    *string `json:"personEmail,omitempty"`
+	PaymentOrderId *string `json:"paymentOrderId,omitempty"`
+	OrderId        *string `json:"orderId,omitempty"`
+}
@@ -0,0 +1,19 @@
+package exception
+
+func NewExchangeRateNotFoundException(currencyCode string) error {
+	return newBusinessException("exchange-rate-not-found",
+		"there is no exchange rate for the currency",
+		map[string]string{"currencyCode": currencyCode})
+}
+
+func NewInvalidCurrencyCodeException(currencyCode string) error {
+	return newBusinessException("invalid-currency-code",
+		"the currency code is not a valid ISO 4217 code",
+		map[string]string{"currencyCode": currencyCode})
+}
+
+func NewPaymentCurrencyNotSupportedException(currencyCode string) error {
+	return newBusinessException("payment-currency-not-supported",
+		"the payments can not be done in the currency",
+		map[string]string{"currencyCode": currencyCode})
+}
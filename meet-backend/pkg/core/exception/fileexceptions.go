@@ -0,0 +1,63 @@
+package exception
+
+import "fmt"
+
+func NewFileNotUploadedException(hash string) error {
+	return newBusinessException("file-not-uploaded",
+		"the file was not found in the storage",
+		map[string]string{"hash": hash})
+}
+
+func NewFileTooLargeException(hash string, size int64, maxSize int64) error {
+	return newBusinessException("file-too-large",
+		"the file exceeds the max size allowed for its type",
+		map[string]string{"hash": hash, "size": fmt.Sprint(size), "maxSize": fmt.Sprint(maxSize)})
+}
+
+func NewFileTypeMismatchException(hash string, typeCode string) error {
+	return newBusinessException("file-type-mismatch",
+		"the content of the file does not match the expected type",
+		map[string]string{"hash": hash, "typeCode": typeCode})
+}
+
+func NewImageTooSmallException(hash string, width int, height int, minSize int) error {
+	return newBusinessException("image-too-small",
+		"the image is smaller than the min size allowed",
+		map[string]string{"hash": hash, "width": fmt.Sprint(width), "height": fmt.Sprint(height), "minSize": fmt.Sprint(minSize)})
+}
+
+func NewFileBlobDeletingException(digest string) error {
+	return newBusinessException("file-blob-deleting",
+		"a file with the same content is being deleted, try again later",
+		map[string]string{"digest": digest})
+}
+
+func NewMultipartUploadNotSupportedException(storageType string) error {
+	return newBusinessException("multipart-upload-not-supported",
+		"the storage does not support uploads in parts",
+		map[string]string{"storageType": storageType})
+}
+
+func NewMultipartUploadNotStartedException(hash string) error {
+	return newBusinessException("multipart-upload-not-started",
+		"the upload in parts of the file was not started",
+		map[string]string{"hash": hash})
+}
+
+func NewInvalidMultipartPartException(hash string, partNumber int) error {
+	return newBusinessException("invalid-multipart-part",
+		"the part number is not valid for the upload",
+		map[string]string{"hash": hash, "partNumber": fmt.Sprint(partNumber)})
+}
+
+func NewMultipartUploadIncompleteException(hash string, missingParts []int) error {
+	return newBusinessException("multipart-upload-incomplete",
+		"there are parts of the file not uploaded yet",
+		map[string]string{"hash": hash, "missingParts": fmt.Sprint(missingParts)})
+}
+
+func NewWatermarkNotFoundException() error {
+	return newBusinessException("watermark-not-found",
+		"the image does not contain a watermark",
+		map[string]string{})
+}
@@ -1,6 +1,10 @@
 package exception
 
-import "github.com/erodriguezg/meet/pkg/core/domain"
+import (
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+)
 
 func NewModelAlreadyRegisteredException(model *domain.Model) error {
 	return newBusinessException("model-already-registered",
@@ -13,3 +17,33 @@ func NewModelNickNameNotAvailable(nickName string) error {
 		"the model nickname is not available for register",
 		map[string]string{"nickName": nickName})
 }
+
+func NewModelNotFoundException(nickName string) error {
+	return newBusinessException("model-not-found",
+		"the model does not exist",
+		map[string]string{"nickName": nickName})
+}
+
+func NewModelNotOwnedException(nickName string) error {
+	return newBusinessException("model-not-owned",
+		"the model is not of the person",
+		map[string]string{"nickName": nickName})
+}
+
+func NewInvalidZodiacSignException(zodiacSignCode string) error {
+	return newBusinessException("invalid-zodiac-sign",
+		"the zodiac sign code is not valid",
+		map[string]string{"zodiacSignCode": zodiacSignCode})
+}
+
+func NewModelProfileImageNotPendingException(nickName string) error {
+	return newBusinessException("model-profile-image-not-pending",
+		"there is not a profile image upload to confirm",
+		map[string]string{"nickName": nickName})
+}
+
+func NewModelNickNameChangeCooldownException(nickName string, nextChangeDate time.Time) error {
+	return newBusinessException("model-nickname-change-cooldown",
+		"the model nickname was changed recently",
+		map[string]string{"nickName": nickName, "nextChangeDate": nextChangeDate.Format(time.RFC3339)})
+}
@@ -0,0 +1,13 @@
+package exception
+
+func NewPackAlreadyOwnedException(email string, packId string) error {
+	return newBusinessException("pack-already-owned",
+		"the person already owns the pack",
+		map[string]string{"email": email, "packId": packId})
+}
+
+func NewInvalidGiftRecipientException(email string) error {
+	return newBusinessException("invalid-gift-recipient",
+		"the gift recipient email is not valid",
+		map[string]string{"email": email})
+}
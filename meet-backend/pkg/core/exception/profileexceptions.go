@@ -0,0 +1,31 @@
+package exception
+
+import "fmt"
+
+func NewLastAdministratorException() error {
+	return newBusinessException("last-administrator", "the change would leave the system without an active administrator", map[string]string{})
+}
+
+func NewProfileNotFoundException(code int) error {
+	return newBusinessException("profile-not-found", "the profile does not exist", map[string]string{"code": fmt.Sprint(code)})
+}
+
+func NewProfileInUseException(code int) error {
+	return newBusinessException("profile-in-use", "the profile is assigned to persons", map[string]string{"code": fmt.Sprint(code)})
+}
+
+func NewSystemProfileException(code int) error {
+	return newBusinessException("system-profile", "the profile is used by the system and can not be deleted", map[string]string{"code": fmt.Sprint(code)})
+}
+
+func NewPermissionNotFoundException(code int) error {
+	return newBusinessException("permission-not-found", "the permission does not exist", map[string]string{"code": fmt.Sprint(code)})
+}
+
+func NewPermissionInUseException(code int) error {
+	return newBusinessException("permission-in-use", "the permission is assigned to profiles", map[string]string{"code": fmt.Sprint(code)})
+}
+
+func NewSystemPermissionException(code int) error {
+	return newBusinessException("system-permission", "the permission is used by the system and can not be deleted", map[string]string{"code": fmt.Sprint(code)})
+}
@@ -0,0 +1,15 @@
+package exception
+
+func NewLoginProviderNotFoundException(provider string) error {
+	return newBusinessException("login-provider-not-found", "the login provider is not configured", map[string]string{"provider": provider})
+}
+
+func NewUnverifiedEmailLinkException(provider string, email string) error {
+	return newBusinessException("unverified-email-link",
+		"the login provider does not verify the email, it can not be linked to the existing person",
+		map[string]string{"provider": provider, "email": email})
+}
+
+func NewInvalidRefreshTokenException() error {
+	return newBusinessException("invalid-refresh-token", "the refresh token is invalid, expired or was already used", map[string]string{})
+}
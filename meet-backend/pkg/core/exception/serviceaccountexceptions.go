@@ -0,0 +1,19 @@
+package exception
+
+import "fmt"
+
+func NewServiceAccountNotFoundException(id string) error {
+	return newBusinessException("service-account-not-found", "the service account does not exist", map[string]string{"id": id})
+}
+
+func NewServiceAccountNameNotAvailableException(name string) error {
+	return newBusinessException("service-account-name-not-available", "other service account has the name", map[string]string{"name": name})
+}
+
+func NewApiKeyNotFoundException(id string) error {
+	return newBusinessException("api-key-not-found", "the api key does not exist or was already revoked", map[string]string{"id": id})
+}
+
+func NewApiKeyPermissionNotAllowedException(code int) error {
+	return newBusinessException("api-key-permission-not-allowed", "the service account does not have the permission", map[string]string{"code": fmt.Sprint(code)})
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/erodriguezg/meet/pkg/core/domain"
+
+// AuditEntryRepository the audit log is append only, there are no updates nor deletes.
+type AuditEntryRepository interface {
+	Save(entry domain.AuditEntry) error
+
+	SearchCount(filters domain.AuditFilter) (int, error)
+
+	// Search the entries of the filter, the newest first
+	Search(filters domain.AuditFilter, first int, last int) ([]domain.AuditEntry, error)
+}
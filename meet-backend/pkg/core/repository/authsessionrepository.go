@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RefreshTokenRepository interface {
+	Save(refreshToken domain.RefreshToken) error
+
+	FindByTokenHash(tokenHash string) (*domain.RefreshToken, error)
+
+	// MarkUsed marks the token as used only if it was not used or revoked before,
+	// false when other request used it first.
+	MarkUsed(id primitive.ObjectID) (bool, error)
+
+	RevokeFamily(familyId string) error
+}
+
+type RevokedAccessTokenRepository interface {
+	Save(revokedAccessToken domain.RevokedAccessToken) error
+
+	ExistsByJti(jti string) (bool, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/erodriguezg/meet/pkg/core/domain"
+
+type ExchangeRateRepository interface {
+	FindAll() ([]domain.ExchangeRate, error)
+
+	FindByCurrencyCode(currencyCode string) (*domain.ExchangeRate, error)
+
+	Save(rate domain.ExchangeRate) (*domain.ExchangeRate, error)
+
+	DeleteByCurrencyCode(currencyCode string) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type FileBlobRepository interface {
+	// AcquireBlob adds a reference to the blob of the digest in the storage, creating it
+	// with the path and size of the parameter when does not exist. A blob with tombstone is
+	// returned as is, it is being deleted and can't be used.
+	AcquireBlob(blob domain.FileBlob) (*domain.FileBlob, error)
+
+	// ReleaseBlob removes a reference, returns nil when the blob does not exist.
+	ReleaseBlob(digest string, storageType string) (*domain.FileBlob, error)
+
+	// MarkDeleting sets the tombstone of the blob when it has no references, false when it
+	// was acquired again meanwhile.
+	MarkDeleting(id primitive.ObjectID) (bool, error)
+
+	Delete(id primitive.ObjectID) error
+}
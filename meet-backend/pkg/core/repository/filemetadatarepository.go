@@ -8,7 +8,23 @@ import (
 type FileMetaDataRepository interface {
 	FindByHash(hash string) (*domain.FileMetaData, error)
 
+	FindAll() ([]domain.FileMetaData, error)
+
+	// FindUploadedNotInStorage returns the uploaded files of other storages ordered by id,
+	// starting after the id (nil for the first page). The files without storage type are
+	// included only with includeLegacy.
+	FindUploadedNotInStorage(storageType string, includeLegacy bool, afterId *primitive.ObjectID, limit int) ([]domain.FileMetaData, error)
+
+	// UpdateStorage saves the storage type and path of the files and clears the cached
+	// download urls, all in one batch
+	UpdateStorage(files []domain.FileMetaData) error
+
 	Save(fileMetaData domain.FileMetaData) (*domain.FileMetaData, error)
 
+	// SaveMultipartPart adds the part to the completed parts of the upload, replacing the part
+	// with the same number, in one atomic update so the parts can be registered in parallel.
+	// False when the file does not have that upload anymore.
+	SaveMultipartPart(hash string, uploadId string, part domain.FileUploadPart) (bool, error)
+
 	Delete(id primitive.ObjectID) error
 }
@@ -8,4 +8,9 @@ type ModelRepository interface {
 	SaveModel(domain.Model) (*domain.Model, error)
 	FindModelByPersonId(personId string) (*domain.Model, error)
 	FindModelByNickName(nickName string) (*domain.Model, error)
+	// FindModelsByPreviousNickName the models that used the nickname before
+	FindModelsByPreviousNickName(nickName string) ([]domain.Model, error)
+	FindModelById(modelId string) (*domain.Model, error)
+	FindModelByProfileImageFileHash(fileHash string) (*domain.Model, error)
+	FindAllModels() ([]domain.Model, error)
 }
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/erodriguezg/meet/pkg/core/domain"
+
+type OpenIdLoginStateRepository interface {
+	Save(loginState domain.OpenIdLoginState) error
+
+	// Consume deletes and returns the login state not expired, nil when it does not exist,
+	// expired or was already consumed.
+	Consume(stateId string) (*domain.OpenIdLoginState, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/erodriguezg/meet/pkg/core/domain"
+
+type PackBundleRepository interface {
+	FindBundleById(bundleId string) (*domain.PackBundle, error)
+
+	FindBundleActiveByModelIdAndBundleNumber(modelId string, bundleNumber int) (*domain.PackBundle, error)
+
+	FindBundlesActiveByModelId(modelId string) ([]domain.PackBundle, error)
+
+	SaveBundle(bundle domain.PackBundle) (*domain.PackBundle, error)
+}
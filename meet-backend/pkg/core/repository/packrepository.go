@@ -11,5 +11,10 @@ type PackRepository interface {
 
 	FindPacksActiveByModelId(modelId string) ([]domain.Pack, error)
 
+	FindPackByItemFileHash(fileHash string) (*domain.Pack, error)
+
+	// FindAllPacks includes the inactive packs
+	FindAllPacks() ([]domain.Pack, error)
+
 	SavePack(pack domain.Pack) (*domain.Pack, error)
 }
@@ -3,6 +3,9 @@ package repository
 type PaymentClientRepository interface {
 	GetClientData() (map[string]any, error)
 
+	// SupportsCurrency the orders can be created only in the currencies supported by the provider
+	SupportsCurrency(currencyCode string) bool
+
 	CreateOrder(value float64, currencyCode string) (string, error)
 
 	CapturePayment(orderID string) (map[string]any, error)
@@ -7,4 +7,8 @@ import (
 type PaymentOrderRepository interface {
 	SavePaymentOrder(paymentOrder *domain.PaymentOrder) (*domain.PaymentOrder, error)
 	FindByOrderId(orderId string) (*domain.PaymentOrder, error)
+	FindById(paymentOrderId string) (*domain.PaymentOrder, error)
+	// FindCapturedByOwnerAndPackId search the captured order that gave the pack to the person,
+	// as buyer or as gift recipient, directly or in a bundle
+	FindCapturedByOwnerAndPackId(personId string, packId string) (*domain.PaymentOrder, error)
 }
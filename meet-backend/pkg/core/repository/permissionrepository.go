@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"github.com/erodriguezg/meet/pkg/core/domain"
+)
+
+type PermissionRepository interface {
+	FindByCode(code int) (*domain.Permission, error)
+	FindAll() ([]domain.Permission, error)
+	Save(permission domain.Permission) (*domain.Permission, error)
+	Delete(code int) error
+}
@@ -9,8 +9,12 @@ type PersonRepository interface {
 
 	FindByEmail(email string) (*domain.Person, error)
 
+	FindByExternalIdentity(provider string, subject string) (*domain.Person, error)
+
 	FindAll() ([]domain.Person, error)
 
+	CountByProfileCodes(profileCodes []int, onlyActive bool) (int64, error)
+
 	FilterPaginated(filters domain.PersonFilter) ([]domain.Person, error)
 
 	Persist(person domain.Person) (*domain.Person, error)
@@ -7,4 +7,7 @@ import (
 type ProfileRepository interface {
 	FindByCode(code int) (*domain.Profile, error)
 	FindAll() ([]domain.Profile, error)
+	FindByPermissionCode(permissionCode int) ([]domain.Profile, error)
+	Save(profile domain.Profile) (*domain.Profile, error)
+	Delete(code int) error
 }
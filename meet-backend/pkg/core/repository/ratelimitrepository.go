@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+)
+
+type RateLimitRepository interface {
+	// Take consumes a token of the bucket of the key, when the bucket is empty it returns
+	// false and the time until the next token.
+	Take(key string, limit domain.RateLimit) (bool, time.Duration, error)
+}
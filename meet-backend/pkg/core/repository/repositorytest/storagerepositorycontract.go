@@ -0,0 +1,228 @@
+// Package repositorytest contains the contract tests shared by the implementations
+// of the core repositories.
+package repositorytest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunStorageRepositoryContract checks the behavior every StorageRepository must have:
+// the upload url accepts the file content and the download urls return it back.
+func RunStorageRepositoryContract(t *testing.T, storage repository.StorageRepository, httpClient *http.Client) {
+	content := []byte(fmt.Sprintf("storage contract content %d", time.Now().UnixNano()))
+	metaData := domain.FileMetaData{
+		Hash: fmt.Sprintf("contract-%d", time.Now().UnixNano()),
+		Path: fmt.Sprintf("contract-test/%d/file.txt", time.Now().UnixNano()),
+	}
+
+	t.Run("storage type", func(t *testing.T) {
+		assert.NotEmpty(t, storage.GetStorageType())
+	})
+
+	t.Run("stat before upload", func(t *testing.T) {
+		stat, err := storage.StatFile(metaData)
+		require.NoError(t, err)
+		assert.Nil(t, stat)
+	})
+
+	t.Run("upload with the upload url", func(t *testing.T) {
+		uploadUrl, err := storage.GetFileUploadUrl(metaData)
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(uploadMethod(storage), uploadUrl, bytes.NewReader(content))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/octet-stream")
+
+		response, err := httpClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.True(t, isSuccess(response.StatusCode), "upload status code: %d", response.StatusCode)
+	})
+
+	t.Run("stat after upload", func(t *testing.T) {
+		stat, err := storage.StatFile(metaData)
+		require.NoError(t, err)
+		require.NotNil(t, stat)
+		assert.Equal(t, int64(len(content)), stat.Size)
+		assert.NotEmpty(t, stat.Checksum)
+	})
+
+	t.Run("read the file head", func(t *testing.T) {
+		head, err := storage.ReadFileHead(metaData, 8)
+		require.NoError(t, err)
+		assert.Equal(t, content[:8], head)
+	})
+
+	t.Run("download with the download url", func(t *testing.T) {
+		downloadUrl, err := storage.GetFileDownloadUrl(metaData)
+		require.NoError(t, err)
+		assert.Equal(t, content, mustDownload(t, httpClient, downloadUrl))
+	})
+
+	t.Run("download with the temporary download url", func(t *testing.T) {
+		downloadUrl, err := storage.GetFileTemporaryDownloadUrl(metaData, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, content, mustDownload(t, httpClient, downloadUrl))
+	})
+
+	t.Run("the upload url is not valid for download", func(t *testing.T) {
+		uploadUrl, err := storage.GetFileUploadUrl(metaData)
+		require.NoError(t, err)
+
+		response, err := httpClient.Get(uploadUrl)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		assert.False(t, isSuccess(response.StatusCode) && bytes.Equal(content, readAll(t, response)))
+	})
+
+	t.Run("put and read a generated file", func(t *testing.T) {
+		generated := append([]byte("generated "), content...)
+		require.NoError(t, storage.PutFile(metaData, generated, "text/plain"))
+
+		reader, err := storage.ReadFile(metaData)
+		require.NoError(t, err)
+		defer reader.Close()
+		readContent, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, generated, readContent)
+	})
+
+	t.Run("put and read a generated file from a reader", func(t *testing.T) {
+		streamMetaData := domain.FileMetaData{
+			Hash: metaData.Hash + "-stream",
+			Path: fmt.Sprintf("contract-test/%d/stream.txt", time.Now().UnixNano()),
+		}
+		generated := append([]byte("streamed "), content...)
+		require.NoError(t, storage.PutFileStream(streamMetaData, bytes.NewReader(generated), "text/plain"))
+
+		reader, err := storage.ReadFile(streamMetaData)
+		require.NoError(t, err)
+		defer reader.Close()
+		readContent, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, generated, readContent)
+		require.NoError(t, storage.DeleteFile(streamMetaData))
+	})
+
+	t.Run("copy a file", func(t *testing.T) {
+		copyMetaData := domain.FileMetaData{
+			Hash: metaData.Hash + "-copy",
+			Path: fmt.Sprintf("contract-test/%d/copy.txt", time.Now().UnixNano()),
+		}
+		require.NoError(t, storage.CopyFile(metaData, copyMetaData))
+
+		reader, err := storage.ReadFile(copyMetaData)
+		require.NoError(t, err)
+		defer reader.Close()
+		copiedContent, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, append([]byte("generated "), content...), copiedContent)
+
+		require.NoError(t, storage.DeleteFile(copyMetaData))
+	})
+
+	t.Run("multipart upload", func(t *testing.T) {
+		multipartMetaData := domain.FileMetaData{
+			Hash: metaData.Hash + "-multipart",
+			Path: fmt.Sprintf("contract-test/%d/multipart.txt", time.Now().UnixNano()),
+		}
+
+		uploadId, err := storage.InitiateMultipartUpload(multipartMetaData)
+		if errors.Is(err, repository.ErrMultipartUploadNotSupported) {
+			t.Skip("the storage does not support multipart uploads")
+		}
+		require.NoError(t, err)
+
+		// only the last part can be smaller than the minimum part size of the storages
+		partUrl, err := storage.GetMultipartUploadPartUrl(multipartMetaData, uploadId, 1)
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, partUrl, bytes.NewReader(content))
+		require.NoError(t, err)
+		response, err := httpClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.True(t, isSuccess(response.StatusCode), "upload part status code: %d", response.StatusCode)
+		etag := response.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+
+		err = storage.CompleteMultipartUpload(multipartMetaData, uploadId,
+			[]domain.FileUploadPart{{PartNumber: 1, ETag: etag}})
+		require.NoError(t, err)
+
+		reader, err := storage.ReadFile(multipartMetaData)
+		require.NoError(t, err)
+		defer reader.Close()
+		readContent, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, content, readContent)
+
+		require.NoError(t, storage.DeleteFile(multipartMetaData))
+	})
+
+	t.Run("abort a multipart upload", func(t *testing.T) {
+		uploadId, err := storage.InitiateMultipartUpload(metaData)
+		if errors.Is(err, repository.ErrMultipartUploadNotSupported) {
+			t.Skip("the storage does not support multipart uploads")
+		}
+		require.NoError(t, err)
+		assert.NoError(t, storage.AbortMultipartUpload(metaData, uploadId))
+	})
+
+	t.Run("delete the file", func(t *testing.T) {
+		require.NoError(t, storage.DeleteFile(metaData))
+
+		stat, err := storage.StatFile(metaData)
+		require.NoError(t, err)
+		assert.Nil(t, stat)
+
+		downloadUrl, err := storage.GetFileTemporaryDownloadUrl(metaData, time.Minute)
+		if err != nil {
+			// the storage can refuse generate urls for missing files
+			return
+		}
+		response, err := httpClient.Get(downloadUrl)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		assert.False(t, isSuccess(response.StatusCode), "download after delete status code: %d", response.StatusCode)
+	})
+}
+
+// private
+
+// uploadMethod the dropbox temporary upload links only accept POST, the presigned urls use PUT.
+func uploadMethod(storage repository.StorageRepository) string {
+	if storage.GetStorageType() == "DROPBOX" {
+		return http.MethodPost
+	}
+	return http.MethodPut
+}
+
+func mustDownload(t *testing.T, httpClient *http.Client, downloadUrl string) []byte {
+	response, err := httpClient.Get(downloadUrl)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.True(t, isSuccess(response.StatusCode), "download status code: %d", response.StatusCode)
+	return readAll(t, response)
+}
+
+func readAll(t *testing.T, response *http.Response) []byte {
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	return body
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ServiceAccountRepository interface {
+	FindById(id string) (*domain.ServiceAccount, error)
+
+	FindByName(name string) (*domain.ServiceAccount, error)
+
+	FindAll() ([]domain.ServiceAccount, error)
+
+	Save(serviceAccount domain.ServiceAccount) (*domain.ServiceAccount, error)
+
+	Delete(id primitive.ObjectID) error
+}
+
+type ApiKeyRepository interface {
+	FindByPrefix(prefix string) (*domain.ApiKey, error)
+
+	FindByServiceAccountId(serviceAccountId primitive.ObjectID) ([]domain.ApiKey, error)
+
+	Save(apiKey domain.ApiKey) (*domain.ApiKey, error)
+
+	// Revoke the key of the service account, false when it does not exist or was already revoked
+	Revoke(serviceAccountId primitive.ObjectID, id primitive.ObjectID) (bool, error)
+
+	RevokeByServiceAccountId(serviceAccountId primitive.ObjectID) error
+}
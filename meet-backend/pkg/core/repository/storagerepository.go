@@ -1,6 +1,14 @@
 package repository
 
-import "github.com/erodriguezg/meet/pkg/core/domain"
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+)
+
+var ErrMultipartUploadNotSupported = errors.New("the storage does not support multipart uploads")
 
 type StorageRepository interface {
 	GetStorageType() string
@@ -9,5 +17,35 @@ type StorageRepository interface {
 
 	GetFileDownloadUrl(metaData domain.FileMetaData) (string, error)
 
+	GetFileTemporaryDownloadUrl(metaData domain.FileMetaData, expiration time.Duration) (string, error)
+
 	DeleteFile(metaData domain.FileMetaData) error
+
+	// StatFile returns nil when the file does not exist in the storage
+	StatFile(metaData domain.FileMetaData) (*domain.FileStat, error)
+
+	ReadFileHead(metaData domain.FileMetaData, length int) ([]byte, error)
+
+	ReadFile(metaData domain.FileMetaData) (io.ReadCloser, error)
+
+	// PutFile stores a file generated by the backend, replacing it if already exists
+	PutFile(metaData domain.FileMetaData, content []byte, contentType string) error
+
+	// PutFileStream stores the content read until EOF, without keeping it all in memory
+	PutFileStream(metaData domain.FileMetaData, reader io.Reader, contentType string) error
+
+	// CopyFile copies the file inside the storage without download it
+	CopyFile(source domain.FileMetaData, target domain.FileMetaData) error
+
+	// InitiateMultipartUpload starts an upload in parts and returns its id. Storages without
+	// support return ErrMultipartUploadNotSupported.
+	InitiateMultipartUpload(metaData domain.FileMetaData) (string, error)
+
+	// GetMultipartUploadPartUrl returns the url for PUT one part, the ETag header of the
+	// response identifies the uploaded part.
+	GetMultipartUploadPartUrl(metaData domain.FileMetaData, uploadId string, partNumber int) (string, error)
+
+	CompleteMultipartUpload(metaData domain.FileMetaData, uploadId string, parts []domain.FileUploadPart) error
+
+	AbortMultipartUpload(metaData domain.FileMetaData, uploadId string) error
 }
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/erodriguezg/meet/pkg/core/domain"
+
+type WatermarkedFileRepository interface {
+	FindBySourceFileHashAndPersonId(sourceFileHash string, personId string) (*domain.WatermarkedFile, error)
+
+	Save(watermarkedFile domain.WatermarkedFile) (*domain.WatermarkedFile, error)
+
+	DeleteByFileHash(fileHash string) error
+}
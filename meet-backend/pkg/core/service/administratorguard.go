@@ -0,0 +1,79 @@
+package service
+
+import (
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+)
+
+// administratorGuard avoids the changes of persons and profiles that leave the system
+// without an active person with the manage system permission.
+type administratorGuard struct {
+	profileRepository repository.ProfileRepository
+	personRepository  repository.PersonRepository
+}
+
+// mustKeepAdministrator checks the change of a person, after is nil when the person is deleted.
+func (port *administratorGuard) mustKeepAdministrator(before domain.Person, after *domain.Person) error {
+	wasAdministrator, err := port.isAdministrator(before)
+	if err != nil || !wasAdministrator {
+		return err
+	}
+	if after != nil {
+		isAdministrator, err := port.isAdministrator(*after)
+		if err != nil || isAdministrator {
+			return err
+		}
+	}
+
+	count, err := port.countActiveAdministrators(0)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return exception.NewLastAdministratorException()
+	}
+	return nil
+}
+
+// mustKeepAdministratorWithoutProfile checks the removal of the manage system permission of a profile.
+func (port *administratorGuard) mustKeepAdministratorWithoutProfile(profileCode int) error {
+	count, err := port.countActiveAdministrators(profileCode)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return exception.NewLastAdministratorException()
+	}
+	return nil
+}
+
+func (port *administratorGuard) isAdministrator(person domain.Person) (bool, error) {
+	if !person.Active {
+		return false, nil
+	}
+	profile, err := port.profileRepository.FindByCode(person.ProfileCode)
+	if err != nil || profile == nil {
+		return false, err
+	}
+	return profile.HasPermission(domain.PermissionCodeManageSystem), nil
+}
+
+// countActiveAdministrators of the profiles with the manage system permission, without the
+// excluded profile (0 for count all).
+func (port *administratorGuard) countActiveAdministrators(excludedProfileCode int) (int64, error) {
+	profiles, err := port.profileRepository.FindByPermissionCode(domain.PermissionCodeManageSystem)
+	if err != nil {
+		return 0, err
+	}
+	var profileCodes []int
+	for _, profile := range profiles {
+		if profile.Code != excludedProfileCode {
+			profileCodes = append(profileCodes, profile.Code)
+		}
+	}
+	if len(profileCodes) == 0 {
+		return 0, nil
+	}
+	return port.personRepository.CountByProfileCodes(profileCodes, true)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.uber.org/zap"
+)
+
+// AuditExportMaxEntries the entries of an export, the filters must be narrowed for more
+const AuditExportMaxEntries = 10000
+
+type AuditService interface {
+	// Record appends the entry of the action done, before and after are the target (nil when
+	// it is created or deleted) and only the changed fields are recorded. The action is
+	// already done, a failure is logged and does not fail it.
+	Record(actor domain.AuditActor, action string, targetType string, targetId string, before any, after any)
+
+	Search(filters domain.AuditFilter, first int, last int) (*domain.AuditSearchResponse, error)
+
+	// FindForExport the newest entries of the filter, up to AuditExportMaxEntries
+	FindForExport(filters domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+type domainAuditService struct {
+	auditEntryRepository repository.AuditEntryRepository
+	log                  *zap.Logger
+}
+
+func NewDomainAuditService(auditEntryRepository repository.AuditEntryRepository, log *zap.Logger) AuditService {
+	return &domainAuditService{auditEntryRepository, log}
+}
+
+func (port *domainAuditService) Record(actor domain.AuditActor, action string, targetType string, targetId string, before any, after any) {
+	entry := domain.AuditEntry{
+		Date:       time.Now(),
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+	}
+	changes, err := domain.NewAuditChanges(before, after)
+	if err != nil {
+		port.log.Error("error at calculating the audit changes", zap.Any("entry", entry), zap.Error(err))
+		changes = []domain.AuditChange{}
+	}
+	entry.Changes = changes
+
+	err = port.auditEntryRepository.Save(entry)
+	if err != nil {
+		port.log.Error("error at saving the audit entry", zap.Any("entry", entry), zap.Error(err))
+	}
+}
+
+func (port *domainAuditService) Search(filters domain.AuditFilter, first int, last int) (*domain.AuditSearchResponse, error) {
+	totalCount, err := port.auditEntryRepository.SearchCount(filters)
+	if err != nil {
+		return nil, err
+	}
+	entries := []domain.AuditEntry{}
+	if totalCount > 0 && last > first {
+		entries, err = port.auditEntryRepository.Search(filters, first, last)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &domain.AuditSearchResponse{TotalCount: totalCount, Entries: entries}, nil
+}
+
+func (port *domainAuditService) FindForExport(filters domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return port.auditEntryRepository.Search(filters, 0, AuditExportMaxEntries)
+}
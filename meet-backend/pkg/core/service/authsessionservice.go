@@ -0,0 +1,171 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	RefreshTokenDuration = 30 * 24 * time.Hour
+	refreshTokenBytes    = 32
+)
+
+type AuthSessionService interface {
+	// CreateRefreshToken starts a new token family for the person after a login
+	CreateRefreshToken(person domain.Person) (string, error)
+
+	// RotateRefreshToken uses the refresh token and returns its person with the new refresh
+	// token of the family. A token used twice revokes the whole family.
+	RotateRefreshToken(refreshToken string) (*domain.Person, string, error)
+
+	RevokeRefreshToken(refreshToken string) error
+
+	RevokeAccessToken(jti string, expirationDate time.Time) error
+
+	IsAccessTokenRevoked(jti string) (bool, error)
+}
+
+type domainAuthSessionService struct {
+	personRepository             repository.PersonRepository
+	refreshTokenRepository       repository.RefreshTokenRepository
+	revokedAccessTokenRepository repository.RevokedAccessTokenRepository
+	log                          *zap.Logger
+}
+
+func NewDomainAuthSessionService(
+	personRepository repository.PersonRepository,
+	refreshTokenRepository repository.RefreshTokenRepository,
+	revokedAccessTokenRepository repository.RevokedAccessTokenRepository,
+	log *zap.Logger,
+) AuthSessionService {
+	return &domainAuthSessionService{personRepository, refreshTokenRepository, revokedAccessTokenRepository, log}
+}
+
+func (port *domainAuthSessionService) CreateRefreshToken(person domain.Person) (string, error) {
+	familyId, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	return port.saveRefreshToken(domain.RefreshToken{
+		FamilyId: familyId,
+		PersonId: *person.Id,
+		AuthDate: now,
+	}, now)
+}
+
+func (port *domainAuthSessionService) RotateRefreshToken(refreshToken string) (*domain.Person, string, error) {
+	token, err := port.refreshTokenRepository.FindByTokenHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, "", err
+	}
+	if token == nil {
+		return nil, "", exception.NewInvalidRefreshTokenException()
+	}
+
+	now := time.Now()
+	if token.UsedDate != nil || token.RevokedDate != nil {
+		port.log.Warn("refresh token reused, revoking the family",
+			zap.String("personId", token.PersonId.Hex()), zap.String("familyId", token.FamilyId))
+		return nil, "", port.revokeFamily(token.FamilyId)
+	}
+	if !token.IsUsable(now) {
+		return nil, "", exception.NewInvalidRefreshTokenException()
+	}
+
+	marked, err := port.refreshTokenRepository.MarkUsed(*token.Id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !marked {
+		// other request used it at the same time
+		port.log.Warn("refresh token used concurrently, revoking the family",
+			zap.String("personId", token.PersonId.Hex()), zap.String("familyId", token.FamilyId))
+		return nil, "", port.revokeFamily(token.FamilyId)
+	}
+
+	person, err := port.personRepository.FindById(token.PersonId.Hex())
+	if err != nil {
+		return nil, "", err
+	}
+	if person == nil || !person.Active {
+		return nil, "", port.revokeFamily(token.FamilyId)
+	}
+
+	newRefreshToken, err := port.saveRefreshToken(domain.RefreshToken{
+		FamilyId: token.FamilyId,
+		PersonId: token.PersonId,
+		AuthDate: token.AuthDate,
+	}, now)
+	if err != nil {
+		return nil, "", err
+	}
+	return person, newRefreshToken, nil
+}
+
+func (port *domainAuthSessionService) RevokeRefreshToken(refreshToken string) error {
+	token, err := port.refreshTokenRepository.FindByTokenHash(hashToken(refreshToken))
+	if err != nil || token == nil {
+		return err
+	}
+	return port.refreshTokenRepository.RevokeFamily(token.FamilyId)
+}
+
+func (port *domainAuthSessionService) RevokeAccessToken(jti string, expirationDate time.Time) error {
+	return port.revokedAccessTokenRepository.Save(domain.RevokedAccessToken{
+		Jti:            jti,
+		ExpirationDate: expirationDate,
+	})
+}
+
+func (port *domainAuthSessionService) IsAccessTokenRevoked(jti string) (bool, error) {
+	return port.revokedAccessTokenRepository.ExistsByJti(jti)
+}
+
+// private
+
+func (port *domainAuthSessionService) saveRefreshToken(token domain.RefreshToken, now time.Time) (string, error) {
+	refreshToken, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	token.TokenHash = hashToken(refreshToken)
+	token.CreationDate = now
+	token.ExpirationDate = now.Add(RefreshTokenDuration)
+	err = port.refreshTokenRepository.Save(token)
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+func (port *domainAuthSessionService) revokeFamily(familyId string) error {
+	err := port.refreshTokenRepository.RevokeFamily(familyId)
+	if err != nil {
+		return err
+	}
+	return exception.NewInvalidRefreshTokenException()
+}
+
+// hashToken only the hash is stored, a leak of the collection does not give usable tokens
+func hashToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+func randomToken() (string, error) {
+	value := make([]byte, refreshTokenBytes)
+	_, err := rand.Read(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
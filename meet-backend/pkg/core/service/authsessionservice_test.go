@@ -0,0 +1,132 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestAuthSessionService(persons ...domain.Person) (AuthSessionService, *fakePersonRepository, *fakeRefreshTokenRepository) {
+	personRepository := newFakePersonRepository(persons...)
+	refreshTokenRepository := newFakeRefreshTokenRepository()
+	authSessionService := NewDomainAuthSessionService(personRepository, refreshTokenRepository,
+		&fakeRevokedAccessTokenRepository{}, zap.NewNop())
+	return authSessionService, personRepository, refreshTokenRepository
+}
+
+func mustFindTestPerson(t *testing.T, personRepository *fakePersonRepository, email string) domain.Person {
+	person, err := personRepository.FindByEmail(email)
+	require.NoError(t, err)
+	require.NotNil(t, person)
+	return *person
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	authSessionService, personRepository, refreshTokenRepository := newTestAuthSessionService(
+		domain.Person{Email: "user@meet.com", Active: true})
+	person := mustFindTestPerson(t, personRepository, "user@meet.com")
+
+	refreshToken, err := authSessionService.CreateRefreshToken(person)
+	require.NoError(t, err)
+
+	rotatedPerson, rotatedToken, err := authSessionService.RotateRefreshToken(refreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, person.Id, rotatedPerson.Id)
+	assert.NotEqual(t, refreshToken, rotatedToken)
+
+	// only the hash is stored, in the same family
+	stored, _ := refreshTokenRepository.FindByTokenHash(hashToken(rotatedToken))
+	original, _ := refreshTokenRepository.FindByTokenHash(hashToken(refreshToken))
+	require.NotNil(t, stored)
+	assert.Equal(t, original.FamilyId, stored.FamilyId)
+	assert.NotNil(t, original.UsedDate)
+
+	_, _, err = authSessionService.RotateRefreshToken(rotatedToken)
+	assert.NoError(t, err)
+}
+
+func TestRotateRefreshTokenReusedRevokesFamily(t *testing.T) {
+	authSessionService, personRepository, _ := newTestAuthSessionService(
+		domain.Person{Email: "user@meet.com", Active: true})
+	person := mustFindTestPerson(t, personRepository, "user@meet.com")
+
+	refreshToken, err := authSessionService.CreateRefreshToken(person)
+	require.NoError(t, err)
+	_, rotatedToken, err := authSessionService.RotateRefreshToken(refreshToken)
+	require.NoError(t, err)
+
+	// the stolen token is used after the legit rotation
+	_, _, err = authSessionService.RotateRefreshToken(refreshToken)
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	// the whole family is revoked, also the last token
+	_, _, err = authSessionService.RotateRefreshToken(rotatedToken)
+	assert.IsType(t, &exception.BusinessException{}, err)
+}
+
+func TestRotateRefreshTokenOtherFamilyKeepsWorking(t *testing.T) {
+	authSessionService, personRepository, _ := newTestAuthSessionService(
+		domain.Person{Email: "user@meet.com", Active: true})
+	person := mustFindTestPerson(t, personRepository, "user@meet.com")
+
+	refreshToken, _ := authSessionService.CreateRefreshToken(person)
+	otherDeviceToken, _ := authSessionService.CreateRefreshToken(person)
+
+	err := authSessionService.RevokeRefreshToken(refreshToken)
+	require.NoError(t, err)
+
+	_, _, err = authSessionService.RotateRefreshToken(refreshToken)
+	assert.IsType(t, &exception.BusinessException{}, err)
+	_, _, err = authSessionService.RotateRefreshToken(otherDeviceToken)
+	assert.NoError(t, err)
+}
+
+func TestRotateRefreshTokenInvalid(t *testing.T) {
+	authSessionService, personRepository, refreshTokenRepository := newTestAuthSessionService(
+		domain.Person{Email: "user@meet.com", Active: true})
+	person := mustFindTestPerson(t, personRepository, "user@meet.com")
+
+	_, _, err := authSessionService.RotateRefreshToken("unknown")
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	refreshToken, _ := authSessionService.CreateRefreshToken(person)
+	stored, _ := refreshTokenRepository.FindByTokenHash(hashToken(refreshToken))
+	stored.ExpirationDate = time.Now().Add(-time.Minute)
+	_ = refreshTokenRepository.Save(*stored)
+
+	_, _, err = authSessionService.RotateRefreshToken(refreshToken)
+	assert.IsType(t, &exception.BusinessException{}, err)
+}
+
+func TestRotateRefreshTokenInactivePersonRevokesFamily(t *testing.T) {
+	authSessionService, personRepository, refreshTokenRepository := newTestAuthSessionService(
+		domain.Person{Email: "user@meet.com", Active: true})
+	person := mustFindTestPerson(t, personRepository, "user@meet.com")
+
+	refreshToken, _ := authSessionService.CreateRefreshToken(person)
+	person.Active = false
+	_, _ = personRepository.Update(person)
+
+	_, _, err := authSessionService.RotateRefreshToken(refreshToken)
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	stored, _ := refreshTokenRepository.FindByTokenHash(hashToken(refreshToken))
+	assert.NotNil(t, stored.RevokedDate)
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	authSessionService, _, _ := newTestAuthSessionService()
+
+	err := authSessionService.RevokeAccessToken("jti-1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	revoked, _ := authSessionService.IsAccessTokenRevoked("jti-1")
+	assert.True(t, revoked)
+	revoked, _ = authSessionService.IsAccessTokenRevoked("jti-2")
+	assert.False(t, revoked)
+}
@@ -2,10 +2,13 @@ package service
 
 import (
 	"fmt"
+	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
 	"github.com/erodriguezg/meet/pkg/core/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -13,35 +16,49 @@ import (
 type BuyPackService interface {
 	GetPaymentClientData() (map[string]any, error)
 
-	GetPackBuyDetails(modelNickName string, packNumber int) (*dto.PackBuyDetailDto, error)
+	GetPackBuyDetails(modelNickName string, packNumber int, currencyCode string) (*dto.PackBuyDetailDto, error)
 
-	CreateBuyPackOrder(buyerPersonId string, modelNickName string, packNumber int) (string, error)
+	CreateBuyPackOrder(buyerPersonId string, modelNickName string, packNumber int, recipientEmail *string, currencyCode string) (string, error)
 
-	CapturePackPayment(orderID string) error
+	GetBundleBuyDetails(buyerPersonId string, modelNickName string, bundleNumber int, recipientEmail *string, currencyCode string) (*dto.PackBundleBuyDetailDto, error)
+
+	CreateBuyBundleOrder(buyerPersonId string, modelNickName string, bundleNumber int, recipientEmail *string, currencyCode string) (string, error)
+
+	// CapturePackPayment records the capture in the audit log with the actor
+	CapturePackPayment(orderID string, actor domain.AuditActor) error
 }
 
 type domainBuyPackService struct {
 	modelService           ModelService
 	personService          PersonService
 	packService            PackService
+	packBundleService      PackBundleService
 	ownedResourceService   OwnedResourceService
+	currencyService        CurrencyService
 	paymentClient          repository.PaymentClientRepository
 	paymentOrderRepository repository.PaymentOrderRepository
+	auditService           AuditService
 }
 
 func NewDomainBuyPackService(personService PersonService,
 	modelService ModelService,
 	packService PackService,
+	packBundleService PackBundleService,
 	ownedResourceService OwnedResourceService,
+	currencyService CurrencyService,
 	paymentClient repository.PaymentClientRepository,
-	paymentOrderRepository repository.PaymentOrderRepository) BuyPackService {
+	paymentOrderRepository repository.PaymentOrderRepository,
+	auditService AuditService) BuyPackService {
 	return &domainBuyPackService{
 		modelService,
 		personService,
 		packService,
+		packBundleService,
 		ownedResourceService,
+		currencyService,
 		paymentClient,
 		paymentOrderRepository,
+		auditService,
 	}
 }
 
@@ -49,7 +66,7 @@ func (port *domainBuyPackService) GetPaymentClientData() (map[string]any, error)
 	return port.paymentClient.GetClientData()
 }
 
-func (port *domainBuyPackService) GetPackBuyDetails(modelNickName string, packNumber int) (*dto.PackBuyDetailDto, error) {
+func (port *domainBuyPackService) GetPackBuyDetails(modelNickName string, packNumber int, currencyCode string) (*dto.PackBuyDetailDto, error) {
 	model, err := port.modelService.FindModelByNickName(modelNickName)
 	if err != nil {
 		return nil, err
@@ -61,19 +78,26 @@ func (port *domainBuyPackService) GetPackBuyDetails(modelNickName string, packNu
 	if err != nil {
 		return nil, err
 	}
-	if pack.DollarValue == nil {
-		return nil, fmt.Errorf("the pack does not have dollar value")
+	if pack == nil {
+		return nil, fmt.Errorf("pack not found")
+	}
+
+	price, err := port.currencyService.PriceForPack(pack, currencyCode)
+	if err != nil {
+		return nil, err
 	}
 
 	dto := dto.PackBuyDetailDto{
 		ModelNickName:   modelNickName,
 		PackTitle:       pack.Title,
-		PackDollarValue: *pack.DollarValue,
+		PackDollarValue: price.DollarValue,
+		CurrencyCode:    price.CurrencyCode,
+		PackValue:       price.Value,
 	}
 	return &dto, nil
 }
 
-func (port *domainBuyPackService) CreateBuyPackOrder(personId string, modelNickName string, packNumber int) (string, error) {
+func (port *domainBuyPackService) CreateBuyPackOrder(personId string, modelNickName string, packNumber int, recipientEmail *string, currencyCode string) (string, error) {
 
 	person, err := port.personService.FindById(personId)
 	if err != nil {
@@ -102,29 +126,37 @@ func (port *domainBuyPackService) CreateBuyPackOrder(personId string, modelNickN
 		return "", fmt.Errorf("pack is not published yet")
 	}
 
-	personHasPack, err := port.ownedResourceService.PersonHasPack(personId, pack.Id.Hex())
+	giftEmail, err := port.normalizeRecipientEmail(person, recipientEmail)
 	if err != nil {
 		return "", err
 	}
-	if personHasPack {
-		return "", fmt.Errorf("the person id %s already has pack id %s", personId, pack.Id.Hex())
-	}
 
-	packDollarValue := pack.DollarValue
-	if packDollarValue == nil {
-		return "", fmt.Errorf("the pack does not have value")
+	if giftEmail == nil {
+		personHasPack, err := port.ownedResourceService.PersonHasPack(personId, pack.Id.Hex())
+		if err != nil {
+			return "", err
+		}
+		if personHasPack {
+			return "", fmt.Errorf("the person id %s already has pack id %s", personId, pack.Id.Hex())
+		}
+	} else {
+		err = port.mustNotOwnPacks(*giftEmail, []primitive.ObjectID{*pack.Id})
+		if err != nil {
+			return "", err
+		}
 	}
 
-	orderId, err := port.paymentClient.CreateOrder(*packDollarValue, "USD")
+	price, err := port.currencyService.PriceForPack(pack, currencyCode)
 	if err != nil {
 		return "", err
 	}
 
-	personObjectId, err := primitive.ObjectIDFromHex(personId)
+	orderId, err := port.createPaymentClientOrder(price)
 	if err != nil {
 		return "", err
 	}
-	packObjectId, err := primitive.ObjectIDFromHex(pack.Id.Hex())
+
+	personObjectId, err := primitive.ObjectIDFromHex(personId)
 	if err != nil {
 		return "", err
 	}
@@ -132,9 +164,13 @@ func (port *domainBuyPackService) CreateBuyPackOrder(personId string, modelNickN
 	paymentOrder := domain.PaymentOrder{
 		OrderId:            orderId,
 		PersonId:           personObjectId,
-		PackId:             packObjectId,
+		PackId:             pack.Id,
 		ModelId:            pack.ModelId,
-		PaymentDollarValue: *pack.DollarValue,
+		RecipientEmail:     giftEmail,
+		PaymentDollarValue: price.DollarValue,
+		PaymentValue:       price.Value,
+		CurrencyCode:       price.CurrencyCode,
+		ExchangeRate:       price.UnitsPerUSD,
 		CreatedAt:          time.Now(),
 	}
 
@@ -146,7 +182,101 @@ func (port *domainBuyPackService) CreateBuyPackOrder(personId string, modelNickN
 	return paymentOrderSaved.OrderId, nil
 }
 
-func (port *domainBuyPackService) CapturePackPayment(orderID string) error {
+func (port *domainBuyPackService) GetBundleBuyDetails(buyerPersonId string, modelNickName string, bundleNumber int, recipientEmail *string, currencyCode string) (*dto.PackBundleBuyDetailDto, error) {
+	buyer, err := port.personService.FindById(buyerPersonId)
+	if err != nil {
+		return nil, err
+	}
+	if buyer == nil {
+		return nil, fmt.Errorf("person id %s not found for GetBundleBuyDetails", buyerPersonId)
+	}
+
+	giftEmail, err := port.normalizeRecipientEmail(buyer, recipientEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	_, bundle, bundleQuote, err := port.quoteBundle(buyer, modelNickName, bundleNumber, giftEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := port.currencyService.PriceFromUSD(bundleQuote.price, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PackBundleBuyDetailDto{
+		ModelNickName:           modelNickName,
+		BundleTitle:             bundle.Title,
+		BundleDollarValue:       bundle.DollarValue,
+		PackNumbers:             bundleQuote.missingPackNumbers,
+		AlreadyOwnedPackNumbers: bundleQuote.ownedPackNumbers,
+		PaymentDollarValue:      price.DollarValue,
+		CurrencyCode:            price.CurrencyCode,
+		PaymentValue:            price.Value,
+	}, nil
+}
+
+func (port *domainBuyPackService) CreateBuyBundleOrder(buyerPersonId string, modelNickName string, bundleNumber int, recipientEmail *string, currencyCode string) (string, error) {
+	buyer, err := port.personService.FindById(buyerPersonId)
+	if err != nil {
+		return "", err
+	}
+	if buyer == nil {
+		return "", fmt.Errorf("person id %s not found for CreateBuyBundleOrder", buyerPersonId)
+	}
+
+	giftEmail, err := port.normalizeRecipientEmail(buyer, recipientEmail)
+	if err != nil {
+		return "", err
+	}
+
+	model, bundle, bundleQuote, err := port.quoteBundle(buyer, modelNickName, bundleNumber, giftEmail)
+	if err != nil {
+		return "", err
+	}
+	if len(bundleQuote.missingPacksId) == 0 {
+		ownerEmail := buyer.Email
+		if giftEmail != nil {
+			ownerEmail = *giftEmail
+		}
+		return "", exception.NewPackAlreadyOwnedException(ownerEmail, bundle.Id.Hex())
+	}
+
+	price, err := port.currencyService.PriceFromUSD(bundleQuote.price, currencyCode)
+	if err != nil {
+		return "", err
+	}
+
+	orderId, err := port.createPaymentClientOrder(price)
+	if err != nil {
+		return "", err
+	}
+
+	paymentOrder := domain.PaymentOrder{
+		OrderId:            orderId,
+		PersonId:           *buyer.Id,
+		BundleId:           bundle.Id,
+		BundlePacksId:      bundleQuote.missingPacksId,
+		ModelId:            *model.Id,
+		RecipientEmail:     giftEmail,
+		PaymentDollarValue: price.DollarValue,
+		PaymentValue:       price.Value,
+		CurrencyCode:       price.CurrencyCode,
+		ExchangeRate:       price.UnitsPerUSD,
+		CreatedAt:          time.Now(),
+	}
+
+	paymentOrderSaved, err := port.paymentOrderRepository.SavePaymentOrder(&paymentOrder)
+	if err != nil {
+		return "", err
+	}
+
+	return paymentOrderSaved.OrderId, nil
+}
+
+func (port *domainBuyPackService) CapturePackPayment(orderID string, actor domain.AuditActor) error {
 
 	paymentOrder, err := port.paymentOrderRepository.FindByOrderId(orderID)
 	if err != nil {
@@ -159,10 +289,32 @@ func (port *domainBuyPackService) CapturePackPayment(orderID string) error {
 		return fmt.Errorf("the payment order id: %s was already capture", orderID)
 	}
 
+	// the owner could get some of the packs by other order after this one was created, the
+	// payment is not captured for not charge them twice
+	ownerEmail, err := port.orderOwnerEmail(paymentOrder)
+	if err != nil {
+		return err
+	}
+	err = port.mustNotOwnPacks(ownerEmail, paymentOrder.PacksId())
+	if err != nil {
+		return err
+	}
+
 	paymentDetails, err := port.paymentClient.CapturePayment(orderID)
 	if err != nil {
 		return err
 	}
+	previousPaymentOrder := *paymentOrder
+
+	ownerPersonId := paymentOrder.PersonId
+	if paymentOrder.RecipientEmail != nil {
+		recipient, err := port.findOrCreateRecipient(*paymentOrder.RecipientEmail, actor)
+		if err != nil {
+			return err
+		}
+		ownerPersonId = *recipient.Id
+		paymentOrder.RecipientPersonId = recipient.Id
+	}
 
 	paymentOrder.PaymentDetails = paymentDetails
 	presentTime := time.Now()
@@ -172,11 +324,182 @@ func (port *domainBuyPackService) CapturePackPayment(orderID string) error {
 	if err != nil {
 		return err
 	}
+	port.auditService.Record(actor, domain.AuditActionPaymentCapture, domain.AuditTargetPaymentOrder,
+		orderID, &previousPaymentOrder, paymentOrder)
+
+	for _, packId := range paymentOrder.PacksId() {
+		err = port.ownedResourceService.AddPackToPerson(ownerPersonId.Hex(), packId.Hex())
+		if err != nil {
+			return err
+		}
+	}
 
-	err = port.ownedResourceService.AddPackToPerson(paymentOrder.PersonId.Hex(), paymentOrder.PackId.Hex())
+	return nil
+}
+
+// private
+
+type bundleQuote struct {
+	price              float64
+	missingPacksId     []primitive.ObjectID
+	missingPackNumbers []int
+	ownedPackNumbers   []int
+}
+
+// quoteBundle prices the bundle for the person that will own the packs, the buyer
+// or the gift recipient, discounting the packs that person already owns.
+func (port *domainBuyPackService) quoteBundle(buyer *domain.Person, modelNickName string, bundleNumber int, giftEmail *string) (*domain.Model, *domain.PackBundle, *bundleQuote, error) {
+	model, err := port.modelService.FindModelByNickName(modelNickName)
 	if err != nil {
-		return err
+		return nil, nil, nil, err
+	}
+	if model == nil {
+		return nil, nil, nil, fmt.Errorf("model %s not found for quoteBundle", modelNickName)
+	}
+
+	bundle, err := port.packBundleService.FindActiveBundleByModelIdAndBundleNumber(model.Id.Hex(), bundleNumber)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if bundle == nil {
+		return nil, nil, nil, fmt.Errorf("bundle not found for quoteBundle")
+	}
+	if !bundle.Published {
+		return nil, nil, nil, fmt.Errorf("bundle is not published yet")
+	}
+
+	packs, err := port.packBundleService.GetBundlePacks(bundle)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	owner := buyer
+	if giftEmail != nil {
+		owner, err = port.personService.FindByEmail(*giftEmail)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	quote := bundleQuote{
+		missingPacksId:     []primitive.ObjectID{},
+		missingPackNumbers: []int{},
+		ownedPackNumbers:   []int{},
+	}
+	var packsTotalValue float64
+	var missingPacksValue float64
+	for _, pack := range packs {
+		if !pack.Published {
+			return nil, nil, nil, fmt.Errorf("the pack number %d of the bundle is not published", pack.PackNumber)
+		}
+		if pack.DollarValue == nil {
+			return nil, nil, nil, fmt.Errorf("the pack number %d of the bundle does not have value", pack.PackNumber)
+		}
+		packsTotalValue += *pack.DollarValue
+
+		ownerHasPack := false
+		if owner != nil {
+			ownerHasPack, err = port.ownedResourceService.PersonHasPack(owner.Id.Hex(), pack.Id.Hex())
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		if ownerHasPack {
+			quote.ownedPackNumbers = append(quote.ownedPackNumbers, pack.PackNumber)
+		} else {
+			quote.missingPacksId = append(quote.missingPacksId, *pack.Id)
+			quote.missingPackNumbers = append(quote.missingPackNumbers, pack.PackNumber)
+			missingPacksValue += *pack.DollarValue
+		}
 	}
 
+	quote.price = domain.BundlePriceExcludingOwned(bundle.DollarValue, packsTotalValue, missingPacksValue)
+	return model, bundle, &quote, nil
+}
+
+// createPaymentClientOrder the payments are done only in the currencies supported by the payment
+// client, the prices in the other currencies are only shown
+func (port *domainBuyPackService) createPaymentClientOrder(price CurrencyPrice) (string, error) {
+	if !port.paymentClient.SupportsCurrency(price.CurrencyCode) {
+		return "", exception.NewPaymentCurrencyNotSupportedException(price.CurrencyCode)
+	}
+	return port.paymentClient.CreateOrder(price.Value, price.CurrencyCode)
+}
+
+// normalizeRecipientEmail returns nil when the order is not a gift, this is
+// when no recipient was given or the recipient is the buyer itself.
+func (port *domainBuyPackService) normalizeRecipientEmail(buyer *domain.Person, recipientEmail *string) (*string, error) {
+	if recipientEmail == nil {
+		return nil, nil
+	}
+	email := strings.ToLower(strings.TrimSpace(*recipientEmail))
+	if email == "" {
+		return nil, nil
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return nil, exception.NewInvalidGiftRecipientException(*recipientEmail)
+	}
+	if strings.EqualFold(buyer.Email, email) {
+		return nil, nil
+	}
+	return &email, nil
+}
+
+// mustNotOwnPacks the person of the email, when exists, must not have any of the packs
+func (port *domainBuyPackService) mustNotOwnPacks(email string, packsId []primitive.ObjectID) error {
+	owner, err := port.personService.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return nil
+	}
+	for _, packId := range packsId {
+		ownerHasPack, err := port.ownedResourceService.PersonHasPack(owner.Id.Hex(), packId.Hex())
+		if err != nil {
+			return err
+		}
+		if ownerHasPack {
+			return exception.NewPackAlreadyOwnedException(email, packId.Hex())
+		}
+	}
 	return nil
 }
+
+// orderOwnerEmail the email of the person that will own the packs, the gift recipient or the buyer
+func (port *domainBuyPackService) orderOwnerEmail(paymentOrder *domain.PaymentOrder) (string, error) {
+	if paymentOrder.RecipientEmail != nil {
+		return *paymentOrder.RecipientEmail, nil
+	}
+	buyer, err := port.personService.FindById(paymentOrder.PersonId.Hex())
+	if err != nil {
+		return "", err
+	}
+	if buyer == nil {
+		return "", fmt.Errorf("the buyer person id %s of the payment order %s not found", paymentOrder.PersonId.Hex(), paymentOrder.OrderId)
+	}
+	return buyer.Email, nil
+}
+
+func (port *domainBuyPackService) findOrCreateRecipient(email string, actor domain.AuditActor) (*domain.Person, error) {
+	recipient, err := port.personService.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if recipient != nil {
+		return recipient, nil
+	}
+
+	// the recipient will claim this person on the first openid login
+	pendingPerson := domain.Person{
+		Email:       email,
+		ProfileCode: domain.ProfileCodeUser,
+		Pending:     true,
+	}
+	recipient, err = port.personService.Save(pendingPerson, actor)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pending gift recipient %s: %w", email, err)
+	}
+	return recipient, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type testBuyPack struct {
+	service          BuyPackService
+	personRepository *fakePersonRepository
+	ownedResource    OwnedResourceService
+	paymentClient    *fakePaymentClient
+	paymentOrders    *fakePaymentOrderRepository
+	buyer            domain.Person
+	currencyService  CurrencyService
+	packs            []domain.Pack
+	bundle           domain.PackBundle
+}
+
+// newTestBuyPack a model with two published packs of 10 dollars and a bundle of both for 15
+func newTestBuyPack(persons ...domain.Person) *testBuyPack {
+	buyer := domain.Person{Email: "buyer@meet.com", ProfileCode: domain.ProfileCodeUser, Active: true}
+	personRepository := newFakePersonRepository(append(persons, buyer)...)
+	storedBuyer, _ := personRepository.FindByEmail(buyer.Email)
+
+	auditService := &fakeAuditService{}
+	personService := NewDomainPersonService(personRepository, newFakeProfileRepository(),
+		ttlcache.New[string, domain.Person](time.Minute, 10), auditService)
+
+	modelId := primitive.NewObjectID()
+	model := domain.Model{Id: &modelId, PersonId: primitive.NewObjectID(), NickName: "model"}
+
+	dollarValue := 10.0
+	var packs []domain.Pack
+	var packsId []primitive.ObjectID
+	for packNumber := 1; packNumber <= 2; packNumber++ {
+		packId := primitive.NewObjectID()
+		packs = append(packs, domain.Pack{Id: &packId, ModelId: modelId, PackNumber: packNumber,
+			DollarValue: &dollarValue, Published: true, Active: true})
+		packsId = append(packsId, packId)
+	}
+	bundleId := primitive.NewObjectID()
+	bundle := domain.PackBundle{Id: &bundleId, ModelId: modelId, BundleNumber: 1, PacksId: packsId,
+		DollarValue: 15, Published: true, Active: true}
+
+	ownedResource := NewDomainOwnedResourceService(newFakeOwnedResourceRepository())
+	currencyService := NewDomainCurrencyService(newFakeExchangeRateRepository())
+	paymentClient := &fakePaymentClient{}
+	paymentOrders := newFakePaymentOrderRepository()
+
+	service := NewDomainBuyPackService(personService,
+		&fakeModelService{models: []domain.Model{model}},
+		&fakePackService{packs: packs},
+		&fakePackBundleService{bundles: []domain.PackBundle{bundle}, packs: packs},
+		ownedResource, currencyService, paymentClient, paymentOrders, auditService)
+
+	return &testBuyPack{service, personRepository, ownedResource, paymentClient, paymentOrders, *storedBuyer,
+		currencyService, packs, bundle}
+}
+
+func TestGiftPackToUnknownEmailCreatesPendingPerson(t *testing.T) {
+	test := newTestBuyPack()
+	recipientEmail := " Friend@Meet.com "
+
+	orderId, err := test.service.CreateBuyPackOrder(test.buyer.Id.Hex(), "model", 1, &recipientEmail, "")
+	require.NoError(t, err)
+	order, _ := test.paymentOrders.FindByOrderId(orderId)
+	require.NotNil(t, order.RecipientEmail)
+	assert.Equal(t, "friend@meet.com", *order.RecipientEmail)
+
+	// the recipient does not exist until the payment is captured
+	recipient, _ := test.personRepository.FindByEmail("friend@meet.com")
+	assert.Nil(t, recipient)
+
+	err = test.service.CapturePackPayment(orderId, domain.AuditActor{})
+	require.NoError(t, err)
+
+	recipient, _ = test.personRepository.FindByEmail("friend@meet.com")
+	require.NotNil(t, recipient)
+	assert.True(t, recipient.Pending)
+	assert.Equal(t, domain.ProfileCodeUser, recipient.ProfileCode)
+
+	recipientHasPack, _ := test.ownedResource.PersonHasPack(recipient.Id.Hex(), test.packs[0].Id.Hex())
+	assert.True(t, recipientHasPack)
+	buyerHasPack, _ := test.ownedResource.PersonHasPack(test.buyer.Id.Hex(), test.packs[0].Id.Hex())
+	assert.False(t, buyerHasPack)
+
+	order, _ = test.paymentOrders.FindByOrderId(orderId)
+	assert.Equal(t, recipient.Id, order.RecipientPersonId)
+	assert.NotNil(t, order.CapturedAt)
+}
+
+func TestGiftPackToOwnEmailIsNotAGift(t *testing.T) {
+	test := newTestBuyPack()
+	recipientEmail := "BUYER@meet.com"
+
+	orderId, err := test.service.CreateBuyPackOrder(test.buyer.Id.Hex(), "model", 1, &recipientEmail, "")
+	require.NoError(t, err)
+	order, _ := test.paymentOrders.FindByOrderId(orderId)
+	assert.Nil(t, order.RecipientEmail)
+}
+
+func TestGiftPackToInvalidEmailIsRejected(t *testing.T) {
+	test := newTestBuyPack()
+	recipientEmail := "friend at meet.com"
+
+	_, err := test.service.CreateBuyPackOrder(test.buyer.Id.Hex(), "model", 1, &recipientEmail, "")
+	assert.IsType(t, &exception.BusinessException{}, err)
+	assert.Empty(t, test.paymentClient.createdOrders)
+}
+
+func TestGiftPackToOwnerIsRejected(t *testing.T) {
+	test := newTestBuyPack(domain.Person{Email: "friend@meet.com", ProfileCode: domain.ProfileCodeUser, Active: true})
+	recipient, _ := test.personRepository.FindByEmail("friend@meet.com")
+	require.NoError(t, test.ownedResource.AddPackToPerson(recipient.Id.Hex(), test.packs[0].Id.Hex()))
+	recipientEmail := "friend@meet.com"
+
+	_, err := test.service.CreateBuyPackOrder(test.buyer.Id.Hex(), "model", 1, &recipientEmail, "")
+	assert.IsType(t, &exception.BusinessException{}, err)
+	assert.Empty(t, test.paymentClient.createdOrders)
+}
+
+func TestGiftPackOwnedBeforeCaptureIsRejected(t *testing.T) {
+	test := newTestBuyPack(domain.Person{Email: "friend@meet.com", ProfileCode: domain.ProfileCodeUser, Active: true})
+	recipient, _ := test.personRepository.FindByEmail("friend@meet.com")
+	recipientEmail := "friend@meet.com"
+
+	orderId, err := test.service.CreateBuyPackOrder(test.buyer.Id.Hex(), "model", 1, &recipientEmail, "")
+	require.NoError(t, err)
+
+	// the recipient gets the pack between the order and the capture
+	require.NoError(t, test.ownedResource.AddPackToPerson(recipient.Id.Hex(), test.packs[0].Id.Hex()))
+
+	err = test.service.CapturePackPayment(orderId, domain.AuditActor{})
+	assert.IsType(t, &exception.BusinessException{}, err)
+	assert.Empty(t, test.paymentClient.capturedOrders)
+}
+
+func TestCaptureBundleGrantsMissingPacks(t *testing.T) {
+	test := newTestBuyPack()
+	require.NoError(t, test.ownedResource.AddPackToPerson(test.buyer.Id.Hex(), test.packs[0].Id.Hex()))
+
+	orderId, err := test.service.CreateBuyBundleOrder(test.buyer.Id.Hex(), "model", 1, nil, "")
+	require.NoError(t, err)
+	order, _ := test.paymentOrders.FindByOrderId(orderId)
+	assert.Nil(t, order.PackId)
+	assert.Equal(t, []primitive.ObjectID{*test.packs[1].Id}, order.PacksId())
+	// the share of the owned pack is discounted
+	assert.Equal(t, 7.5, test.paymentClient.createdOrders[0].value)
+
+	err = test.service.CapturePackPayment(orderId, domain.AuditActor{})
+	require.NoError(t, err)
+
+	buyerHasPack, _ := test.ownedResource.PersonHasPack(test.buyer.Id.Hex(), test.packs[1].Id.Hex())
+	assert.True(t, buyerHasPack)
+	captured, _ := test.paymentOrders.FindCapturedByOwnerAndPackId(test.buyer.Id.Hex(), test.packs[1].Id.Hex())
+	require.NotNil(t, captured)
+	assert.Equal(t, orderId, captured.OrderId)
+}
+
+func TestCaptureBundleRejectsPackBoughtMeanwhile(t *testing.T) {
+	test := newTestBuyPack()
+
+	bundleOrderId, err := test.service.CreateBuyBundleOrder(test.buyer.Id.Hex(), "model", 1, nil, "")
+	require.NoError(t, err)
+
+	// the buyer gets one of the packs of the bundle before paying it
+	packOrderId, err := test.service.CreateBuyPackOrder(test.buyer.Id.Hex(), "model", 2, nil, "")
+	require.NoError(t, err)
+	require.NoError(t, test.service.CapturePackPayment(packOrderId, domain.AuditActor{}))
+
+	err = test.service.CapturePackPayment(bundleOrderId, domain.AuditActor{})
+	assert.IsType(t, &exception.BusinessException{}, err)
+	assert.Equal(t, []string{packOrderId}, test.paymentClient.capturedOrders)
+
+	bundleOrder, _ := test.paymentOrders.FindByOrderId(bundleOrderId)
+	assert.Nil(t, bundleOrder.CapturedAt)
+	buyerHasPack, _ := test.ownedResource.PersonHasPack(test.buyer.Id.Hex(), test.packs[0].Id.Hex())
+	assert.False(t, buyerHasPack)
+}
+
+func TestBuyPackInCurrencyNotSupportedByThePaymentClient(t *testing.T) {
+	test := newTestBuyPack()
+	_, err := test.currencyService.SaveRate(domain.CurrencyCodeCLP, 945.37)
+	require.NoError(t, err)
+
+	// the price is shown in CLP but it can not be charged
+	details, err := test.service.GetPackBuyDetails("model", 1, "clp")
+	require.NoError(t, err)
+	assert.Equal(t, 9454.0, details.PackValue)
+
+	_, err = test.service.CreateBuyPackOrder(test.buyer.Id.Hex(), "model", 1, nil, "clp")
+	assert.IsType(t, &exception.BusinessException{}, err)
+	assert.Empty(t, test.paymentClient.createdOrders)
+}
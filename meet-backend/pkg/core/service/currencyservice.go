@@ -0,0 +1,222 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+)
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// CurrencyPrice is a value expressed in one currency together with the rate used
+// for get it and the same value normalized to dollars.
+type CurrencyPrice struct {
+	CurrencyCode string
+	Value        float64
+	DollarValue  float64
+	UnitsPerUSD  float64
+}
+
+type CurrencyService interface {
+	FindAllRates() ([]domain.ExchangeRate, error)
+
+	SaveRate(currencyCode string, unitsPerUSD float64) (*domain.ExchangeRate, error)
+
+	DeleteRate(currencyCode string) error
+
+	ImportRatesCsv(reader io.Reader) (int, error)
+
+	NormalizeCurrencyCode(currencyCode string) (string, error)
+
+	PriceFromUSD(dollarValue float64, currencyCode string) (CurrencyPrice, error)
+
+	PriceForPack(pack *domain.Pack, currencyCode string) (CurrencyPrice, error)
+}
+
+type domainCurrencyService struct {
+	repository repository.ExchangeRateRepository
+}
+
+func NewDomainCurrencyService(repository repository.ExchangeRateRepository) CurrencyService {
+	return &domainCurrencyService{repository}
+}
+
+func (port *domainCurrencyService) FindAllRates() ([]domain.ExchangeRate, error) {
+	return port.repository.FindAll()
+}
+
+func (port *domainCurrencyService) SaveRate(currencyCode string, unitsPerUSD float64) (*domain.ExchangeRate, error) {
+	return port.saveRate(currencyCode, unitsPerUSD, domain.ExchangeRateSourceAdmin)
+}
+
+func (port *domainCurrencyService) DeleteRate(currencyCode string) error {
+	code, err := port.NormalizeCurrencyCode(currencyCode)
+	if err != nil {
+		return err
+	}
+	return port.repository.DeleteByCurrencyCode(code)
+}
+
+// ImportRatesCsv reads lines with the format "currencyCode,unitsPerUsd". A first
+// line with a non numeric rate is considered a header and skipped.
+func (port *domainCurrencyService) ImportRatesCsv(reader io.Reader) (int, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = 2
+	csvReader.TrimLeadingSpace = true
+
+	type csvRate struct {
+		code  string
+		value float64
+	}
+
+	var rates []csvRate
+	line := 0
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return 0, fmt.Errorf("error reading exchange rates csv at line %d: %w", line, err)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return 0, fmt.Errorf("invalid exchange rate at line %d: %w", line, err)
+		}
+		rates = append(rates, csvRate{record[0], value})
+	}
+
+	// validate all the file before save any rate
+	for i, rate := range rates {
+		code, err := port.NormalizeCurrencyCode(rate.code)
+		if err != nil {
+			return 0, err
+		}
+		if rate.value <= 0 {
+			return 0, fmt.Errorf("invalid exchange rate for %s: %f", code, rate.value)
+		}
+		rates[i].code = code
+	}
+
+	for _, rate := range rates {
+		_, err := port.saveRate(rate.code, rate.value, domain.ExchangeRateSourceCsv)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(rates), nil
+}
+
+func (port *domainCurrencyService) NormalizeCurrencyCode(currencyCode string) (string, error) {
+	return normalizeCurrencyCode(currencyCode)
+}
+
+// normalizeCurrencyCode shared by the services that receive currency codes, the empty code is USD
+func normalizeCurrencyCode(currencyCode string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currencyCode))
+	if code == "" {
+		return domain.CurrencyCodeUSD, nil
+	}
+	if !currencyCodeRegex.MatchString(code) {
+		return "", exception.NewInvalidCurrencyCodeException(currencyCode)
+	}
+	return code, nil
+}
+
+func (port *domainCurrencyService) PriceFromUSD(dollarValue float64, currencyCode string) (CurrencyPrice, error) {
+	unitsPerUSD, code, err := port.getRate(currencyCode)
+	if err != nil {
+		return CurrencyPrice{}, err
+	}
+	return CurrencyPrice{
+		CurrencyCode: code,
+		Value:        domain.ConvertFromUSD(dollarValue, unitsPerUSD, code),
+		DollarValue:  domain.RoundCurrency(dollarValue, domain.CurrencyCodeUSD),
+		UnitsPerUSD:  unitsPerUSD,
+	}, nil
+}
+
+// PriceForPack uses the price defined by the model for the currency when exists,
+// otherwise converts the pack dollar value.
+func (port *domainCurrencyService) PriceForPack(pack *domain.Pack, currencyCode string) (CurrencyPrice, error) {
+	unitsPerUSD, code, err := port.getRate(currencyCode)
+	if err != nil {
+		return CurrencyPrice{}, err
+	}
+
+	if value, ok := pack.Prices[code]; ok && code != domain.CurrencyCodeUSD {
+		return CurrencyPrice{
+			CurrencyCode: code,
+			Value:        domain.RoundCurrency(value, code),
+			DollarValue:  domain.ConvertToUSD(value, unitsPerUSD),
+			UnitsPerUSD:  unitsPerUSD,
+		}, nil
+	}
+
+	if pack.DollarValue == nil {
+		return CurrencyPrice{}, fmt.Errorf("the pack does not have dollar value")
+	}
+	return port.PriceFromUSD(*pack.DollarValue, code)
+}
+
+// private
+
+func (port *domainCurrencyService) getRate(currencyCode string) (float64, string, error) {
+	code, err := port.NormalizeCurrencyCode(currencyCode)
+	if err != nil {
+		return 0, "", err
+	}
+	if code == domain.CurrencyCodeUSD {
+		return 1, code, nil
+	}
+	rate, err := port.repository.FindByCurrencyCode(code)
+	if err != nil {
+		return 0, "", err
+	}
+	if rate == nil {
+		return 0, "", exception.NewExchangeRateNotFoundException(code)
+	}
+	return rate.UnitsPerUSD, code, nil
+}
+
+func (port *domainCurrencyService) saveRate(currencyCode string, unitsPerUSD float64, source string) (*domain.ExchangeRate, error) {
+	code, err := port.NormalizeCurrencyCode(currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	if code == domain.CurrencyCodeUSD {
+		return nil, fmt.Errorf("the %s rate is fixed", domain.CurrencyCodeUSD)
+	}
+	if unitsPerUSD <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate for %s: %f", code, unitsPerUSD)
+	}
+
+	existingRate, err := port.repository.FindByCurrencyCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := domain.ExchangeRate{
+		CurrencyCode: code,
+		UnitsPerUSD:  unitsPerUSD,
+		Source:       source,
+		UpdateDate:   time.Now(),
+	}
+	if existingRate != nil {
+		rate.Id = existingRate.Id
+	}
+	return port.repository.Save(rate)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCurrencyCode(t *testing.T) {
+	code, err := normalizeCurrencyCode(" clp ")
+	require.NoError(t, err)
+	assert.Equal(t, "CLP", code)
+
+	code, err = normalizeCurrencyCode("")
+	require.NoError(t, err)
+	assert.Equal(t, domain.CurrencyCodeUSD, code)
+
+	for _, invalid := range []string{"C1P", "ÉUR", "CL", "CLPS"} {
+		_, err = normalizeCurrencyCode(invalid)
+		assert.IsType(t, &exception.BusinessException{}, err, invalid)
+	}
+}
@@ -0,0 +1,879 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"github.com/erodriguezg/meet/pkg/util/hashutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// in memory repositories for the tests of the services
+
+type fakePersonRepository struct {
+	persons map[primitive.ObjectID]domain.Person
+}
+
+func newFakePersonRepository(persons ...domain.Person) *fakePersonRepository {
+	repository := &fakePersonRepository{map[primitive.ObjectID]domain.Person{}}
+	for _, person := range persons {
+		repository.Persist(person)
+	}
+	return repository
+}
+
+func (port *fakePersonRepository) FindById(uuid string) (*domain.Person, error) {
+	id, _ := primitive.ObjectIDFromHex(uuid)
+	person, found := port.persons[id]
+	if !found {
+		return nil, nil
+	}
+	return &person, nil
+}
+
+func (port *fakePersonRepository) FindByEmail(email string) (*domain.Person, error) {
+	for _, person := range port.persons {
+		if strings.EqualFold(person.Email, email) {
+			return &person, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakePersonRepository) FindByExternalIdentity(provider string, subject string) (*domain.Person, error) {
+	for _, person := range port.persons {
+		if person.HasExternalIdentity(provider, subject) {
+			return &person, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakePersonRepository) FindAll() ([]domain.Person, error) {
+	var persons []domain.Person
+	for _, person := range port.persons {
+		persons = append(persons, person)
+	}
+	return persons, nil
+}
+
+func (port *fakePersonRepository) CountByProfileCodes(profileCodes []int, onlyActive bool) (int64, error) {
+	var count int64
+	for _, person := range port.persons {
+		if slices.Contains(profileCodes, person.ProfileCode) && (!onlyActive || person.Active) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (port *fakePersonRepository) FilterPaginated(filters domain.PersonFilter) ([]domain.Person, error) {
+	return port.FindAll()
+}
+
+func (port *fakePersonRepository) Persist(person domain.Person) (*domain.Person, error) {
+	if person.Id == nil {
+		id := primitive.NewObjectID()
+		person.Id = &id
+	}
+	port.persons[*person.Id] = person
+	return &person, nil
+}
+
+func (port *fakePersonRepository) Update(person domain.Person) (*domain.Person, error) {
+	return port.Persist(person)
+}
+
+func (port *fakePersonRepository) Delete(uuid string) error {
+	id, _ := primitive.ObjectIDFromHex(uuid)
+	delete(port.persons, id)
+	return nil
+}
+
+type fakeProfileRepository struct {
+	profiles []domain.Profile
+}
+
+func newFakeProfileRepository() *fakeProfileRepository {
+	return &fakeProfileRepository{[]domain.Profile{
+		{Code: domain.ProfileCodeAdministrator, Name: "Administrator", PermissionsCodes: []int{domain.PermissionCodeManageSystem}},
+		{Code: domain.ProfileCodeUser, Name: "User", PermissionsCodes: []int{domain.PermissionCodeEditOwnProfile}},
+	}}
+}
+
+func (port *fakeProfileRepository) FindByCode(code int) (*domain.Profile, error) {
+	for _, profile := range port.profiles {
+		if profile.Code == code {
+			return &profile, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakeProfileRepository) FindAll() ([]domain.Profile, error) {
+	return port.profiles, nil
+}
+
+func (port *fakeProfileRepository) FindByPermissionCode(permissionCode int) ([]domain.Profile, error) {
+	var profiles []domain.Profile
+	for _, profile := range port.profiles {
+		if profile.HasPermission(permissionCode) {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+func (port *fakeProfileRepository) Save(profile domain.Profile) (*domain.Profile, error) {
+	port.profiles = append(port.profiles, profile)
+	return &profile, nil
+}
+
+func (port *fakeProfileRepository) Delete(code int) error {
+	port.profiles = slices.DeleteFunc(port.profiles, func(profile domain.Profile) bool { return profile.Code == code })
+	return nil
+}
+
+type fakeAuditEntry struct {
+	actor    domain.AuditActor
+	action   string
+	targetId string
+}
+
+type fakeAuditService struct {
+	entries []fakeAuditEntry
+}
+
+func (port *fakeAuditService) Record(actor domain.AuditActor, action string, targetType string, targetId string, before any, after any) {
+	port.entries = append(port.entries, fakeAuditEntry{actor, action, targetId})
+}
+
+func (port *fakeAuditService) Search(filters domain.AuditFilter, first int, last int) (*domain.AuditSearchResponse, error) {
+	return &domain.AuditSearchResponse{}, nil
+}
+
+func (port *fakeAuditService) FindForExport(filters domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return nil, nil
+}
+
+type fakeRefreshTokenRepository struct {
+	tokens map[primitive.ObjectID]domain.RefreshToken
+}
+
+func newFakeRefreshTokenRepository() *fakeRefreshTokenRepository {
+	return &fakeRefreshTokenRepository{map[primitive.ObjectID]domain.RefreshToken{}}
+}
+
+func (port *fakeRefreshTokenRepository) Save(refreshToken domain.RefreshToken) error {
+	if refreshToken.Id == nil {
+		id := primitive.NewObjectID()
+		refreshToken.Id = &id
+	}
+	port.tokens[*refreshToken.Id] = refreshToken
+	return nil
+}
+
+func (port *fakeRefreshTokenRepository) FindByTokenHash(tokenHash string) (*domain.RefreshToken, error) {
+	for _, token := range port.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakeRefreshTokenRepository) MarkUsed(id primitive.ObjectID) (bool, error) {
+	token, found := port.tokens[id]
+	if !found || token.UsedDate != nil || token.RevokedDate != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedDate = &now
+	port.tokens[id] = token
+	return true, nil
+}
+
+func (port *fakeRefreshTokenRepository) RevokeFamily(familyId string) error {
+	now := time.Now()
+	for id, token := range port.tokens {
+		if token.FamilyId == familyId && token.RevokedDate == nil {
+			token.RevokedDate = &now
+			port.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+type fakeRevokedAccessTokenRepository struct {
+	jtis []string
+}
+
+func (port *fakeRevokedAccessTokenRepository) Save(revokedAccessToken domain.RevokedAccessToken) error {
+	port.jtis = append(port.jtis, revokedAccessToken.Jti)
+	return nil
+}
+
+func (port *fakeRevokedAccessTokenRepository) ExistsByJti(jti string) (bool, error) {
+	return slices.Contains(port.jtis, jti), nil
+}
+
+type fakeFileMetaDataRepository struct {
+	files map[string]domain.FileMetaData
+	// error returned by the next Save
+	saveErr error
+}
+
+func newFakeFileMetaDataRepository() *fakeFileMetaDataRepository {
+	return &fakeFileMetaDataRepository{files: map[string]domain.FileMetaData{}}
+}
+
+func (port *fakeFileMetaDataRepository) FindByHash(hash string) (*domain.FileMetaData, error) {
+	file, found := port.files[hash]
+	if !found {
+		return nil, nil
+	}
+	return &file, nil
+}
+
+func (port *fakeFileMetaDataRepository) FindAll() ([]domain.FileMetaData, error) {
+	var files []domain.FileMetaData
+	for _, file := range port.files {
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (port *fakeFileMetaDataRepository) FindUploadedNotInStorage(storageType string, includeLegacy bool, afterId *primitive.ObjectID, limit int) ([]domain.FileMetaData, error) {
+	var files []domain.FileMetaData
+	for _, file := range port.files {
+		if !file.Uploaded || file.StorageType == storageType || (file.StorageType == "" && !includeLegacy) {
+			continue
+		}
+		if afterId != nil && file.Id.Hex() <= afterId.Hex() {
+			continue
+		}
+		files = append(files, file)
+	}
+	slices.SortFunc(files, func(a, b domain.FileMetaData) int {
+		return strings.Compare(a.Id.Hex(), b.Id.Hex())
+	})
+	if len(files) > limit {
+		files = files[:limit]
+	}
+	return files, nil
+}
+
+func (port *fakeFileMetaDataRepository) UpdateStorage(files []domain.FileMetaData) error {
+	for _, file := range files {
+		stored := port.files[file.Hash]
+		stored.StorageType = file.StorageType
+		stored.Path = file.Path
+		stored.DownloadUrl = nil
+		port.files[file.Hash] = stored
+	}
+	return nil
+}
+
+func (port *fakeFileMetaDataRepository) Save(fileMetaData domain.FileMetaData) (*domain.FileMetaData, error) {
+	if port.saveErr != nil {
+		err := port.saveErr
+		port.saveErr = nil
+		return nil, err
+	}
+	if fileMetaData.Id == nil {
+		id := primitive.NewObjectID()
+		fileMetaData.Id = &id
+	}
+	port.files[fileMetaData.Hash] = fileMetaData
+	return &fileMetaData, nil
+}
+
+func (port *fakeFileMetaDataRepository) SaveMultipartPart(hash string, uploadId string, part domain.FileUploadPart) (bool, error) {
+	file, found := port.files[hash]
+	if !found || file.MultipartUpload == nil || file.MultipartUpload.UploadId != uploadId {
+		return false, nil
+	}
+	upload := *file.MultipartUpload
+	upload.CompletedParts = slices.DeleteFunc(slices.Clone(upload.CompletedParts), func(completedPart domain.FileUploadPart) bool {
+		return completedPart.PartNumber == part.PartNumber
+	})
+	upload.CompletedParts = append(upload.CompletedParts, part)
+	file.MultipartUpload = &upload
+	port.files[hash] = file
+	return true, nil
+}
+
+func (port *fakeFileMetaDataRepository) Delete(id primitive.ObjectID) error {
+	for hash, file := range port.files {
+		if *file.Id == id {
+			delete(port.files, hash)
+		}
+	}
+	return nil
+}
+
+type fakeFileBlobRepository struct {
+	blobs map[string]domain.FileBlob
+}
+
+func newFakeFileBlobRepository() *fakeFileBlobRepository {
+	return &fakeFileBlobRepository{map[string]domain.FileBlob{}}
+}
+
+func (port *fakeFileBlobRepository) AcquireBlob(blob domain.FileBlob) (*domain.FileBlob, error) {
+	key := blob.StorageType + "/" + blob.Digest
+	acquired, found := port.blobs[key]
+	if !found {
+		id := primitive.NewObjectID()
+		acquired = blob
+		acquired.Id = &id
+		acquired.CreationDate = time.Now()
+	}
+	acquired.RefCount++
+	port.blobs[key] = acquired
+	return &acquired, nil
+}
+
+func (port *fakeFileBlobRepository) ReleaseBlob(digest string, storageType string) (*domain.FileBlob, error) {
+	key := storageType + "/" + digest
+	released, found := port.blobs[key]
+	if !found {
+		return nil, nil
+	}
+	released.RefCount--
+	port.blobs[key] = released
+	return &released, nil
+}
+
+func (port *fakeFileBlobRepository) MarkDeleting(id primitive.ObjectID) (bool, error) {
+	for key, blob := range port.blobs {
+		if *blob.Id == id && blob.RefCount <= 0 && blob.DeletingDate == nil {
+			now := time.Now()
+			blob.DeletingDate = &now
+			port.blobs[key] = blob
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (port *fakeFileBlobRepository) Delete(id primitive.ObjectID) error {
+	for key, blob := range port.blobs {
+		if *blob.Id == id {
+			delete(port.blobs, key)
+		}
+	}
+	return nil
+}
+
+func (port *fakeFileBlobRepository) find(storageType string, digest string) *domain.FileBlob {
+	blob, found := port.blobs[storageType+"/"+digest]
+	if !found {
+		return nil
+	}
+	return &blob
+}
+
+// fakeStorageRepository keeps the objects in memory by path
+type fakeStorageRepository struct {
+	storageType string
+	objects     map[string][]byte
+}
+
+func newFakeStorageRepository(storageType string) *fakeStorageRepository {
+	return &fakeStorageRepository{storageType, map[string][]byte{}}
+}
+
+func (port *fakeStorageRepository) GetStorageType() string {
+	return port.storageType
+}
+
+func (port *fakeStorageRepository) GetFileUploadUrl(metaData domain.FileMetaData) (string, error) {
+	return "memory://" + metaData.Path, nil
+}
+
+func (port *fakeStorageRepository) GetFileDownloadUrl(metaData domain.FileMetaData) (string, error) {
+	return "memory://" + metaData.Path, nil
+}
+
+func (port *fakeStorageRepository) GetFileTemporaryDownloadUrl(metaData domain.FileMetaData, expiration time.Duration) (string, error) {
+	return "memory://" + metaData.Path, nil
+}
+
+func (port *fakeStorageRepository) DeleteFile(metaData domain.FileMetaData) error {
+	delete(port.objects, metaData.Path)
+	return nil
+}
+
+func (port *fakeStorageRepository) StatFile(metaData domain.FileMetaData) (*domain.FileStat, error) {
+	content, found := port.objects[metaData.Path]
+	if !found {
+		return nil, nil
+	}
+	return &domain.FileStat{
+		Size:     int64(len(content)),
+		Checksum: "sha256:" + hashutil.SHA256HexEncoding(string(content)),
+	}, nil
+}
+
+func (port *fakeStorageRepository) ReadFileHead(metaData domain.FileMetaData, length int) ([]byte, error) {
+	content := port.objects[metaData.Path]
+	return content[:min(length, len(content))], nil
+}
+
+func (port *fakeStorageRepository) ReadFile(metaData domain.FileMetaData) (io.ReadCloser, error) {
+	content, found := port.objects[metaData.Path]
+	if !found {
+		return nil, fmt.Errorf("object not found: %s", metaData.Path)
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (port *fakeStorageRepository) PutFile(metaData domain.FileMetaData, content []byte, contentType string) error {
+	port.objects[metaData.Path] = slices.Clone(content)
+	return nil
+}
+
+func (port *fakeStorageRepository) PutFileStream(metaData domain.FileMetaData, reader io.Reader, contentType string) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	port.objects[metaData.Path] = content
+	return nil
+}
+
+func (port *fakeStorageRepository) CopyFile(source domain.FileMetaData, target domain.FileMetaData) error {
+	content, found := port.objects[source.Path]
+	if !found {
+		return fmt.Errorf("object not found: %s", source.Path)
+	}
+	port.objects[target.Path] = content
+	return nil
+}
+
+func (port *fakeStorageRepository) InitiateMultipartUpload(metaData domain.FileMetaData) (string, error) {
+	return "", repository.ErrMultipartUploadNotSupported
+}
+
+func (port *fakeStorageRepository) GetMultipartUploadPartUrl(metaData domain.FileMetaData, uploadId string, partNumber int) (string, error) {
+	return "", repository.ErrMultipartUploadNotSupported
+}
+
+func (port *fakeStorageRepository) CompleteMultipartUpload(metaData domain.FileMetaData, uploadId string, parts []domain.FileUploadPart) error {
+	return repository.ErrMultipartUploadNotSupported
+}
+
+func (port *fakeStorageRepository) AbortMultipartUpload(metaData domain.FileMetaData, uploadId string) error {
+	return repository.ErrMultipartUploadNotSupported
+}
+
+type fakeOwnedResourceRepository struct {
+	resources map[string]domain.OwnedResources
+}
+
+func newFakeOwnedResourceRepository() *fakeOwnedResourceRepository {
+	return &fakeOwnedResourceRepository{map[string]domain.OwnedResources{}}
+}
+
+func (port *fakeOwnedResourceRepository) FindByPersonId(personId string) (*domain.OwnedResources, error) {
+	resources, found := port.resources[personId]
+	if !found {
+		return nil, nil
+	}
+	return &resources, nil
+}
+
+func (port *fakeOwnedResourceRepository) Save(resources domain.OwnedResources) (*domain.OwnedResources, error) {
+	resources.OwnedPacksId = slices.Clone(resources.OwnedPacksId)
+	port.resources[resources.PersonId.Hex()] = resources
+	return &resources, nil
+}
+
+type fakeExchangeRateRepository struct {
+	rates map[string]domain.ExchangeRate
+}
+
+func newFakeExchangeRateRepository(rates ...domain.ExchangeRate) *fakeExchangeRateRepository {
+	repository := &fakeExchangeRateRepository{map[string]domain.ExchangeRate{}}
+	for _, rate := range rates {
+		repository.rates[rate.CurrencyCode] = rate
+	}
+	return repository
+}
+
+func (port *fakeExchangeRateRepository) FindAll() ([]domain.ExchangeRate, error) {
+	var rates []domain.ExchangeRate
+	for _, rate := range port.rates {
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func (port *fakeExchangeRateRepository) FindByCurrencyCode(currencyCode string) (*domain.ExchangeRate, error) {
+	rate, found := port.rates[currencyCode]
+	if !found {
+		return nil, nil
+	}
+	return &rate, nil
+}
+
+func (port *fakeExchangeRateRepository) Save(rate domain.ExchangeRate) (*domain.ExchangeRate, error) {
+	port.rates[rate.CurrencyCode] = rate
+	return &rate, nil
+}
+
+func (port *fakeExchangeRateRepository) DeleteByCurrencyCode(currencyCode string) error {
+	delete(port.rates, currencyCode)
+	return nil
+}
+
+type fakePaymentOrderRepository struct {
+	orders map[string]domain.PaymentOrder
+}
+
+func newFakePaymentOrderRepository() *fakePaymentOrderRepository {
+	return &fakePaymentOrderRepository{map[string]domain.PaymentOrder{}}
+}
+
+func (port *fakePaymentOrderRepository) SavePaymentOrder(paymentOrder *domain.PaymentOrder) (*domain.PaymentOrder, error) {
+	saved := *paymentOrder
+	if saved.Id == nil {
+		id := primitive.NewObjectID()
+		saved.Id = &id
+	}
+	port.orders[saved.OrderId] = saved
+	return &saved, nil
+}
+
+func (port *fakePaymentOrderRepository) FindByOrderId(orderId string) (*domain.PaymentOrder, error) {
+	order, found := port.orders[orderId]
+	if !found {
+		return nil, nil
+	}
+	return &order, nil
+}
+
+func (port *fakePaymentOrderRepository) FindById(paymentOrderId string) (*domain.PaymentOrder, error) {
+	for _, order := range port.orders {
+		if order.Id.Hex() == paymentOrderId {
+			return &order, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakePaymentOrderRepository) FindCapturedByOwnerAndPackId(personId string, packId string) (*domain.PaymentOrder, error) {
+	for _, order := range port.orders {
+		if order.CapturedAt == nil {
+			continue
+		}
+		owner := order.PersonId
+		if order.RecipientPersonId != nil {
+			owner = *order.RecipientPersonId
+		}
+		if owner.Hex() != personId {
+			continue
+		}
+		if slices.ContainsFunc(order.PacksId(), func(id primitive.ObjectID) bool { return id.Hex() == packId }) {
+			return &order, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakePaymentClient approves every order, the created orders are numbered
+type fakePaymentClient struct {
+	createdOrders  []fakePaymentClientOrder
+	capturedOrders []string
+}
+
+type fakePaymentClientOrder struct {
+	value        float64
+	currencyCode string
+}
+
+func (port *fakePaymentClient) GetClientData() (map[string]any, error) {
+	return map[string]any{}, nil
+}
+
+// SupportsCurrency like paypal, the fake does not charge in CLP
+func (port *fakePaymentClient) SupportsCurrency(currencyCode string) bool {
+	return currencyCode != domain.CurrencyCodeCLP
+}
+
+func (port *fakePaymentClient) CreateOrder(value float64, currencyCode string) (string, error) {
+	port.createdOrders = append(port.createdOrders, fakePaymentClientOrder{value, currencyCode})
+	return fmt.Sprintf("order-%d", len(port.createdOrders)), nil
+}
+
+func (port *fakePaymentClient) CapturePayment(orderID string) (map[string]any, error) {
+	port.capturedOrders = append(port.capturedOrders, orderID)
+	return map[string]any{"status": "COMPLETED"}, nil
+}
+
+// the fakes of the services embed the interface, only the methods used by the tests are
+// implemented
+
+type fakeModelService struct {
+	ModelService
+	models []domain.Model
+}
+
+func (port *fakeModelService) FindModelByNickName(modelNickName string) (*domain.Model, error) {
+	for _, model := range port.models {
+		if model.NickName == modelNickName {
+			return &model, nil
+		}
+	}
+	return nil, nil
+}
+
+type fakePackService struct {
+	PackService
+	packs []domain.Pack
+}
+
+func (port *fakePackService) FindActivePackByModelIdAndPackNumber(modelId string, packNumber int) (*domain.Pack, error) {
+	for _, pack := range port.packs {
+		if pack.ModelId.Hex() == modelId && pack.PackNumber == packNumber && pack.Active {
+			return &pack, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindPackByModelNicknameAndPackNumber the fake has the packs of only one model
+func (port *fakePackService) FindPackByModelNicknameAndPackNumber(modelNickname string, packNumber int) (*domain.Pack, error) {
+	for _, pack := range port.packs {
+		if pack.PackNumber == packNumber {
+			return &pack, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakePackRepository saves the packs in the fake pack service
+type fakePackRepository struct {
+	repository.PackRepository
+	packService *fakePackService
+}
+
+func (port *fakePackRepository) SavePack(pack domain.Pack) (*domain.Pack, error) {
+	for i := range port.packService.packs {
+		if *port.packService.packs[i].Id == *pack.Id {
+			port.packService.packs[i] = pack
+		}
+	}
+	return &pack, nil
+}
+
+// FindAllPacks includes the inactive packs
+func (port *fakePackRepository) FindAllPacks() ([]domain.Pack, error) {
+	return port.packService.packs, nil
+}
+
+type fakeModelRepository struct {
+	repository.ModelRepository
+	models []domain.Model
+}
+
+func (port *fakeModelRepository) FindAllModels() ([]domain.Model, error) {
+	return port.models, nil
+}
+
+type fakeWatermarkedFileRepository struct {
+	repository.WatermarkedFileRepository
+	deletedFileHashes []string
+}
+
+func (port *fakeWatermarkedFileRepository) DeleteByFileHash(fileHash string) error {
+	port.deletedFileHashes = append(port.deletedFileHashes, fileHash)
+	return nil
+}
+
+type fakePackPaymentMethodRepository struct {
+	paymentMethods map[primitive.ObjectID]domain.PackPaymentMethod
+}
+
+func newFakePackPaymentMethodRepository() *fakePackPaymentMethodRepository {
+	return &fakePackPaymentMethodRepository{map[primitive.ObjectID]domain.PackPaymentMethod{}}
+}
+
+func (port *fakePackPaymentMethodRepository) Save(paymentMethod domain.PackPaymentMethod) (domain.PackPaymentMethod, error) {
+	port.paymentMethods[paymentMethod.PackId] = paymentMethod
+	return paymentMethod, nil
+}
+
+func (port *fakePackPaymentMethodRepository) FindByPackId(packId primitive.ObjectID) (*domain.PackPaymentMethod, error) {
+	paymentMethod, found := port.paymentMethods[packId]
+	if !found {
+		return nil, nil
+	}
+	return &paymentMethod, nil
+}
+
+type fakePackBundleService struct {
+	PackBundleService
+	bundles []domain.PackBundle
+	packs   []domain.Pack
+}
+
+func (port *fakePackBundleService) FindActiveBundleByModelIdAndBundleNumber(modelId string, bundleNumber int) (*domain.PackBundle, error) {
+	for _, bundle := range port.bundles {
+		if bundle.ModelId.Hex() == modelId && bundle.BundleNumber == bundleNumber && bundle.Active {
+			return &bundle, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakePackBundleService) GetBundlePacks(bundle *domain.PackBundle) ([]domain.Pack, error) {
+	var packs []domain.Pack
+	for _, pack := range port.packs {
+		if slices.Contains(bundle.PacksId, *pack.Id) {
+			packs = append(packs, pack)
+		}
+	}
+	return packs, nil
+}
+
+type fakePermissionRepository struct {
+	permissions []domain.Permission
+}
+
+func newFakePermissionRepository() *fakePermissionRepository {
+	return &fakePermissionRepository{[]domain.Permission{
+		{Code: domain.PermissionCodeManageSystem, Name: "Manage System"},
+		{Code: domain.PermissionCodeEditOwnProfile, Name: "Edit Own Profile"},
+	}}
+}
+
+func (port *fakePermissionRepository) FindByCode(code int) (*domain.Permission, error) {
+	for _, permission := range port.permissions {
+		if permission.Code == code {
+			return &permission, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakePermissionRepository) FindAll() ([]domain.Permission, error) {
+	return port.permissions, nil
+}
+
+func (port *fakePermissionRepository) Save(permission domain.Permission) (*domain.Permission, error) {
+	port.permissions = slices.DeleteFunc(port.permissions, func(saved domain.Permission) bool { return saved.Code == permission.Code })
+	port.permissions = append(port.permissions, permission)
+	return &permission, nil
+}
+
+func (port *fakePermissionRepository) Delete(code int) error {
+	port.permissions = slices.DeleteFunc(port.permissions, func(permission domain.Permission) bool { return permission.Code == code })
+	return nil
+}
+
+type fakeServiceAccountRepository struct {
+	serviceAccounts map[string]domain.ServiceAccount
+}
+
+func newFakeServiceAccountRepository() *fakeServiceAccountRepository {
+	return &fakeServiceAccountRepository{map[string]domain.ServiceAccount{}}
+}
+
+func (port *fakeServiceAccountRepository) FindById(id string) (*domain.ServiceAccount, error) {
+	serviceAccount, found := port.serviceAccounts[id]
+	if !found {
+		return nil, nil
+	}
+	return &serviceAccount, nil
+}
+
+func (port *fakeServiceAccountRepository) FindByName(name string) (*domain.ServiceAccount, error) {
+	for _, serviceAccount := range port.serviceAccounts {
+		if serviceAccount.Name == name {
+			return &serviceAccount, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakeServiceAccountRepository) FindAll() ([]domain.ServiceAccount, error) {
+	var serviceAccounts []domain.ServiceAccount
+	for _, serviceAccount := range port.serviceAccounts {
+		serviceAccounts = append(serviceAccounts, serviceAccount)
+	}
+	return serviceAccounts, nil
+}
+
+func (port *fakeServiceAccountRepository) Save(serviceAccount domain.ServiceAccount) (*domain.ServiceAccount, error) {
+	if serviceAccount.Id == nil {
+		id := primitive.NewObjectID()
+		serviceAccount.Id = &id
+	}
+	port.serviceAccounts[serviceAccount.Id.Hex()] = serviceAccount
+	return &serviceAccount, nil
+}
+
+func (port *fakeServiceAccountRepository) Delete(id primitive.ObjectID) error {
+	delete(port.serviceAccounts, id.Hex())
+	return nil
+}
+
+type fakeApiKeyRepository struct {
+	apiKeys []domain.ApiKey
+}
+
+func (port *fakeApiKeyRepository) FindByPrefix(prefix string) (*domain.ApiKey, error) {
+	for _, apiKey := range port.apiKeys {
+		if apiKey.Prefix == prefix {
+			return &apiKey, nil
+		}
+	}
+	return nil, nil
+}
+
+func (port *fakeApiKeyRepository) FindByServiceAccountId(serviceAccountId primitive.ObjectID) ([]domain.ApiKey, error) {
+	var apiKeys []domain.ApiKey
+	for _, apiKey := range port.apiKeys {
+		if apiKey.ServiceAccountId == serviceAccountId {
+			apiKeys = append(apiKeys, apiKey)
+		}
+	}
+	return apiKeys, nil
+}
+
+func (port *fakeApiKeyRepository) Save(apiKey domain.ApiKey) (*domain.ApiKey, error) {
+	id := primitive.NewObjectID()
+	apiKey.Id = &id
+	port.apiKeys = append(port.apiKeys, apiKey)
+	return &apiKey, nil
+}
+
+func (port *fakeApiKeyRepository) Revoke(serviceAccountId primitive.ObjectID, id primitive.ObjectID) (bool, error) {
+	for i := range port.apiKeys {
+		apiKey := &port.apiKeys[i]
+		if apiKey.ServiceAccountId == serviceAccountId && *apiKey.Id == id && apiKey.RevokedDate == nil {
+			now := time.Now()
+			apiKey.RevokedDate = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (port *fakeApiKeyRepository) RevokeByServiceAccountId(serviceAccountId primitive.ObjectID) error {
+	for i := range port.apiKeys {
+		if port.apiKeys[i].ServiceAccountId == serviceAccountId && port.apiKeys[i].RevokedDate == nil {
+			now := time.Now()
+			port.apiKeys[i].RevokedDate = &now
+		}
+	}
+	return nil
+}
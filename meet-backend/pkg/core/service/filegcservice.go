@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+)
+
+const (
+	FileGcReasonAbandonedUpload = "abandoned-upload"
+	FileGcReasonUnreferenced    = "unreferenced"
+	FileGcReasonDanglingSource  = "dangling-source"
+
+	// files younger than this are never collected, the upload can be in progress
+	FileGcDefaultMinAge = 24 * time.Hour
+)
+
+type FileGcService interface {
+	// CollectGarbage finds the files not referenced by the active items of the active packs
+	// or by the models profile, and deletes them when dryRun is false. The generated
+	// files (previews, watermarked copies) follow their source file.
+	CollectGarbage(dryRun bool, minAge time.Duration) (*dto.FileGcReportDto, error)
+}
+
+type domainFileGcService struct {
+	fileService               FileService
+	fileMetaDataRepository    repository.FileMetaDataRepository
+	packRepository            repository.PackRepository
+	modelRepository           repository.ModelRepository
+	watermarkedFileRepository repository.WatermarkedFileRepository
+}
+
+func NewDomainFileGcService(
+	fileService FileService,
+	fileMetaDataRepository repository.FileMetaDataRepository,
+	packRepository repository.PackRepository,
+	modelRepository repository.ModelRepository,
+	watermarkedFileRepository repository.WatermarkedFileRepository,
+) FileGcService {
+	return &domainFileGcService{
+		fileService,
+		fileMetaDataRepository,
+		packRepository,
+		modelRepository,
+		watermarkedFileRepository,
+	}
+}
+
+func (port *domainFileGcService) CollectGarbage(dryRun bool, minAge time.Duration) (*dto.FileGcReportDto, error) {
+	referenced, err := port.findReferencedHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := port.fileMetaDataRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	filesByHash := make(map[string]*domain.FileMetaData, len(files))
+	for i := range files {
+		filesByHash[files[i].Hash] = &files[i]
+	}
+
+	report := dto.FileGcReportDto{
+		DryRun:       dryRun,
+		ScannedFiles: len(files),
+		Garbage:      []dto.FileGcEntryDto{},
+	}
+
+	limitDate := time.Now().Add(-minAge)
+	for i := range files {
+		file := &files[i]
+		if file.Id == nil || file.Id.Timestamp().After(limitDate) {
+			continue
+		}
+
+		reason := port.garbageReason(file, filesByHash, referenced)
+		if reason == "" {
+			continue
+		}
+
+		entry := dto.FileGcEntryDto{
+			Hash:   file.Hash,
+			Path:   file.Path,
+			Reason: reason,
+			Size:   file.Size,
+		}
+
+		if !dryRun {
+			err = port.deleteFile(file)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", file.Hash, err.Error()))
+				continue
+			}
+		}
+
+		report.Garbage = append(report.Garbage, entry)
+		report.FreedBytes += entry.Size
+	}
+
+	return &report, nil
+}
+
+// private
+
+func (port *domainFileGcService) findReferencedHashes() (map[string]bool, error) {
+	referenced := map[string]bool{}
+
+	packs, err := port.packRepository.FindAllPacks()
+	if err != nil {
+		return nil, err
+	}
+	for _, pack := range packs {
+		if !pack.Active {
+			continue
+		}
+		for _, item := range pack.PackItems {
+			if !item.Active {
+				continue
+			}
+			referenced[item.ResourceFileHash] = true
+			referenced[item.ThumbnailFileHash] = true
+			referenced[item.ThumbnailLockedFileHash] = true
+		}
+	}
+
+	models, err := port.modelRepository.FindAllModels()
+	if err != nil {
+		return nil, err
+	}
+	for _, model := range models {
+		if model.ProfileImageFileHash != nil {
+			referenced[*model.ProfileImageFileHash] = true
+		}
+		if model.ProfileImageThumbnailFileHash != nil {
+			referenced[*model.ProfileImageThumbnailFileHash] = true
+		}
+		// the image uploaded but not confirmed yet
+		if model.PendingProfileImageFileHash != nil {
+			referenced[*model.PendingProfileImageFileHash] = true
+		}
+		if model.PendingProfileImageThumbnailFileHash != nil {
+			referenced[*model.PendingProfileImageThumbnailFileHash] = true
+		}
+	}
+
+	return referenced, nil
+}
+
+// garbageReason returns empty when the file must be kept.
+func (port *domainFileGcService) garbageReason(
+	file *domain.FileMetaData,
+	filesByHash map[string]*domain.FileMetaData,
+	referenced map[string]bool,
+) string {
+	if referenced[file.Hash] {
+		return ""
+	}
+
+	if file.SourceFileHash != nil {
+		source, exists := filesByHash[*file.SourceFileHash]
+		if !exists {
+			return FileGcReasonDanglingSource
+		}
+		return port.garbageReason(source, filesByHash, referenced)
+	}
+
+	if !file.Uploaded {
+		return FileGcReasonAbandonedUpload
+	}
+	return FileGcReasonUnreferenced
+}
+
+// deleteFile deletes the file with the file service, so the shared blobs are kept while
+// other files reference them.
+func (port *domainFileGcService) deleteFile(file *domain.FileMetaData) error {
+	err := port.watermarkedFileRepository.DeleteByFileHash(file.Hash)
+	if err != nil {
+		return fmt.Errorf("error at watermarkedFileRepository.DeleteByFileHash. error: %w", err)
+	}
+	return port.fileService.Delete(file.Hash)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testFileGcMinAge = time.Hour
+
+type testFileGc struct {
+	*testFileService
+	service        FileGcService
+	packService    *fakePackService
+	models         *fakeModelRepository
+	watermarkFiles *fakeWatermarkedFileRepository
+}
+
+func newTestFileGc() *testFileGc {
+	fileService := newTestFileService()
+	packService := &fakePackService{}
+	models := &fakeModelRepository{}
+	watermarkFiles := &fakeWatermarkedFileRepository{}
+	service := NewDomainFileGcService(fileService, fileService.fileMetaDataRepo,
+		&fakePackRepository{packService: packService}, models, watermarkFiles)
+	return &testFileGc{fileService, service, packService, models, watermarkFiles}
+}
+
+// old the file was created before the min age, its id gives the creation date
+func (port *testFileGc) old(hashes ...string) {
+	for _, hash := range hashes {
+		file := port.fileMetaDataRepo.files[hash]
+		id := primitive.NewObjectIDFromTimestamp(time.Now().Add(-2 * testFileGcMinAge))
+		file.Id = &id
+		port.fileMetaDataRepo.files[hash] = file
+	}
+}
+
+// uploadWithPreviews an upload not confirmed yet with its thumbnail and locked previews
+func (port *testFileGc) uploadWithPreviews(t *testing.T, name string) (*domain.FileMetaData, string, string) {
+	source := port.upload(t, "uploads/"+name+".jpg", name)
+	thumbnail, err := port.CreatePreview(source.Hash, domain.FilePreviewThumbnail, "uploads/"+name+"-thumbnail.jpg", []string{name})
+	require.NoError(t, err)
+	locked, err := port.CreatePreview(source.Hash, domain.FilePreviewLocked, "uploads/"+name+"-locked.jpg", []string{name})
+	require.NoError(t, err)
+	return source, thumbnail.Hash, locked.Hash
+}
+
+func gcReasons(report *dto.FileGcReportDto) map[string]string {
+	reasons := map[string]string{}
+	for _, entry := range report.Garbage {
+		reasons[entry.Hash] = entry.Reason
+	}
+	return reasons
+}
+
+func TestCollectGarbageDryRunDeletesNothing(t *testing.T) {
+	gc := newTestFileGc()
+	abandoned := gc.upload(t, "uploads/abandoned.txt", "abandoned")
+	gc.old(abandoned.Hash)
+
+	report, err := gc.service.CollectGarbage(true, testFileGcMinAge)
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, map[string]string{abandoned.Hash: FileGcReasonAbandonedUpload}, gcReasons(report))
+	stillStored, _ := gc.FindByHash(abandoned.Hash)
+	assert.NotNil(t, stillStored)
+	assert.Contains(t, gc.storage.objects, "uploads/abandoned.txt")
+	assert.Empty(t, gc.watermarkFiles.deletedFileHashes)
+}
+
+func TestCollectGarbageDeletesOldAbandonedUploads(t *testing.T) {
+	gc := newTestFileGc()
+	abandoned := gc.upload(t, "uploads/abandoned.txt", "abandoned")
+	fresh := gc.upload(t, "uploads/fresh.txt", "fresh")
+	gc.old(abandoned.Hash)
+
+	report, err := gc.service.CollectGarbage(false, testFileGcMinAge)
+	require.NoError(t, err)
+
+	// the fresh upload can be in progress
+	assert.Equal(t, map[string]string{abandoned.Hash: FileGcReasonAbandonedUpload}, gcReasons(report))
+	assert.Equal(t, 2, report.ScannedFiles)
+	deleted, _ := gc.FindByHash(abandoned.Hash)
+	assert.Nil(t, deleted)
+	assert.NotContains(t, gc.storage.objects, "uploads/abandoned.txt")
+	kept, _ := gc.FindByHash(fresh.Hash)
+	assert.NotNil(t, kept)
+	assert.Contains(t, gc.storage.objects, "uploads/fresh.txt")
+}
+
+func TestCollectGarbageDeletesUnreferencedUploads(t *testing.T) {
+	gc := newTestFileGc()
+	unreferenced := gc.upload(t, "uploads/unreferenced.txt", "unreferenced")
+	require.NoError(t, gc.ConfirmUploaded(unreferenced.Hash, gc.uploaderId))
+	confirmed, _ := gc.FindByHash(unreferenced.Hash)
+	gc.old(unreferenced.Hash)
+
+	report, err := gc.service.CollectGarbage(false, testFileGcMinAge)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{unreferenced.Hash: FileGcReasonUnreferenced}, gcReasons(report))
+	assert.Equal(t, int64(len("unreferenced")), report.FreedBytes)
+	assert.NotContains(t, gc.storage.objects, confirmed.Path)
+	assert.Equal(t, []string{unreferenced.Hash}, gc.watermarkFiles.deletedFileHashes)
+}
+
+func TestCollectGarbageGeneratedFilesFollowTheirSource(t *testing.T) {
+	gc := newTestFileGc()
+	kept, keptThumbnail, keptLocked := gc.uploadWithPreviews(t, "kept")
+	removed, removedThumbnail, removedLocked := gc.uploadWithPreviews(t, "removed")
+	watermarked, err := gc.CreateGenerated(removed.Hash, "uploads/removed-wm.jpg", []string{"removed-wm"},
+		domain.PackItemTypeCodeImgJpg, []byte("watermarked"), "image/jpeg")
+	require.NoError(t, err)
+	dangling, err := gc.CreatePreview(removed.Hash, domain.FilePreviewThumbnail, "uploads/dangling.jpg", []string{"dangling"})
+	require.NoError(t, err)
+	danglingSource := "missing"
+	dangling.SourceFileHash = &danglingSource
+	gc.fileMetaDataRepo.files[dangling.Hash] = *dangling
+
+	packId := primitive.NewObjectID()
+	gc.packService.packs = []domain.Pack{{Id: &packId, Active: true, PackItems: []domain.PackItem{
+		{ResourceFileHash: kept.Hash, ThumbnailFileHash: keptThumbnail, ThumbnailLockedFileHash: keptLocked, Active: true},
+		{ResourceFileHash: removed.Hash, ThumbnailFileHash: removedThumbnail, ThumbnailLockedFileHash: removedLocked, Active: false},
+	}}}
+	gc.old(kept.Hash, keptThumbnail, keptLocked, removed.Hash, removedThumbnail, removedLocked, watermarked.Hash, dangling.Hash)
+
+	report, err := gc.service.CollectGarbage(false, testFileGcMinAge)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		removed.Hash:     FileGcReasonAbandonedUpload,
+		removedThumbnail: FileGcReasonAbandonedUpload,
+		removedLocked:    FileGcReasonAbandonedUpload,
+		watermarked.Hash: FileGcReasonAbandonedUpload,
+		dangling.Hash:    FileGcReasonDanglingSource,
+	}, gcReasons(report))
+	assert.Empty(t, report.Errors)
+	assert.NotContains(t, gc.storage.objects, "uploads/removed-wm.jpg")
+	assert.Contains(t, gc.watermarkFiles.deletedFileHashes, watermarked.Hash)
+	for _, hash := range []string{kept.Hash, keptThumbnail, keptLocked} {
+		file, _ := gc.FindByHash(hash)
+		assert.NotNil(t, file)
+	}
+}
+
+func TestCollectGarbageKeepsTheProfileImages(t *testing.T) {
+	gc := newTestFileGc()
+	current := gc.upload(t, "uploads/current.png", "current")
+	pending := gc.upload(t, "uploads/pending.png", "pending")
+	pendingThumbnail := gc.upload(t, "uploads/pending-thumbnail.png", "pending thumbnail")
+	replaced := gc.upload(t, "uploads/replaced.png", "replaced")
+	gc.old(current.Hash, pending.Hash, pendingThumbnail.Hash, replaced.Hash)
+
+	gc.models.models = []domain.Model{{
+		ProfileImageFileHash:                 &current.Hash,
+		PendingProfileImageFileHash:          &pending.Hash,
+		PendingProfileImageThumbnailFileHash: &pendingThumbnail.Hash,
+	}}
+
+	report, err := gc.service.CollectGarbage(false, testFileGcMinAge)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{replaced.Hash: FileGcReasonAbandonedUpload}, gcReasons(report))
+	for _, hash := range []string{current.Hash, pending.Hash, pendingThumbnail.Hash} {
+		file, _ := gc.FindByHash(hash)
+		assert.NotNil(t, file)
+	}
+}
@@ -1,11 +1,24 @@
 package service
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"image"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
 	"github.com/erodriguezg/meet/pkg/core/repository"
 	"github.com/erodriguezg/meet/pkg/util/hashutil"
+	"github.com/erodriguezg/meet/pkg/util/imageutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type FileService interface {
@@ -15,65 +28,305 @@ type FileService interface {
 
 	GetDownloadUrl(hash string) (string, error)
 
-	CreateForUpload(path string, hashSeeds []string) (*domain.FileMetaData, string, error)
+	GetTemporaryDownloadUrl(hash string) (string, error)
 
-	ConfirmUploaded(hash string) error
+	// CreateForUpload the images smaller than minImageSize (width or height) are rejected when
+	// the upload is confirmed, 0 for accept any size
+	CreateForUpload(path string, hashSeeds []string, typeCode string, uploaderPersonId string, minImageSize int) (*domain.FileMetaData, string, error)
+
+	CreatePreview(sourceHash string, previewKind string, path string, hashSeeds []string) (*domain.FileMetaData, error)
+
+	CreateGenerated(sourceHash string, path string, hashSeeds []string, typeCode string, content []byte, contentType string) (*domain.FileMetaData, error)
+
+	ReadContent(hash string) (io.ReadCloser, error)
+
+	ConfirmUploaded(hash string, personIdRequester string) error
+
+	// StartMultipartUpload starts the upload in parts of a file created for upload, or
+	// returns the state of the upload already started so the client can resume it.
+	StartMultipartUpload(hash string, personIdRequester string, size int64) (*domain.FileMultipartUpload, error)
+
+	GetMultipartPartUploadUrl(hash string, personIdRequester string, partNumber int) (string, error)
+
+	RegisterMultipartPart(hash string, personIdRequester string, part domain.FileUploadPart) error
+
+	// CompleteMultipartUpload joins the parts in the storage and confirms the upload.
+	CompleteMultipartUpload(hash string, personIdRequester string) error
+
+	AbortMultipartUpload(hash string, personIdRequester string) error
 
 	Delete(hash string) error
 }
 
+const (
+	temporaryDownloadUrlExpiration = 10 * time.Minute
+
+	previewWidth           = 200
+	previewJpegQuality     = 80
+	lockedPreviewPixelSize = 12
+
+	sanitizedJpegQuality = 92
+)
+
+type FileAccessDeniedError struct {
+	FileHash string
+}
+
+func (e *FileAccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied to file with hash: %s", e.FileHash)
+}
+
 type domainFileService struct {
 	storageService   StorageService
 	fileMetaDataRepo repository.FileMetaDataRepository
+	fileBlobRepo     repository.FileBlobRepository
 }
 
-func NewFileService(storageService StorageService, fileMetaDataRepo repository.FileMetaDataRepository) FileService {
-	return &domainFileService{storageService, fileMetaDataRepo}
+func NewFileService(
+	storageService StorageService,
+	fileMetaDataRepo repository.FileMetaDataRepository,
+	fileBlobRepo repository.FileBlobRepository,
+) FileService {
+	return &domainFileService{storageService, fileMetaDataRepo, fileBlobRepo}
 }
 
 func (port *domainFileService) GetStorageType() string {
 	return port.storageService.GetStorageType()
 }
 
-func (port *domainFileService) ConfirmUploaded(hash string) error {
+// ConfirmUploaded verifies the uploaded file in the storage before mark it as uploaded.
+// Only the person who requested the upload can confirm it.
+func (port *domainFileService) ConfirmUploaded(hash string, personIdRequester string) error {
 
 	fileMetaData, err := port.mustGetOneByHash(hash)
 	if err != nil {
 		return err
 	}
 
+	if fileMetaData.UploaderPersonId == nil || fileMetaData.UploaderPersonId.Hex() != personIdRequester {
+		return &FileAccessDeniedError{hash}
+	}
+
+	if fileMetaData.Uploaded {
+		return nil
+	}
+
+	stat, err := port.storageService.StatFile(*fileMetaData)
+	if err != nil {
+		return fmt.Errorf("error at storageService.StatFile. error: %w", err)
+	}
+	if stat == nil {
+		return exception.NewFileNotUploadedException(hash)
+	}
+
+	if fileMetaData.TypeCode != "" {
+		maxSize := domain.MaxFileSize(fileMetaData.TypeCode)
+		if maxSize > 0 && stat.Size > maxSize {
+			return exception.NewFileTooLargeException(hash, stat.Size, maxSize)
+		}
+
+		head, err := port.storageService.ReadFileHead(*fileMetaData, domain.FileHeadLength)
+		if err != nil {
+			return fmt.Errorf("error at storageService.ReadFileHead. error: %w", err)
+		}
+		if !domain.MatchesTypeCode(fileMetaData.TypeCode, head) {
+			return exception.NewFileTypeMismatchException(hash, fileMetaData.TypeCode)
+		}
+	}
+
+	if isImageTypeCode(fileMetaData.TypeCode) {
+		stat, err = port.sanitizeImage(fileMetaData)
+		if err != nil {
+			return err
+		}
+		fileMetaData.Sanitized = true
+	}
+
+	err = port.generatePreviews(fileMetaData)
+	if err != nil {
+		return err
+	}
+
+	uploadedObject := *fileMetaData
+	err = port.storeContentAddressed(fileMetaData, stat)
+	if err != nil {
+		return err
+	}
+
+	uploadedDate := time.Now()
 	fileMetaData.Uploaded = true
+	fileMetaData.Size = stat.Size
+	fileMetaData.ContentType = stat.ContentType
+	fileMetaData.Checksum = "sha256:" + fileMetaData.ContentDigest
+	fileMetaData.UploadedDate = &uploadedDate
 
 	_, err = port.fileMetaDataRepo.Save(*fileMetaData)
 	if err != nil {
-		return fmt.Errorf("error at fileMetaDataRepo.Save, error: %w", err)
+		// the uploaded object is kept, so the confirmation can be retried
+		releaseErr := port.releaseBlob(fileMetaData)
+		return errors.Join(fmt.Errorf("error at fileMetaDataRepo.Save, error: %w", err), releaseErr)
+	}
+
+	// deleted only once the metadata points to the blob
+	err = port.storageService.DeleteFile(uploadedObject)
+	if err != nil {
+		return fmt.Errorf("error at storageService.DeleteFile. error: %w", err)
 	}
 
 	return nil
 }
 
-func (port *domainFileService) CreateForUpload(path string, hashSeeds []string) (*domain.FileMetaData, string, error) {
+func (port *domainFileService) StartMultipartUpload(hash string, personIdRequester string, size int64) (*domain.FileMultipartUpload, error) {
+	fileMetaData, err := port.mustGetPendingUpload(hash, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
 
-	hashPlain := ""
-	for i, seed := range hashSeeds {
-		if i == 0 {
-			hashPlain = seed
-		} else {
-			hashPlain = hashPlain + "-" + seed
+	if fileMetaData.MultipartUpload != nil {
+		if fileMetaData.MultipartUpload.Size == size {
+			return fileMetaData.MultipartUpload, nil
+		}
+		// the client changed the file, the parts already uploaded are useless
+		err = port.storageService.AbortMultipartUpload(*fileMetaData, fileMetaData.MultipartUpload.UploadId)
+		if err != nil {
+			return nil, fmt.Errorf("error at storageService.AbortMultipartUpload. error: %w", err)
 		}
 	}
 
-	hashBcrypt, err := hashutil.BCryptHash(hashPlain)
+	maxSize := domain.MaxFileSize(fileMetaData.TypeCode)
+	if maxSize > 0 && size > maxSize {
+		return nil, exception.NewFileTooLargeException(hash, size, maxSize)
+	}
+
+	uploadId, err := port.storageService.InitiateMultipartUpload(*fileMetaData)
+	if err != nil {
+		if errors.Is(err, repository.ErrMultipartUploadNotSupported) {
+			return nil, exception.NewMultipartUploadNotSupportedException(port.storageService.GetStorageType())
+		}
+		return nil, fmt.Errorf("error at storageService.InitiateMultipartUpload. error: %w", err)
+	}
+
+	partSize := domain.MultipartPartSize(size)
+	fileMetaData.MultipartUpload = &domain.FileMultipartUpload{
+		UploadId:       uploadId,
+		Size:           size,
+		PartSize:       partSize,
+		PartCount:      domain.MultipartPartCount(size, partSize),
+		CompletedParts: []domain.FileUploadPart{},
+		StartDate:      time.Now(),
+	}
+
+	_, err = port.fileMetaDataRepo.Save(*fileMetaData)
+	if err != nil {
+		return nil, fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
+	}
+	return fileMetaData.MultipartUpload, nil
+}
+
+func (port *domainFileService) GetMultipartPartUploadUrl(hash string, personIdRequester string, partNumber int) (string, error) {
+	fileMetaData, err := port.mustGetMultipartUpload(hash, personIdRequester, partNumber)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := port.storageService.GetMultipartUploadPartUrl(*fileMetaData, fileMetaData.MultipartUpload.UploadId, partNumber)
+	if err != nil {
+		return "", fmt.Errorf("error at storageService.GetMultipartUploadPartUrl. error: %w", err)
+	}
+	return url, nil
+}
+
+// RegisterMultipartPart saves the etag of an uploaded part, a part uploaded again replaces it.
+func (port *domainFileService) RegisterMultipartPart(hash string, personIdRequester string, part domain.FileUploadPart) error {
+	fileMetaData, err := port.mustGetMultipartUpload(hash, personIdRequester, part.PartNumber)
+	if err != nil {
+		return err
+	}
+
+	saved, err := port.fileMetaDataRepo.SaveMultipartPart(hash, fileMetaData.MultipartUpload.UploadId, part)
+	if err != nil {
+		return fmt.Errorf("error at fileMetaDataRepo.SaveMultipartPart. error: %w", err)
+	}
+	if !saved {
+		// completed or aborted meanwhile
+		return exception.NewMultipartUploadNotStartedException(hash)
+	}
+	return nil
+}
+
+func (port *domainFileService) CompleteMultipartUpload(hash string, personIdRequester string) error {
+	fileMetaData, err := port.mustGetMultipartUpload(hash, personIdRequester, 1)
+	if err != nil {
+		return err
+	}
+
+	upload := fileMetaData.MultipartUpload
+	missingParts := upload.MissingParts()
+	if len(missingParts) > 0 {
+		return exception.NewMultipartUploadIncompleteException(hash, missingParts)
+	}
+
+	parts := append([]domain.FileUploadPart{}, upload.CompletedParts...)
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+
+	err = port.storageService.CompleteMultipartUpload(*fileMetaData, upload.UploadId, parts)
+	if err != nil {
+		return fmt.Errorf("error at storageService.CompleteMultipartUpload. error: %w", err)
+	}
+
+	fileMetaData.MultipartUpload = nil
+	_, err = port.fileMetaDataRepo.Save(*fileMetaData)
+	if err != nil {
+		return fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
+	}
+
+	return port.ConfirmUploaded(hash, personIdRequester)
+}
+
+func (port *domainFileService) AbortMultipartUpload(hash string, personIdRequester string) error {
+	fileMetaData, err := port.mustGetPendingUpload(hash, personIdRequester)
+	if err != nil {
+		return err
+	}
+	if fileMetaData.MultipartUpload == nil {
+		return nil
+	}
+
+	err = port.storageService.AbortMultipartUpload(*fileMetaData, fileMetaData.MultipartUpload.UploadId)
+	if err != nil {
+		return fmt.Errorf("error at storageService.AbortMultipartUpload. error: %w", err)
+	}
+
+	fileMetaData.MultipartUpload = nil
+	_, err = port.fileMetaDataRepo.Save(*fileMetaData)
+	if err != nil {
+		return fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
+	}
+	return nil
+}
+
+func (port *domainFileService) CreateForUpload(path string, hashSeeds []string, typeCode string, uploaderPersonId string, minImageSize int) (*domain.FileMetaData, string, error) {
+
+	uploaderObjectId, err := primitive.ObjectIDFromHex(uploaderPersonId)
 	if err != nil {
-		return nil, "", fmt.Errorf("errot at generating BCryptHash for seed: %s, error: %w", hashPlain, err)
+		return nil, "", fmt.Errorf("invalid uploader person id: %s, error: %w", uploaderPersonId, err)
 	}
 
-	b64UrlEncodedHash := hashutil.B64UrlEncoding(hashBcrypt)
+	b64UrlEncodedHash, err := port.newFileHash(hashSeeds)
+	if err != nil {
+		return nil, "", err
+	}
 
 	fileMetaData, err := port.fileMetaDataRepo.Save(domain.FileMetaData{
-		Hash:     b64UrlEncodedHash,
-		Path:     path,
-		Uploaded: false,
+		Hash:             b64UrlEncodedHash,
+		Path:             path,
+		Uploaded:         false,
+		TypeCode:         typeCode,
+		StorageType:      port.storageService.GetStorageType(),
+		UploaderPersonId: &uploaderObjectId,
+		MinImageSize:     minImageSize,
 	})
 	if err != nil {
 		return nil, "", fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
@@ -87,6 +340,88 @@ func (port *domainFileService) CreateForUpload(path string, hashSeeds []string)
 	return fileMetaData, uploadUrl, nil
 }
 
+// CreatePreview registers a file that the backend generates from the source file
+// when the source upload is confirmed, the previews are always jpeg.
+func (port *domainFileService) CreatePreview(sourceHash string, previewKind string, path string, hashSeeds []string) (*domain.FileMetaData, error) {
+	sourceMetaData, err := port.mustGetOneByHash(sourceHash)
+	if err != nil {
+		return nil, err
+	}
+
+	previewHash, err := port.newFileHash(append(hashSeeds, previewKind))
+	if err != nil {
+		return nil, err
+	}
+
+	previewMetaData, err := port.fileMetaDataRepo.Save(domain.FileMetaData{
+		Hash:           previewHash,
+		Path:           path,
+		Uploaded:       false,
+		TypeCode:       domain.PackItemTypeCodeImgJpg,
+		StorageType:    port.storageService.GetStorageType(),
+		SourceFileHash: &sourceHash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
+	}
+
+	if sourceMetaData.Previews == nil {
+		sourceMetaData.Previews = map[string]string{}
+	}
+	sourceMetaData.Previews[previewKind] = previewHash
+	_, err = port.fileMetaDataRepo.Save(*sourceMetaData)
+	if err != nil {
+		return nil, fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
+	}
+
+	return previewMetaData, nil
+}
+
+// CreateGenerated stores a file generated by the backend from other file, it is already uploaded.
+func (port *domainFileService) CreateGenerated(sourceHash string, path string, hashSeeds []string, typeCode string, content []byte, contentType string) (*domain.FileMetaData, error) {
+	hash, err := port.newFileHash(hashSeeds)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMetaData := domain.FileMetaData{
+		Hash:           hash,
+		Path:           path,
+		TypeCode:       typeCode,
+		StorageType:    port.storageService.GetStorageType(),
+		SourceFileHash: &sourceHash,
+	}
+
+	err = port.storageService.PutFile(fileMetaData, content, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("error at storageService.PutFile. error: %w", err)
+	}
+
+	uploadedDate := time.Now()
+	fileMetaData.Uploaded = true
+	fileMetaData.Size = int64(len(content))
+	fileMetaData.ContentType = contentType
+	fileMetaData.Checksum = "sha256:" + hashutil.SHA256HexEncoding(string(content))
+	fileMetaData.UploadedDate = &uploadedDate
+
+	savedMetaData, err := port.fileMetaDataRepo.Save(fileMetaData)
+	if err != nil {
+		return nil, fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
+	}
+	return savedMetaData, nil
+}
+
+func (port *domainFileService) ReadContent(hash string) (io.ReadCloser, error) {
+	fileMetaData, err := port.mustGetOneByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !fileMetaData.Uploaded {
+		return nil, exception.NewFileNotUploadedException(hash)
+	}
+	return port.storageService.ReadFile(*fileMetaData)
+}
+
 func (port *domainFileService) Delete(hash string) error {
 
 	fileMetaData, err := port.mustGetOneByHash(hash)
@@ -94,9 +429,13 @@ func (port *domainFileService) Delete(hash string) error {
 		return err
 	}
 
-	err = port.storageService.DeleteFile(*fileMetaData)
+	if fileMetaData.ContentDigest != "" {
+		err = port.releaseBlob(fileMetaData)
+	} else {
+		err = port.deleteOwnObject(fileMetaData)
+	}
 	if err != nil {
-		return fmt.Errorf("error at storageService.DeleteFile. error: %w", err)
+		return err
 	}
 
 	err = port.fileMetaDataRepo.Delete(*fileMetaData.Id)
@@ -117,6 +456,10 @@ func (port *domainFileService) GetDownloadUrl(hash string) (string, error) {
 		return "", err
 	}
 
+	if !fileMetaData.Uploaded {
+		return "", exception.NewFileNotUploadedException(hash)
+	}
+
 	if fileMetaData.DownloadUrl != nil {
 		return *fileMetaData.DownloadUrl, nil
 	}
@@ -136,8 +479,323 @@ func (port *domainFileService) GetDownloadUrl(hash string) (string, error) {
 	return downloadUrl, nil
 }
 
+// GetTemporaryDownloadUrl generates a new short-lived url on every call, it is never
+// cached in the file metadata.
+func (port *domainFileService) GetTemporaryDownloadUrl(hash string) (string, error) {
+	fileMetaData, err := port.mustGetOneByHash(hash)
+	if err != nil {
+		return "", err
+	}
+
+	if !fileMetaData.Uploaded {
+		return "", exception.NewFileNotUploadedException(hash)
+	}
+
+	downloadUrl, err := port.storageService.GetFileTemporaryDownloadUrl(*fileMetaData, temporaryDownloadUrlExpiration)
+	if err != nil {
+		return "", fmt.Errorf("error at storageService.GetFileTemporaryDownloadUrl. error: %w", err)
+	}
+
+	return downloadUrl, nil
+}
+
 // private
 
+func (port *domainFileService) newFileHash(hashSeeds []string) (string, error) {
+	hashPlain := ""
+	for i, seed := range hashSeeds {
+		if i == 0 {
+			hashPlain = seed
+		} else {
+			hashPlain = hashPlain + "-" + seed
+		}
+	}
+
+	hashBcrypt, err := hashutil.BCryptHash(hashPlain)
+	if err != nil {
+		return "", fmt.Errorf("errot at generating BCryptHash for seed: %s, error: %w", hashPlain, err)
+	}
+
+	return hashutil.B64UrlEncoding(hashBcrypt), nil
+}
+
+// storeContentAddressed copies the uploaded object to the blob of its content, when other
+// file already has the same content the blob is shared. The uploaded object is kept, the
+// caller deletes it after saving the metadata.
+func (port *domainFileService) storeContentAddressed(fileMetaData *domain.FileMetaData, stat *domain.FileStat) error {
+	digest, err := port.contentDigest(fileMetaData, stat)
+	if err != nil {
+		return err
+	}
+
+	blob, err := port.fileBlobRepo.AcquireBlob(domain.FileBlob{
+		Digest:      digest,
+		StorageType: port.storageService.ResolveStorageType(*fileMetaData),
+		Path:        domain.FileBlobPath(digest, path.Ext(fileMetaData.Path)),
+		Size:        stat.Size,
+	})
+	if err != nil {
+		return fmt.Errorf("error at fileBlobRepo.AcquireBlob. error: %w", err)
+	}
+	if blob.DeletingDate != nil {
+		return exception.NewFileBlobDeletingException(digest)
+	}
+
+	blobMetaData := *fileMetaData
+	blobMetaData.Path = blob.Path
+	blobStat, err := port.storageService.StatFile(blobMetaData)
+	if err == nil && blobStat == nil {
+		err = port.storageService.CopyFile(*fileMetaData, blobMetaData)
+	}
+	if err != nil {
+		blobMetaData.ContentDigest = digest
+		releaseErr := port.releaseBlob(&blobMetaData)
+		return errors.Join(fmt.Errorf("error storing the blob %s. error: %w", digest, err), releaseErr)
+	}
+
+	fileMetaData.Path = blob.Path
+	fileMetaData.ContentDigest = digest
+	return nil
+}
+
+// contentDigest uses the checksum of the storage when it is a sha256 (local storage and
+// sanitized images), otherwise reads the file.
+func (port *domainFileService) contentDigest(fileMetaData *domain.FileMetaData, stat *domain.FileStat) (string, error) {
+	if digest, found := strings.CutPrefix(stat.Checksum, "sha256:"); found {
+		return digest, nil
+	}
+
+	reader, err := port.storageService.ReadFile(*fileMetaData)
+	if err != nil {
+		return "", fmt.Errorf("error at storageService.ReadFile. error: %w", err)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, reader)
+	if err != nil {
+		return "", fmt.Errorf("error reading file %s. error: %w", fileMetaData.Hash, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// releaseBlob the object is deleted only when no other file references it.
+func (port *domainFileService) releaseBlob(fileMetaData *domain.FileMetaData) error {
+	return releaseFileBlob(port.fileBlobRepo, port.storageService, *fileMetaData, true)
+}
+
+// releaseFileBlob removes a reference of the blob of the file, the last one deletes the object
+// (with deleteObject) and then the record. The tombstone set before blocks the acquires, so a
+// new blob of the same digest never finds the object that is being deleted.
+func releaseFileBlob(fileBlobRepo repository.FileBlobRepository, storageService StorageService, fileMetaData domain.FileMetaData, deleteObject bool) error {
+	blob, err := fileBlobRepo.ReleaseBlob(fileMetaData.ContentDigest, storageService.ResolveStorageType(fileMetaData))
+	if err != nil {
+		return fmt.Errorf("error at fileBlobRepo.ReleaseBlob. error: %w", err)
+	}
+	if blob == nil {
+		return nil
+	}
+
+	// a tombstone already set is of a deletion interrupted, of this same file
+	if blob.DeletingDate == nil {
+		if blob.RefCount > 0 {
+			return nil
+		}
+		marked, err := fileBlobRepo.MarkDeleting(*blob.Id)
+		if err != nil {
+			return fmt.Errorf("error at fileBlobRepo.MarkDeleting. error: %w", err)
+		}
+		if !marked {
+			return nil
+		}
+	}
+
+	if deleteObject {
+		fileMetaData.Path = blob.Path
+		err = storageService.DeleteFile(fileMetaData)
+		if err != nil {
+			return fmt.Errorf("error at storageService.DeleteFile. error: %w", err)
+		}
+	}
+
+	err = fileBlobRepo.Delete(*blob.Id)
+	if err != nil {
+		return fmt.Errorf("error at fileBlobRepo.Delete. error: %w", err)
+	}
+	return nil
+}
+
+// deleteOwnObject deletes the object of a file not stored as blob, the pending uploads can
+// have a multipart upload in progress or no object at all.
+func (port *domainFileService) deleteOwnObject(fileMetaData *domain.FileMetaData) error {
+	if fileMetaData.MultipartUpload != nil {
+		err := port.storageService.AbortMultipartUpload(*fileMetaData, fileMetaData.MultipartUpload.UploadId)
+		if err != nil {
+			return fmt.Errorf("error at storageService.AbortMultipartUpload. error: %w", err)
+		}
+	}
+
+	if !fileMetaData.Uploaded {
+		stat, err := port.storageService.StatFile(*fileMetaData)
+		if err != nil {
+			return fmt.Errorf("error at storageService.StatFile. error: %w", err)
+		}
+		if stat == nil {
+			return nil
+		}
+	}
+
+	err := port.storageService.DeleteFile(*fileMetaData)
+	if err != nil {
+		return fmt.Errorf("error at storageService.DeleteFile. error: %w", err)
+	}
+	return nil
+}
+
+// sanitizeImage replaces the stored object with the image re-encoded without metadata and
+// with the exif orientation applied to the pixels, so the gps position or the camera
+// serial of the uploader are never served.
+func (port *domainFileService) sanitizeImage(fileMetaData *domain.FileMetaData) (*domain.FileStat, error) {
+	reader, err := port.storageService.ReadFile(*fileMetaData)
+	if err != nil {
+		return nil, fmt.Errorf("error at storageService.ReadFile. error: %w", err)
+	}
+	defer reader.Close()
+
+	// the size was already checked against the limit of the type
+	original, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s. error: %w", fileMetaData.Hash, err)
+	}
+
+	decoded, _, err := imageutil.Decode(bytes.NewReader(original))
+	if err != nil {
+		return nil, exception.NewFileTypeMismatchException(fileMetaData.Hash, fileMetaData.TypeCode)
+	}
+	oriented := imageutil.Orient(decoded, imageutil.ReadOrientation(original))
+
+	bounds := oriented.Bounds()
+	if bounds.Dx() < fileMetaData.MinImageSize || bounds.Dy() < fileMetaData.MinImageSize {
+		return nil, exception.NewImageTooSmallException(fileMetaData.Hash, bounds.Dx(), bounds.Dy(), fileMetaData.MinImageSize)
+	}
+
+	var content []byte
+	var contentType string
+	if fileMetaData.TypeCode == domain.PackItemTypeCodeImgPng {
+		content, err = imageutil.EncodePng(oriented)
+		contentType = "image/png"
+	} else {
+		content, err = imageutil.EncodeJpeg(oriented, sanitizedJpegQuality)
+		contentType = "image/jpeg"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = port.storageService.PutFile(*fileMetaData, content, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("error at storageService.PutFile. error: %w", err)
+	}
+
+	return &domain.FileStat{
+		Size:        int64(len(content)),
+		ContentType: contentType,
+		Checksum:    "sha256:" + hashutil.SHA256HexEncoding(string(content)),
+	}, nil
+}
+
+// generatePreviews the images are resized (and pixelated for the locked preview),
+// the videos get a placeholder because there is no pure go video decoder.
+func (port *domainFileService) generatePreviews(source *domain.FileMetaData) error {
+	if len(source.Previews) == 0 {
+		return nil
+	}
+
+	var thumbnail image.Image
+	if isImageTypeCode(source.TypeCode) {
+		reader, err := port.storageService.ReadFile(*source)
+		if err != nil {
+			return fmt.Errorf("error at storageService.ReadFile. error: %w", err)
+		}
+		defer reader.Close()
+		sourceImage, _, err := imageutil.Decode(reader)
+		if err != nil {
+			return exception.NewFileTypeMismatchException(source.Hash, source.TypeCode)
+		}
+		thumbnail = imageutil.Resize(sourceImage, previewWidth)
+	} else {
+		thumbnail = imageutil.VideoPlaceholder(previewWidth, previewWidth*9/16)
+	}
+
+	for previewKind, previewHash := range source.Previews {
+		previewMetaData, err := port.mustGetOneByHash(previewHash)
+		if err != nil {
+			return err
+		}
+
+		previewImage := thumbnail
+		if previewKind == domain.FilePreviewLocked {
+			previewImage = imageutil.Pixelate(thumbnail, lockedPreviewPixelSize)
+		}
+
+		content, err := imageutil.EncodeJpeg(previewImage, previewJpegQuality)
+		if err != nil {
+			return err
+		}
+
+		err = port.storageService.PutFile(*previewMetaData, content, "image/jpeg")
+		if err != nil {
+			return fmt.Errorf("error at storageService.PutFile. error: %w", err)
+		}
+
+		uploadedDate := time.Now()
+		previewMetaData.Uploaded = true
+		previewMetaData.Size = int64(len(content))
+		previewMetaData.ContentType = "image/jpeg"
+		previewMetaData.Checksum = "sha256:" + hashutil.SHA256HexEncoding(string(content))
+		previewMetaData.UploadedDate = &uploadedDate
+
+		_, err = port.fileMetaDataRepo.Save(*previewMetaData)
+		if err != nil {
+			return fmt.Errorf("error at fileMetaDataRepo.Save. error: %w", err)
+		}
+	}
+	return nil
+}
+
+// mustGetPendingUpload returns the file not uploaded yet, only for the person who requested the upload.
+func (port *domainFileService) mustGetPendingUpload(hash string, personIdRequester string) (*domain.FileMetaData, error) {
+	fileMetaData, err := port.mustGetOneByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if fileMetaData.UploaderPersonId == nil || fileMetaData.UploaderPersonId.Hex() != personIdRequester {
+		return nil, &FileAccessDeniedError{hash}
+	}
+	if fileMetaData.Uploaded {
+		return nil, fmt.Errorf("the file with hash %s is already uploaded", hash)
+	}
+	return fileMetaData, nil
+}
+
+func (port *domainFileService) mustGetMultipartUpload(hash string, personIdRequester string, partNumber int) (*domain.FileMetaData, error) {
+	fileMetaData, err := port.mustGetPendingUpload(hash, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+	if fileMetaData.MultipartUpload == nil {
+		return nil, exception.NewMultipartUploadNotStartedException(hash)
+	}
+	if partNumber < 1 || partNumber > fileMetaData.MultipartUpload.PartCount {
+		return nil, exception.NewInvalidMultipartPartException(hash, partNumber)
+	}
+	return fileMetaData, nil
+}
+
+func isImageTypeCode(typeCode string) bool {
+	return typeCode == domain.PackItemTypeCodeImgJpg || typeCode == domain.PackItemTypeCodeImgPng
+}
+
 func (port *domainFileService) mustGetOneByHash(hash string) (*domain.FileMetaData, error) {
 	fileMetaData, err := port.fileMetaDataRepo.FindByHash(hash)
 	if err != nil {
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/util/hashutil"
+	"github.com/erodriguezg/meet/pkg/util/imageutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testStorageType = "memory"
+
+type testFileService struct {
+	FileService
+	storage          *fakeStorageRepository
+	fileMetaDataRepo *fakeFileMetaDataRepository
+	fileBlobRepo     *fakeFileBlobRepository
+	uploaderId       string
+}
+
+func newTestFileService() *testFileService {
+	storage := newFakeStorageRepository(testStorageType)
+	fileMetaDataRepo := newFakeFileMetaDataRepository()
+	fileBlobRepo := newFakeFileBlobRepository()
+	fileService := NewFileService(NewDomainStorageService(storage, ""), fileMetaDataRepo, fileBlobRepo)
+	return &testFileService{fileService, storage, fileMetaDataRepo, fileBlobRepo, primitive.NewObjectID().Hex()}
+}
+
+// upload creates the file for upload and puts the content in its upload path
+func (port *testFileService) upload(t *testing.T, path string, content string) *domain.FileMetaData {
+	fileMetaData, _, err := port.CreateForUpload(path, []string{path}, "", port.uploaderId, 0)
+	require.NoError(t, err)
+	port.storage.objects[path] = []byte(content)
+	return fileMetaData
+}
+
+func TestConfirmUploadedSharesTheBlob(t *testing.T) {
+	fileService := newTestFileService()
+	first := fileService.upload(t, "uploads/first.txt", "same content")
+	second := fileService.upload(t, "uploads/second.txt", "same content")
+
+	require.NoError(t, fileService.ConfirmUploaded(first.Hash, fileService.uploaderId))
+	require.NoError(t, fileService.ConfirmUploaded(second.Hash, fileService.uploaderId))
+
+	digest := hashutil.SHA256HexEncoding("same content")
+	blob := fileService.fileBlobRepo.find(testStorageType, digest)
+	require.NotNil(t, blob)
+	assert.Equal(t, 2, blob.RefCount)
+
+	// only the blob is kept
+	assert.Len(t, fileService.storage.objects, 1)
+	assert.Contains(t, fileService.storage.objects, blob.Path)
+
+	confirmed, _ := fileService.FindByHash(second.Hash)
+	assert.True(t, confirmed.Uploaded)
+	assert.Equal(t, blob.Path, confirmed.Path)
+	assert.Equal(t, digest, confirmed.ContentDigest)
+}
+
+func TestConfirmUploadedFailedSaveCanBeRetried(t *testing.T) {
+	fileService := newTestFileService()
+	file := fileService.upload(t, "uploads/file.txt", "content")
+
+	fileService.fileMetaDataRepo.saveErr = errors.New("connection lost")
+	err := fileService.ConfirmUploaded(file.Hash, fileService.uploaderId)
+	require.Error(t, err)
+
+	// the upload is kept and the reference released
+	assert.Contains(t, fileService.storage.objects, "uploads/file.txt")
+	assert.Nil(t, fileService.fileBlobRepo.find(testStorageType, hashutil.SHA256HexEncoding("content")))
+
+	require.NoError(t, fileService.ConfirmUploaded(file.Hash, fileService.uploaderId))
+	blob := fileService.fileBlobRepo.find(testStorageType, hashutil.SHA256HexEncoding("content"))
+	require.NotNil(t, blob)
+	assert.Equal(t, 1, blob.RefCount)
+	assert.NotContains(t, fileService.storage.objects, "uploads/file.txt")
+}
+
+func TestDeleteLastReferenceDeletesTheBlob(t *testing.T) {
+	fileService := newTestFileService()
+	first := fileService.upload(t, "uploads/first.txt", "same content")
+	second := fileService.upload(t, "uploads/second.txt", "same content")
+	require.NoError(t, fileService.ConfirmUploaded(first.Hash, fileService.uploaderId))
+	require.NoError(t, fileService.ConfirmUploaded(second.Hash, fileService.uploaderId))
+	digest := hashutil.SHA256HexEncoding("same content")
+
+	require.NoError(t, fileService.Delete(first.Hash))
+	assert.Len(t, fileService.storage.objects, 1)
+	assert.Equal(t, 1, fileService.fileBlobRepo.find(testStorageType, digest).RefCount)
+
+	require.NoError(t, fileService.Delete(second.Hash))
+	assert.Empty(t, fileService.storage.objects)
+	assert.Nil(t, fileService.fileBlobRepo.find(testStorageType, digest))
+}
+
+func TestConfirmUploadedBlobBeingDeleted(t *testing.T) {
+	fileService := newTestFileService()
+	digest := hashutil.SHA256HexEncoding("content")
+	deletingDate := time.Now()
+	id := primitive.NewObjectID()
+	fileService.fileBlobRepo.blobs[testStorageType+"/"+digest] = domain.FileBlob{
+		Id:           &id,
+		Digest:       digest,
+		StorageType:  testStorageType,
+		Path:         domain.FileBlobPath(digest, ".txt"),
+		DeletingDate: &deletingDate,
+	}
+	file := fileService.upload(t, "uploads/file.txt", "content")
+
+	err := fileService.ConfirmUploaded(file.Hash, fileService.uploaderId)
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	// the upload is kept for retry when the deletion ends
+	assert.Contains(t, fileService.storage.objects, "uploads/file.txt")
+	unconfirmed, _ := fileService.FindByHash(file.Hash)
+	assert.False(t, unconfirmed.Uploaded)
+}
+
+func TestReleaseFileBlobInterruptedDeletion(t *testing.T) {
+	fileService := newTestFileService()
+	file := fileService.upload(t, "uploads/file.txt", "content")
+	require.NoError(t, fileService.ConfirmUploaded(file.Hash, fileService.uploaderId))
+	digest := hashutil.SHA256HexEncoding("content")
+
+	// the tombstone was set but the object and the record were not deleted
+	blob := fileService.fileBlobRepo.find(testStorageType, digest)
+	blob.RefCount = 0
+	deletingDate := time.Now()
+	blob.DeletingDate = &deletingDate
+	fileService.fileBlobRepo.blobs[testStorageType+"/"+digest] = *blob
+
+	require.NoError(t, fileService.Delete(file.Hash))
+	assert.Empty(t, fileService.storage.objects)
+	assert.Nil(t, fileService.fileBlobRepo.find(testStorageType, digest))
+}
+
+func TestConfirmUploadedRejectsImageSmallerThanMinSize(t *testing.T) {
+	fileService := newTestFileService()
+	content, err := imageutil.EncodePng(image.NewGray(image.Rect(0, 0, 100, 80)))
+	require.NoError(t, err)
+
+	small, _, err := fileService.CreateForUpload("uploads/small.png", []string{"small"}, domain.PackItemTypeCodeImgPng, fileService.uploaderId, 90)
+	require.NoError(t, err)
+	fileService.storage.objects["uploads/small.png"] = content
+	err = fileService.ConfirmUploaded(small.Hash, fileService.uploaderId)
+	assert.IsType(t, &exception.BusinessException{}, err)
+	unconfirmed, _ := fileService.FindByHash(small.Hash)
+	assert.False(t, unconfirmed.Uploaded)
+
+	large, _, err := fileService.CreateForUpload("uploads/large.png", []string{"large"}, domain.PackItemTypeCodeImgPng, fileService.uploaderId, 80)
+	require.NoError(t, err)
+	fileService.storage.objects["uploads/large.png"] = content
+	require.NoError(t, fileService.ConfirmUploaded(large.Hash, fileService.uploaderId))
+}
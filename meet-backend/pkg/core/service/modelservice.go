@@ -2,33 +2,75 @@ package service
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/dto"
 	"github.com/erodriguezg/meet/pkg/core/exception"
 	"github.com/erodriguezg/meet/pkg/core/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
 )
 
 type ModelService interface {
 	SearchModels(filters domain.FilterSearchModel, first int, last int) (*domain.SearchModelResponse, error)
 	FindModelByPersonId(personId string) (*domain.Model, error)
 	RegisterModel(registerData dto.ModelRegisterDto) error
+
+	// FindModelByNickName also resolves the previous nicknames, so the old links keep working
 	FindModelByNickName(modelNickName string) (*domain.Model, error)
-	PrepareUploadUrlForProfileImage(modelNickName string) ([]dto.ResourceUploadUrlDto, error)
+	FindModelById(modelId string) (*domain.Model, error)
+
+	// FindModelByProfileImageFileHash the model with the file as its current profile image
+	// (or its thumbnail)
+	FindModelByProfileImageFileHash(fileHash string) (*domain.Model, error)
+
+	// UpdateModelProfile replaces the editable fields of the profile, only the person of the
+	// model can edit it
+	UpdateModelProfile(modelNickName string, data dto.ModelProfileUpdateDto, personIdRequester string) (*domain.Model, error)
+
+	// PrepareUploadUrlForProfileImage the new image is pending until it is confirmed, the
+	// current image is kept meanwhile
+	PrepareUploadUrlForProfileImage(modelNickName string, personIdRequester string) ([]dto.ResourceUploadUrlDto, error)
+
+	// ConfirmProfileImage confirms the upload of the pending image, it replaces the current
+	// image and the files of the current image are deleted
+	ConfirmProfileImage(modelNickName string, personIdRequester string) (*domain.Model, error)
+
+	// ChangeNickName the nickname can be changed once by cooldown, the previous nickname is
+	// kept in the history and reserved to the model for a period
+	ChangeNickName(modelNickName string, newNickName string, personIdRequester string, actor domain.AuditActor) (*domain.Model, error)
 }
 
 type domainModelService struct {
 	personService PersonService
 	fileService   FileService
+	auditService  AuditService
 	repository    repository.ModelRepository
+	// time between nickname changes and time the previous nicknames are reserved
+	nickNameChangeCooldown time.Duration
+	nickNameReservation    time.Duration
+	log                    *zap.Logger
 }
 
 func NewDomainModelService(
 	personService PersonService,
 	fileService FileService,
-	repository repository.ModelRepository) ModelService {
-	return &domainModelService{personService, fileService, repository}
+	auditService AuditService,
+	repository repository.ModelRepository,
+	nickNameChangeCooldown time.Duration,
+	nickNameReservation time.Duration,
+	log *zap.Logger) ModelService {
+	return &domainModelService{
+		personService,
+		fileService,
+		auditService,
+		repository,
+		nickNameChangeCooldown,
+		nickNameReservation,
+		log,
+	}
 }
 
 func (port *domainModelService) SearchModels(filters domain.FilterSearchModel, first int, last int) (*domain.SearchModelResponse, error) {
@@ -67,15 +109,11 @@ func (port *domainModelService) RegisterModel(registerData dto.ModelRegisterDto)
 
 	// check if model nick name is available
 
-	existingModel, err = port.repository.FindModelByNickName(registerData.NickName)
+	err = port.mustBeNickNameAvailable(registerData.NickName, nil)
 	if err != nil {
 		return err
 	}
 
-	if existingModel != nil {
-		return exception.NewModelNickNameNotAvailable(registerData.NickName)
-	}
-
 	// update person
 
 	person, err := port.personService.FindById(registerData.PersonId)
@@ -89,7 +127,7 @@ func (port *domainModelService) RegisterModel(registerData dto.ModelRegisterDto)
 
 	person.ProfileCode = domain.ProfileCodeModel
 
-	_, err = port.personService.Save(*person)
+	_, err = port.personService.Save(*person, domain.AuditActor{PersonId: registerData.PersonId})
 	if err != nil {
 		return err
 	}
@@ -114,33 +152,98 @@ func (port *domainModelService) FindModelByNickName(modelNickName string) (*doma
 	if err != nil {
 		return nil, fmt.Errorf("error at FindModelByNickName. Model: %s, error: %w", modelNickName, err)
 	}
+	if model != nil {
+		return model, nil
+	}
+
+	// after the reservation the nickname can be used by other model, the last one that used
+	// it keeps resolving it meanwhile
+	previousModels, err := port.repository.FindModelsByPreviousNickName(modelNickName)
+	if err != nil {
+		return nil, fmt.Errorf("error at FindModelByNickName. Model: %s, error: %w", modelNickName, err)
+	}
+	var lastChangedDate time.Time
+	for i := range previousModels {
+		changedDate := previousModels[i].PreviousNickNameChangedDate(modelNickName)
+		if changedDate != nil && changedDate.After(lastChangedDate) {
+			model = &previousModels[i]
+			lastChangedDate = *changedDate
+		}
+	}
+	return model, nil
+}
+
+func (port *domainModelService) FindModelByProfileImageFileHash(fileHash string) (*domain.Model, error) {
+	model, err := port.repository.FindModelByProfileImageFileHash(fileHash)
+	if err != nil {
+		return nil, fmt.Errorf("error at FindModelByProfileImageFileHash. fileHash: %s, error: %w", fileHash, err)
+	}
 	return model, nil
 }
 
-func (port *domainModelService) PrepareUploadUrlForProfileImage(modelNickName string) ([]dto.ResourceUploadUrlDto, error) {
+func (port *domainModelService) FindModelById(modelId string) (*domain.Model, error) {
+	model, err := port.repository.FindModelById(modelId)
+	if err != nil {
+		return nil, fmt.Errorf("error at FindModelById. Model: %s, error: %w", modelId, err)
+	}
+	return model, nil
+}
 
-	model, err := port.mustGetModelByNickName(modelNickName)
+func (port *domainModelService) UpdateModelProfile(modelNickName string, data dto.ModelProfileUpdateDto, personIdRequester string) (*domain.Model, error) {
+
+	model, err := port.mustGetOwnedModel(modelNickName, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+
+	zodiacSignCode := emptyToNil(data.ZodiacSignCode)
+	if zodiacSignCode != nil {
+		*zodiacSignCode = strings.ToLower(*zodiacSignCode)
+		if !domain.IsZodiacSignCode(*zodiacSignCode) {
+			return nil, exception.NewInvalidZodiacSignException(*zodiacSignCode)
+		}
+	}
+	model.AboutMe = emptyToNil(data.AboutMe)
+	model.CountryCode = emptyToNil(data.CountryCode)
+	model.City = emptyToNil(data.City)
+	model.ZodiacSignCode = zodiacSignCode
+
+	savedModel, err := port.repository.SaveModel(*model)
+	if err != nil {
+		return nil, fmt.Errorf("error at SaveModel for UpdateModelProfile. error: %w", err)
+	}
+	return savedModel, nil
+}
+
+func (port *domainModelService) PrepareUploadUrlForProfileImage(modelNickName string, personIdRequester string) ([]dto.ResourceUploadUrlDto, error) {
+
+	model, err := port.mustGetOwnedModel(modelNickName, personIdRequester)
 	if err != nil {
 		return nil, err
 	}
 
 	modelIdHex := model.Id.Hex()
 
-	pathNormalFile := fmt.Sprintf("models/%s/profile-img.png", modelIdHex)
-	pathThumbnailFile := fmt.Sprintf("models/%s/profile-img-thumbnail.png", modelIdHex)
+	// a path by upload, the current image is kept until the new one is confirmed
+	actualDateFormat := time.Now().Format("20060102150405")
+	pathNormalFile := fmt.Sprintf("models/%s/profile-img-%s.png", modelIdHex, actualDateFormat)
+	pathThumbnailFile := fmt.Sprintf("models/%s/profile-img-thumbnail-%s.png", modelIdHex, actualDateFormat)
 
-	profileImageFile, normalUploadUrl, err := port.fileService.CreateForUpload(pathNormalFile, []string{modelIdHex, modelNickName, "profileImage"})
+	profileImageFile, normalUploadUrl, err := port.fileService.CreateForUpload(pathNormalFile, []string{modelIdHex, modelNickName, "profileImage", actualDateFormat},
+		domain.PackItemTypeCodeImgPng, personIdRequester, 0)
 	if err != nil {
 		return nil, fmt.Errorf("errot at CreateForUpload. error: %w", err)
 	}
 
-	profileImageThumbnailFile, thumbnailUploadUrl, err := port.fileService.CreateForUpload(pathThumbnailFile, []string{modelIdHex, modelNickName, "profileImageThumbnail"})
+	profileImageThumbnailFile, thumbnailUploadUrl, err := port.fileService.CreateForUpload(pathThumbnailFile, []string{modelIdHex, modelNickName, "profileImageThumbnail", actualDateFormat},
+		domain.PackItemTypeCodeImgPng, personIdRequester, 0)
 	if err != nil {
 		return nil, fmt.Errorf("errot at CreateForUpload. error: %w", err)
 	}
 
-	model.ProfileImageFileHash = &profileImageFile.Hash
-	model.ProfileImageThumbnailFileHash = &profileImageThumbnailFile.Hash
+	// the files of a previous upload never confirmed are left to the file gc
+	model.PendingProfileImageFileHash = &profileImageFile.Hash
+	model.PendingProfileImageThumbnailFileHash = &profileImageThumbnailFile.Hash
 
 	_, err = port.repository.SaveModel(*model)
 	if err != nil {
@@ -150,13 +253,13 @@ func (port *domainModelService) PrepareUploadUrlForProfileImage(modelNickName st
 	return []dto.ResourceUploadUrlDto{
 		{
 			UploadUrl:   normalUploadUrl,
-			FileHash:    *model.ProfileImageFileHash,
+			FileHash:    profileImageFile.Hash,
 			IsThumbnail: false,
 			IsBlurred:   false,
 		},
 		{
 			UploadUrl:   thumbnailUploadUrl,
-			FileHash:    *model.ProfileImageThumbnailFileHash,
+			FileHash:    profileImageThumbnailFile.Hash,
 			IsThumbnail: true,
 			IsBlurred:   false,
 		},
@@ -164,6 +267,81 @@ func (port *domainModelService) PrepareUploadUrlForProfileImage(modelNickName st
 
 }
 
+func (port *domainModelService) ConfirmProfileImage(modelNickName string, personIdRequester string) (*domain.Model, error) {
+
+	model, err := port.mustGetOwnedModel(modelNickName, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.PendingProfileImageFileHash == nil || model.PendingProfileImageThumbnailFileHash == nil {
+		return nil, exception.NewModelProfileImageNotPendingException(modelNickName)
+	}
+
+	for _, hash := range []string{*model.PendingProfileImageFileHash, *model.PendingProfileImageThumbnailFileHash} {
+		err = port.fileService.ConfirmUploaded(hash, personIdRequester)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	previousHashes := nonNilStrings(model.ProfileImageFileHash, model.ProfileImageThumbnailFileHash)
+
+	model.ProfileImageFileHash = model.PendingProfileImageFileHash
+	model.ProfileImageThumbnailFileHash = model.PendingProfileImageThumbnailFileHash
+	model.PendingProfileImageFileHash = nil
+	model.PendingProfileImageThumbnailFileHash = nil
+
+	savedModel, err := port.repository.SaveModel(*model)
+	if err != nil {
+		return nil, fmt.Errorf("error at SaveModel for ConfirmProfileImage. error: %w", err)
+	}
+
+	// the new image is already saved, the files not deleted are collected by the file gc
+	for _, hash := range previousHashes {
+		err = port.fileService.Delete(hash)
+		if err != nil {
+			port.log.Warn("error deleting the previous profile image file",
+				zap.String("modelNickName", modelNickName), zap.String("hash", hash), zap.Error(err))
+		}
+	}
+
+	return savedModel, nil
+}
+
+func (port *domainModelService) ChangeNickName(modelNickName string, newNickName string, personIdRequester string, actor domain.AuditActor) (*domain.Model, error) {
+
+	model, err := port.mustGetOwnedModel(modelNickName, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+	if model.NickName == newNickName {
+		return model, nil
+	}
+
+	now := time.Now()
+	nextChangeDate := model.NextNickNameChangeDate(port.nickNameChangeCooldown)
+	if nextChangeDate != nil && now.Before(*nextChangeDate) {
+		return nil, exception.NewModelNickNameChangeCooldownException(model.NickName, *nextChangeDate)
+	}
+
+	err = port.mustBeNickNameAvailable(newNickName, model.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousModel := *model
+	model.ChangeNickName(newNickName, now)
+
+	savedModel, err := port.repository.SaveModel(*model)
+	if err != nil {
+		return nil, fmt.Errorf("error at SaveModel for ChangeNickName. error: %w", err)
+	}
+	port.auditService.Record(actor, domain.AuditActionModelChangeNickName, domain.AuditTargetModel,
+		model.Id.Hex(), &previousModel, savedModel)
+	return savedModel, nil
+}
+
 // private
 func (port *domainModelService) mustGetModelByNickName(modelNickName string) (*domain.Model, error) {
 	model, err := port.FindModelByNickName(modelNickName)
@@ -171,7 +349,67 @@ func (port *domainModelService) mustGetModelByNickName(modelNickName string) (*d
 		return nil, fmt.Errorf("error at FindModelByNickName. model: %s, error: %w", modelNickName, err)
 	}
 	if model == nil {
-		return nil, fmt.Errorf("model nickname %s does not exist", modelNickName)
+		return nil, exception.NewModelNotFoundException(modelNickName)
 	}
 	return model, nil
 }
+
+func (port *domainModelService) mustGetOwnedModel(modelNickName string, personIdRequester string) (*domain.Model, error) {
+	model, err := port.mustGetModelByNickName(modelNickName)
+	if err != nil {
+		return nil, err
+	}
+	if model.PersonId.Hex() != personIdRequester {
+		return nil, exception.NewModelNotOwnedException(modelNickName)
+	}
+	return model, nil
+}
+
+// mustBeNickNameAvailable the nickname is not used by other model, nor reserved by one that used
+// it before. The model (nil for a new one) can take again its own previous nicknames.
+func (port *domainModelService) mustBeNickNameAvailable(nickName string, modelId *primitive.ObjectID) error {
+	existingModel, err := port.repository.FindModelByNickName(nickName)
+	if err != nil {
+		return err
+	}
+	if existingModel != nil && !sameModel(existingModel, modelId) {
+		return exception.NewModelNickNameNotAvailable(nickName)
+	}
+
+	previousModels, err := port.repository.FindModelsByPreviousNickName(nickName)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range previousModels {
+		if !sameModel(&previousModels[i], modelId) && previousModels[i].ReservesNickName(nickName, port.nickNameReservation, now) {
+			return exception.NewModelNickNameNotAvailable(nickName)
+		}
+	}
+	return nil
+}
+
+func sameModel(model *domain.Model, modelId *primitive.ObjectID) bool {
+	return modelId != nil && model.Id != nil && *model.Id == *modelId
+}
+
+func emptyToNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func nonNilStrings(values ...*string) []string {
+	var result []string
+	for _, value := range values {
+		if value != nil {
+			result = append(result, *value)
+		}
+	}
+	return result
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/erodriguezg/meet/pkg/core/domain"
@@ -16,14 +17,20 @@ type PackPaymentMethodService interface {
 }
 
 type domainPPMSService struct {
-	packService PackService
-	repository  repository.PackPaymentMethodRepository
+	packService     PackService
+	currencyService CurrencyService
+	packRepository  repository.PackRepository
+	repository      repository.PackPaymentMethodRepository
 }
 
 func NewPackPaymentMethodService(packService PackService,
+	currencyService CurrencyService,
+	packRepository repository.PackRepository,
 	repository repository.PackPaymentMethodRepository) PackPaymentMethodService {
 	return &domainPPMSService{
 		packService,
+		currencyService,
+		packRepository,
 		repository,
 	}
 }
@@ -68,7 +75,6 @@ func (port *domainPPMSService) Save(modelNickName string, packNumber int, packPa
 		ChiliBankReceiptMethodEnabled: packPaymentMethodDTO.ChiliBankReceiptMethodEnabled,
 		PackId:                        *pack.Id,
 		ChiliBankReceiptAccountId:     chiliBankReceiptAccountId,
-		ChiliBankReceiptCLPPrice:      packPaymentMethodDTO.ChiliBankReceiptCLPPrice,
 		PaypalReceiptMethodEnabled:    packPaymentMethodDTO.PaypalReceiptMethodEnabled,
 		PaypalReceiptRecipientEmail:   packPaymentMethodDTO.PaypalReceiptRecipientEmail,
 		PaypalReceiptUSDPrice:         packPaymentMethodDTO.PaypalOnlineUSDPrice,
@@ -78,13 +84,19 @@ func (port *domainPPMSService) Save(modelNickName string, packNumber int, packPa
 		UpdateDate:                    updatedTime,
 	}
 
+	if packPaymentMethodDTO.ChiliBankReceiptCLPPrice != nil {
+		err = port.savePackCLPPrice(pack, *packPaymentMethodDTO.ChiliBankReceiptCLPPrice)
+		if err != nil {
+			return dto.PackPaymentMethodDTO{}, err
+		}
+	}
+
 	savedDomain, err := port.repository.Save(toUpdateDomain)
 	if err != nil {
 		return dto.PackPaymentMethodDTO{}, err
 	}
 
-	newDto := port.mapDomainToDTO(&savedDomain)
-	return newDto, nil
+	return port.mapDomainToDTO(pack, &savedDomain)
 
 }
 
@@ -105,14 +117,33 @@ func (port *domainPPMSService) GetFromPack(modelNickName string, packNumber int)
 	if packPaymentMethodFound == nil {
 		return nil, nil
 	} else {
-		dto := port.mapDomainToDTO(packPaymentMethodFound)
+		dto, err := port.mapDomainToDTO(pack, packPaymentMethodFound)
+		if err != nil {
+			return nil, err
+		}
 		return &dto, nil
 	}
 }
 
 // private
 
-func (port *domainPPMSService) mapDomainToDTO(packPaymentMethod *domain.PackPaymentMethod) dto.PackPaymentMethodDTO {
+// savePackCLPPrice the bank receipts are paid with the CLP price of the pack, the same price of
+// the other payments in CLP
+func (port *domainPPMSService) savePackCLPPrice(pack *domain.Pack, clpPrice int) error {
+	if clpPrice <= 0 {
+		return fmt.Errorf("invalid %s price: %d", domain.CurrencyCodeCLP, clpPrice)
+	}
+	prices := maps.Clone(pack.Prices)
+	if prices == nil {
+		prices = map[string]float64{}
+	}
+	prices[domain.CurrencyCodeCLP] = domain.RoundCurrency(float64(clpPrice), domain.CurrencyCodeCLP)
+	pack.Prices = prices
+	_, err := port.packRepository.SavePack(*pack)
+	return err
+}
+
+func (port *domainPPMSService) mapDomainToDTO(pack *domain.Pack, packPaymentMethod *domain.PackPaymentMethod) (dto.PackPaymentMethodDTO, error) {
 
 	var chiliBankReceiptAccountId *string
 	if packPaymentMethod.ChiliBankReceiptAccountId != nil {
@@ -120,10 +151,25 @@ func (port *domainPPMSService) mapDomainToDTO(packPaymentMethod *domain.PackPaym
 		chiliBankReceiptAccountId = &auxText
 	}
 
+	// the CLP price defined for the pack, or its dollar value converted with the CLP rate
+	var chiliBankReceiptCLPPrice *int
+	if packPaymentMethod.ChiliBankReceiptMethodEnabled {
+		value, ok := pack.Prices[domain.CurrencyCodeCLP]
+		if !ok {
+			price, err := port.currencyService.PriceForPack(pack, domain.CurrencyCodeCLP)
+			if err != nil {
+				return dto.PackPaymentMethodDTO{}, err
+			}
+			value = price.Value
+		}
+		clpPrice := int(value)
+		chiliBankReceiptCLPPrice = &clpPrice
+	}
+
 	dto := dto.PackPaymentMethodDTO{
 		ChiliBankReceiptMethodEnabled: packPaymentMethod.ChiliBankReceiptMethodEnabled,
 		ChiliBankReceiptAccountId:     chiliBankReceiptAccountId,
-		ChiliBankReceiptCLPPrice:      packPaymentMethod.ChiliBankReceiptCLPPrice,
+		ChiliBankReceiptCLPPrice:      chiliBankReceiptCLPPrice,
 		PaypalReceiptMethodEnabled:    packPaymentMethod.PaypalReceiptMethodEnabled,
 		PaypalReceiptRecipientEmail:   packPaymentMethod.PaypalReceiptRecipientEmail,
 		PaypalReceiptUSDPrice:         packPaymentMethod.PaypalReceiptUSDPrice,
@@ -132,5 +178,5 @@ func (port *domainPPMSService) mapDomainToDTO(packPaymentMethod *domain.PackPaym
 		PaypalOnlineUSDPrice:          packPaymentMethod.PaypalOnlineUSDPrice,
 	}
 
-	return dto
+	return dto, nil
 }
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestPackPaymentMethodService(rates ...domain.ExchangeRate) (PackPaymentMethodService, *fakePackService) {
+	packId := primitive.NewObjectID()
+	dollarValue := 10.0
+	packService := &fakePackService{packs: []domain.Pack{
+		{Id: &packId, PackNumber: 1, DollarValue: &dollarValue, Prices: map[string]float64{"EUR": 9}, Active: true},
+	}}
+	service := NewPackPaymentMethodService(packService,
+		NewDomainCurrencyService(newFakeExchangeRateRepository(rates...)),
+		&fakePackRepository{packService: packService},
+		newFakePackPaymentMethodRepository())
+	return service, packService
+}
+
+func TestPackPaymentMethodSavesTheCLPPriceInThePack(t *testing.T) {
+	service, packService := newTestPackPaymentMethodService()
+	clpPrice := 9990
+
+	saved, err := service.Save("model", 1, dto.PackPaymentMethodDTO{
+		ChiliBankReceiptMethodEnabled: true,
+		ChiliBankReceiptCLPPrice:      &clpPrice,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, clpPrice, *saved.ChiliBankReceiptCLPPrice)
+
+	// the other prices of the pack are kept
+	assert.Equal(t, map[string]float64{"EUR": 9, "CLP": 9990}, packService.packs[0].Prices)
+
+	found, err := service.GetFromPack("model", 1)
+	require.NoError(t, err)
+	assert.Equal(t, clpPrice, *found.ChiliBankReceiptCLPPrice)
+}
+
+func TestPackPaymentMethodConvertsTheCLPPriceWithoutPackPrice(t *testing.T) {
+	service, _ := newTestPackPaymentMethodService(domain.ExchangeRate{CurrencyCode: "CLP", UnitsPerUSD: 945.37})
+
+	saved, err := service.Save("model", 1, dto.PackPaymentMethodDTO{ChiliBankReceiptMethodEnabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, 9454, *saved.ChiliBankReceiptCLPPrice)
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PackBundleService interface {
+	CreateBundle(modelNickName string, data dto.SavePackBundleDto, personIdRequester string) (*dto.PackBundleDto, error)
+
+	UpdateBundle(modelNickName string, bundleNumber int, data dto.SavePackBundleDto, personIdRequester string) (*dto.PackBundleDto, error)
+
+	PublishBundle(modelNickName string, bundleNumber int, personIdRequester string) error
+
+	DeleteBundle(modelNickName string, bundleNumber int, personIdRequester string) error
+
+	GetBundlesFromModel(modelNickName string, personIdRequester *string) ([]dto.PackBundleDto, error)
+
+	FindActiveBundleByModelIdAndBundleNumber(modelId string, bundleNumber int) (*domain.PackBundle, error)
+
+	GetBundlePacks(bundle *domain.PackBundle) ([]domain.Pack, error)
+}
+
+type domainPackBundleService struct {
+	personService  PersonService
+	profileService ProfileService
+	modelService   ModelService
+	packService    PackService
+	repository     repository.PackBundleRepository
+}
+
+func NewDomainPackBundleService(
+	personService PersonService,
+	profileService ProfileService,
+	modelService ModelService,
+	packService PackService,
+	repository repository.PackBundleRepository,
+) PackBundleService {
+	return &domainPackBundleService{
+		personService,
+		profileService,
+		modelService,
+		packService,
+		repository,
+	}
+}
+
+func (port *domainPackBundleService) CreateBundle(modelNickName string, data dto.SavePackBundleDto, personIdRequester string) (*dto.PackBundleDto, error) {
+	model, err := port.mustGetModelForEdit(modelNickName, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+
+	packsId, err := port.getBundlePacksId(model, data.PackNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, err := port.repository.FindBundlesActiveByModelId(model.Id.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("error at packBundleService: CreateBundle: FindBundlesActiveByModelId. error: %w", err)
+	}
+
+	newBundleNumber := 1
+	for _, bundle := range bundles {
+		if bundle.BundleNumber >= newBundleNumber {
+			newBundleNumber = bundle.BundleNumber + 1
+		}
+	}
+
+	newBundle := domain.PackBundle{
+		ModelId:      *model.Id,
+		BundleNumber: newBundleNumber,
+		Title:        data.Title,
+		PacksId:      packsId,
+		DollarValue:  data.DollarValue,
+		Published:    false,
+		CreationDate: time.Now(),
+		Active:       true,
+	}
+
+	savedBundle, err := port.repository.SaveBundle(newBundle)
+	if err != nil {
+		return nil, fmt.Errorf("error at packBundleService: CreateBundle: SaveBundle. error: %w", err)
+	}
+
+	return port.toDto(savedBundle, data.PackNumbers), nil
+}
+
+func (port *domainPackBundleService) UpdateBundle(modelNickName string, bundleNumber int, data dto.SavePackBundleDto, personIdRequester string) (*dto.PackBundleDto, error) {
+	model, err := port.mustGetModelForEdit(modelNickName, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := port.mustGetBundleActive(model, bundleNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	packsId, err := port.getBundlePacksId(model, data.PackNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.Title = data.Title
+	bundle.PacksId = packsId
+	bundle.DollarValue = data.DollarValue
+
+	savedBundle, err := port.repository.SaveBundle(*bundle)
+	if err != nil {
+		return nil, fmt.Errorf("error at packBundleService: UpdateBundle: SaveBundle. error: %w", err)
+	}
+
+	return port.toDto(savedBundle, data.PackNumbers), nil
+}
+
+func (port *domainPackBundleService) PublishBundle(modelNickName string, bundleNumber int, personIdRequester string) error {
+	model, err := port.mustGetModelForEdit(modelNickName, personIdRequester)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := port.mustGetBundleActive(model, bundleNumber)
+	if err != nil {
+		return err
+	}
+
+	packs, err := port.GetBundlePacks(bundle)
+	if err != nil {
+		return err
+	}
+	for _, pack := range packs {
+		if !pack.Published {
+			return fmt.Errorf("error packBundleService: PublishBundle: pack number %d is not published yet", pack.PackNumber)
+		}
+	}
+
+	actualTime := time.Now()
+	bundle.Published = true
+	bundle.PublishedDate = &actualTime
+
+	_, err = port.repository.SaveBundle(*bundle)
+	if err != nil {
+		return fmt.Errorf("error at packBundleService: PublishBundle: SaveBundle. error: %w", err)
+	}
+	return nil
+}
+
+func (port *domainPackBundleService) DeleteBundle(modelNickName string, bundleNumber int, personIdRequester string) error {
+	model, err := port.mustGetModelForEdit(modelNickName, personIdRequester)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := port.mustGetBundleActive(model, bundleNumber)
+	if err != nil {
+		return err
+	}
+
+	bundle.Active = false
+
+	_, err = port.repository.SaveBundle(*bundle)
+	if err != nil {
+		return fmt.Errorf("error at packBundleService: DeleteBundle: SaveBundle. error: %w", err)
+	}
+	return nil
+}
+
+func (port *domainPackBundleService) GetBundlesFromModel(modelNickName string, personIdRequester *string) ([]dto.PackBundleDto, error) {
+	model, err := port.mustGetModel(modelNickName)
+	if err != nil {
+		return nil, err
+	}
+
+	canEdit := false
+	if personIdRequester != nil {
+		canEdit, err = port.canEditModel(model, *personIdRequester)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bundles, err := port.repository.FindBundlesActiveByModelId(model.Id.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("error at packBundleService: GetBundlesFromModel: FindBundlesActiveByModelId. error: %w", err)
+	}
+
+	var bundlesDto []dto.PackBundleDto
+	for i := range bundles {
+		bundle := &bundles[i]
+		if !bundle.Published && !canEdit {
+			continue
+		}
+
+		packs, err := port.GetBundlePacks(bundle)
+		if err != nil {
+			return nil, err
+		}
+
+		packNumbers := make([]int, len(packs))
+		for j, pack := range packs {
+			packNumbers[j] = pack.PackNumber
+		}
+
+		bundlesDto = append(bundlesDto, *port.toDto(bundle, packNumbers))
+	}
+
+	return bundlesDto, nil
+}
+
+func (port *domainPackBundleService) FindActiveBundleByModelIdAndBundleNumber(modelId string, bundleNumber int) (*domain.PackBundle, error) {
+	return port.repository.FindBundleActiveByModelIdAndBundleNumber(modelId, bundleNumber)
+}
+
+func (port *domainPackBundleService) GetBundlePacks(bundle *domain.PackBundle) ([]domain.Pack, error) {
+	packs := make([]domain.Pack, 0, len(bundle.PacksId))
+	for _, packId := range bundle.PacksId {
+		pack, err := port.packService.FindPackById(packId.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if pack == nil || !pack.Active {
+			return nil, fmt.Errorf("error packBundleService: GetBundlePacks: pack id %s of bundle %d is not available", packId.Hex(), bundle.BundleNumber)
+		}
+		packs = append(packs, *pack)
+	}
+	return packs, nil
+}
+
+// private
+
+func (port *domainPackBundleService) mustGetModel(modelNickName string) (*domain.Model, error) {
+	model, err := port.modelService.FindModelByNickName(modelNickName)
+	if err != nil {
+		return nil, err
+	}
+	if model == nil {
+		return nil, fmt.Errorf("model not found for nickname: %s", modelNickName)
+	}
+	return model, nil
+}
+
+func (port *domainPackBundleService) mustGetModelForEdit(modelNickName string, personIdRequester string) (*domain.Model, error) {
+	model, err := port.mustGetModel(modelNickName)
+	if err != nil {
+		return nil, err
+	}
+	canEdit, err := port.canEditModel(model, personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, fmt.Errorf("the person id %s can not edit bundles of model %s", personIdRequester, modelNickName)
+	}
+	return model, nil
+}
+
+func (port *domainPackBundleService) canEditModel(model *domain.Model, personIdRequester string) (bool, error) {
+	if model.PersonId.Hex() == personIdRequester {
+		return true, nil
+	}
+
+	person, err := port.personService.FindById(personIdRequester)
+	if err != nil {
+		return false, err
+	}
+	if person == nil {
+		return false, nil
+	}
+
+	profile, err := port.profileService.FindByCode(person.ProfileCode)
+	if err != nil {
+		return false, err
+	}
+	if profile == nil {
+		return false, nil
+	}
+	for _, permissionCode := range profile.PermissionsCodes {
+		if permissionCode == domain.PermissionCodeManageSystem {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (port *domainPackBundleService) mustGetBundleActive(model *domain.Model, bundleNumber int) (*domain.PackBundle, error) {
+	bundle, err := port.repository.FindBundleActiveByModelIdAndBundleNumber(model.Id.Hex(), bundleNumber)
+	if err != nil {
+		return nil, fmt.Errorf("error at packBundleService: mustGetBundleActive. error: %w", err)
+	}
+	if bundle == nil {
+		return nil, fmt.Errorf("bundle number %d not found for model %s", bundleNumber, model.NickName)
+	}
+	return bundle, nil
+}
+
+func (port *domainPackBundleService) getBundlePacksId(model *domain.Model, packNumbers []int) ([]primitive.ObjectID, error) {
+	if len(packNumbers) < 2 {
+		return nil, fmt.Errorf("a bundle requires at least two packs")
+	}
+
+	packsId := make([]primitive.ObjectID, 0, len(packNumbers))
+	seen := make(map[int]bool)
+	for _, packNumber := range packNumbers {
+		if seen[packNumber] {
+			return nil, fmt.Errorf("the pack number %d is repeated in the bundle", packNumber)
+		}
+		seen[packNumber] = true
+
+		pack, err := port.packService.FindActivePackByModelIdAndPackNumber(model.Id.Hex(), packNumber)
+		if err != nil {
+			return nil, err
+		}
+		if pack == nil {
+			return nil, fmt.Errorf("pack number %d not found for model %s", packNumber, model.NickName)
+		}
+		if pack.DollarValue == nil {
+			return nil, fmt.Errorf("the pack number %d does not have dollar value", packNumber)
+		}
+		packsId = append(packsId, *pack.Id)
+	}
+	return packsId, nil
+}
+
+func (port *domainPackBundleService) toDto(bundle *domain.PackBundle, packNumbers []int) *dto.PackBundleDto {
+	return &dto.PackBundleDto{
+		BundleNumber: bundle.BundleNumber,
+		Title:        bundle.Title,
+		PackNumbers:  packNumbers,
+		DollarValue:  bundle.DollarValue,
+		Published:    bundle.Published,
+	}
+}
@@ -1,14 +1,18 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
 	"github.com/erodriguezg/meet/pkg/core/repository"
 	"github.com/erodriguezg/meet/pkg/util/hashutil"
+	"github.com/erodriguezg/meet/pkg/util/watermark"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
 )
 
 const (
@@ -24,7 +28,7 @@ type PackService interface {
 
 	DeletePack(modelNickName string, packNumber int) error
 
-	PrepareUploadForPackItem(modelNickName string, packNumber int, typeCode string, isPublic bool) ([]dto.ResourceUploadUrlDto, error)
+	PrepareUploadForPackItem(modelNickName string, packNumber int, typeCode string, isPublic bool, personIdRequester string) ([]dto.ResourceUploadUrlDto, error)
 
 	GetPackInfo(modelNickName string, packNumber int, personIdRequester *string) (*dto.PackInfoDto, error)
 
@@ -36,7 +40,8 @@ type PackService interface {
 
 	ReadyToPublishPack(modelNickName string, packNumber int) error
 
-	PublishPack(modelNickName string, packNumber int) error
+	// PublishPack records the publication in the audit log with the actor
+	PublishPack(modelNickName string, packNumber int, actor domain.AuditActor) error
 
 	FindPackById(packId string) (*domain.Pack, error)
 
@@ -49,6 +54,18 @@ type PackService interface {
 	EditPackTitle(modelNickName string, packNumber int, title string) error
 
 	EditPackDescription(modelNickName string, packNumber int, description string) error
+
+	EditPackPrices(modelNickName string, packNumber int, dollarValue float64, prices map[string]float64, personIdRequester string) error
+
+	GetFileDownloadUrl(fileHash string, personIdRequester *string) (string, error)
+}
+
+type PackFileAccessDeniedError struct {
+	FileHash string
+}
+
+func (e *PackFileAccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied to pack file with hash: %s", e.FileHash)
 }
 
 type domainPackService struct {
@@ -57,7 +74,10 @@ type domainPackService struct {
 	modelService         ModelService
 	ownerResourceService OwnedResourceService
 	fileService          FileService
+	watermarkService     WatermarkService
 	repository           repository.PackRepository
+	auditService         AuditService
+	log                  *zap.Logger
 }
 
 func NewDomainPackService(
@@ -66,7 +86,10 @@ func NewDomainPackService(
 	modelService ModelService,
 	ownerResourceService OwnedResourceService,
 	fileService FileService,
+	watermarkService WatermarkService,
 	repository repository.PackRepository,
+	auditService AuditService,
+	log *zap.Logger,
 ) PackService {
 	return &domainPackService{
 		personService,
@@ -74,7 +97,10 @@ func NewDomainPackService(
 		modelService,
 		ownerResourceService,
 		fileService,
+		watermarkService,
 		repository,
+		auditService,
+		log,
 	}
 }
 
@@ -283,13 +309,21 @@ func (port *domainPackService) GetPacksFromModel(modelNickName string, personIdR
 	return packsDto, nil
 }
 
-func (port *domainPackService) PrepareUploadForPackItem(modelNickName string, packNumber int, typeCode string, isPublic bool) ([]dto.ResourceUploadUrlDto, error) {
+func (port *domainPackService) PrepareUploadForPackItem(modelNickName string, packNumber int, typeCode string, isPublic bool, personIdRequester string) ([]dto.ResourceUploadUrlDto, error) {
 
 	pack, err := port.mustGetPackActive(modelNickName, packNumber)
 	if err != nil {
 		return nil, err
 	}
 
+	accessLevel, err := port.getAccessLevelToPack(pack, modelNickName, &personIdRequester)
+	if err != nil {
+		return nil, err
+	}
+	if accessLevel < PackAccessLevelModel {
+		return nil, fmt.Errorf("error at PackService: PrepareUploadForPackItem: the person %s can not upload items to the pack", personIdRequester)
+	}
+
 	actualDate := time.Now()
 	actualDateFormat := actualDate.Format("20060102150405")
 
@@ -367,19 +401,27 @@ func (port *domainPackService) PrepareUploadForPackItem(modelNickName string, pa
 		lockFileHash,
 		lockExtension)
 
+	// the paid images are delivered with a watermark, they can not be smaller than it
+	minImageSize := 0
+	if !isPublic && isImageTypeCode(typeCode) {
+		minImageSize = watermark.MinImageSize(watermarkPayloadLength)
+	}
+
 	normalFile, normalUploadUrl, err := port.fileService.CreateForUpload(normalPath,
-		[]string{pack.ModelId.Hex(), pack.Id.Hex(), fmt.Sprint(itemNumber), "normal", extension, actualDateFormat})
+		[]string{pack.ModelId.Hex(), pack.Id.Hex(), fmt.Sprint(itemNumber), "normal", extension, actualDateFormat},
+		typeCode, personIdRequester, minImageSize)
 	if err != nil {
 		return nil, fmt.Errorf("error at packService: PrepareUploadForPackItem: create normal file. error: %w ", err)
 	}
 
-	thumbnailFile, thumbnailUploadUrl, err := port.fileService.CreateForUpload(thumbnailPath,
+	// the thumbnail and the locked preview are generated by the backend when the upload is confirmed
+	thumbnailFile, err := port.fileService.CreatePreview(normalFile.Hash, domain.FilePreviewThumbnail, thumbnailPath,
 		[]string{pack.ModelId.Hex(), pack.Id.Hex(), fmt.Sprint(itemNumber), "thumbnail", thumbnailExtension, actualDateFormat})
 	if err != nil {
 		return nil, fmt.Errorf("error at packService: PrepareUploadForPackItem: create thumbnail file. error: %w ", err)
 	}
 
-	lockFile, lockUploadUrl, err := port.fileService.CreateForUpload(lockPath,
+	lockFile, err := port.fileService.CreatePreview(normalFile.Hash, domain.FilePreviewLocked, lockPath,
 		[]string{pack.ModelId.Hex(), pack.Id.Hex(), fmt.Sprint(itemNumber), "lock", lockExtension, actualDateFormat})
 	if err != nil {
 		return nil, fmt.Errorf("error at packService: PrepareUploadForPackItem: create lock file. error: %w ", err)
@@ -410,18 +452,6 @@ func (port *domainPackService) PrepareUploadForPackItem(modelNickName string, pa
 			IsThumbnail: false,
 			IsBlurred:   false,
 		},
-		{
-			UploadUrl:   thumbnailUploadUrl,
-			FileHash:    thumbnailFile.Hash,
-			IsThumbnail: true,
-			IsBlurred:   false,
-		},
-		{
-			UploadUrl:   lockUploadUrl,
-			FileHash:    lockFile.Hash,
-			IsThumbnail: true,
-			IsBlurred:   true,
-		},
 	}, nil
 
 }
@@ -450,7 +480,7 @@ func (port *domainPackService) ReadyToPublishPack(modelNickName string, packNumb
 	return nil
 }
 
-func (port *domainPackService) PublishPack(modelNickName string, packNumber int) error {
+func (port *domainPackService) PublishPack(modelNickName string, packNumber int, actor domain.AuditActor) error {
 	modelId, err := port.getModelId(modelNickName)
 	if err != nil {
 		return err
@@ -460,6 +490,7 @@ func (port *domainPackService) PublishPack(modelNickName string, packNumber int)
 	if err != nil {
 		return err
 	}
+	previousPack := *pack
 
 	actualTime := time.Now()
 
@@ -470,6 +501,8 @@ func (port *domainPackService) PublishPack(modelNickName string, packNumber int)
 	if err != nil {
 		return fmt.Errorf("error at pack service: ReadyToPublishPack: SavePack. error: %w", err)
 	}
+	port.auditService.Record(actor, domain.AuditActionPackPublish, domain.AuditTargetPack,
+		pack.Id.Hex(), &previousPack, pack)
 
 	return nil
 }
@@ -523,8 +556,143 @@ func (port *domainPackService) EditPackTitle(modelNickName string, packNumber in
 	return err
 }
 
+func (port *domainPackService) EditPackPrices(modelNickName string, packNumber int, dollarValue float64, prices map[string]float64, personIdRequester string) error {
+	pack, err := port.mustGetPackActive(modelNickName, packNumber)
+	if err != nil {
+		return err
+	}
+
+	accessLevel, err := port.getAccessLevelToPack(pack, modelNickName, &personIdRequester)
+	if err != nil {
+		return err
+	}
+	if accessLevel < PackAccessLevelModel {
+		return fmt.Errorf("error packService: EditPackPrices: the person %s can not edit the pack prices", personIdRequester)
+	}
+
+	if dollarValue <= 0 {
+		return fmt.Errorf("error packService: EditPackPrices: invalid dollar value: %f", dollarValue)
+	}
+
+	normalizedPrices := make(map[string]float64, len(prices))
+	for currencyCode, value := range prices {
+		code, err := normalizeCurrencyCode(currencyCode)
+		if err != nil {
+			return err
+		}
+		// the dollar value is the price in USD
+		if code == domain.CurrencyCodeUSD {
+			return exception.NewInvalidCurrencyCodeException(currencyCode)
+		}
+		if value <= 0 {
+			return fmt.Errorf("error packService: EditPackPrices: invalid price for %s: %f", code, value)
+		}
+		normalizedPrices[code] = domain.RoundCurrency(value, code)
+	}
+
+	roundedDollarValue := domain.RoundCurrency(dollarValue, domain.CurrencyCodeUSD)
+	pack.DollarValue = &roundedDollarValue
+	pack.Prices = normalizedPrices
+
+	_, err = port.repository.SavePack(*pack)
+	return err
+}
+
+// GetFileDownloadUrl resources of the pack items get a short-lived url generated for each
+// request, and only if the requester can view the pack or the item is public. Thumbnails
+// keep the long-lived url. Out of the packs only the current profile images of the models
+// (and their thumbnails) can be downloaded, any other file is denied.
+// The owners of the pack receive the paid images with a watermark that identifies them.
+func (port *domainPackService) GetFileDownloadUrl(fileHash string, personIdRequester *string) (string, error) {
+	pack, err := port.repository.FindPackByItemFileHash(fileHash)
+	if err != nil {
+		return "", err
+	}
+	if pack == nil {
+		return port.getProfileImageDownloadUrl(fileHash)
+	}
+	if !pack.Active {
+		return "", &PackFileAccessDeniedError{fileHash}
+	}
+
+	var packItem *domain.PackItem
+	for i := range pack.PackItems {
+		item := &pack.PackItems[i]
+		if item.ResourceFileHash == fileHash || item.ThumbnailFileHash == fileHash || item.ThumbnailLockedFileHash == fileHash {
+			packItem = item
+			break
+		}
+	}
+	if packItem == nil || !packItem.Active {
+		return "", &PackFileAccessDeniedError{fileHash}
+	}
+	if packItem.ThumbnailLockedFileHash == fileHash {
+		return port.fileService.GetDownloadUrl(fileHash)
+	}
+
+	model, err := port.modelService.FindModelById(pack.ModelId.Hex())
+	if err != nil {
+		return "", err
+	}
+	if model == nil {
+		return "", fmt.Errorf("error packService: GetFileDownloadUrl: model not found for id: %s", pack.ModelId.Hex())
+	}
+
+	accessLevel, err := port.getAccessLevelToPack(pack, model.NickName, personIdRequester)
+	if err != nil {
+		return "", err
+	}
+	if !canDownloadPackItemFile(packItem, fileHash, accessLevel) {
+		return "", &PackFileAccessDeniedError{fileHash}
+	}
+
+	if packItem.ResourceFileHash != fileHash {
+		return port.fileService.GetDownloadUrl(fileHash)
+	}
+
+	if !packItem.PublicItem && accessLevel == PackAccessLevelView && isImageTypeCode(packItem.TypeCode) {
+		watermarkedFileHash, err := port.watermarkService.GetWatermarkedFileHash(fileHash, pack.Id.Hex(), *personIdRequester)
+		if err == nil {
+			return port.fileService.GetTemporaryDownloadUrl(watermarkedFileHash)
+		}
+		if !errors.Is(err, watermark.ErrImageTooSmall) {
+			return "", err
+		}
+		// only the images uploaded before the min size was checked at the confirmation
+		port.log.Warn("the paid image is delivered without watermark, it is too small",
+			zap.String("packId", pack.Id.Hex()), zap.String("fileHash", fileHash))
+	}
+	return port.fileService.GetTemporaryDownloadUrl(fileHash)
+}
+
 // private
 
+// canDownloadPackItemFile the locked thumbnail is for anyone, the thumbnail and the resource
+// only for the public items or the persons that can view the pack (as in GetItemsFromPack)
+func canDownloadPackItemFile(packItem *domain.PackItem, fileHash string, accessLevel int) bool {
+	if packItem.ThumbnailLockedFileHash == fileHash {
+		return true
+	}
+	if accessLevel == PackAccessLevelDenied {
+		return false
+	}
+	return packItem.PublicItem || accessLevel >= PackAccessLevelView
+}
+
+// getProfileImageDownloadUrl the files out of the packs are only downloaded when they are the
+// profile image of a model, the others (pending uploads, previews of the resources,
+// watermarked copies) are not public
+func (port *domainPackService) getProfileImageDownloadUrl(fileHash string) (string, error) {
+	model, err := port.modelService.FindModelByProfileImageFileHash(fileHash)
+	if err != nil {
+		return "", err
+	}
+	if model == nil {
+		return "", &PackFileAccessDeniedError{fileHash}
+	}
+	return port.fileService.GetDownloadUrl(fileHash)
+}
+
 func (port *domainPackService) getModel(modelNickName string) (*domain.Model, error) {
 	model, err := port.modelService.FindModelByNickName(modelNickName)
 	if err != nil {
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanDownloadPackItemFileLockedItem(t *testing.T) {
+	item := &domain.PackItem{
+		ResourceFileHash:        "resource",
+		ThumbnailFileHash:       "thumbnail",
+		ThumbnailLockedFileHash: "locked",
+		PublicItem:              false,
+	}
+
+	for _, accessLevel := range []int{PackAccessLevelDenied, PackAccessLevelLocked} {
+		assert.True(t, canDownloadPackItemFile(item, "locked", accessLevel))
+		assert.False(t, canDownloadPackItemFile(item, "thumbnail", accessLevel))
+		assert.False(t, canDownloadPackItemFile(item, "resource", accessLevel))
+	}
+
+	for _, accessLevel := range []int{PackAccessLevelView, PackAccessLevelModel, PackAccessLevelEdit} {
+		assert.True(t, canDownloadPackItemFile(item, "thumbnail", accessLevel))
+		assert.True(t, canDownloadPackItemFile(item, "resource", accessLevel))
+	}
+}
+
+func TestCanDownloadPackItemFilePublicItem(t *testing.T) {
+	item := &domain.PackItem{
+		ResourceFileHash:        "resource",
+		ThumbnailFileHash:       "thumbnail",
+		ThumbnailLockedFileHash: "locked",
+		PublicItem:              true,
+	}
+
+	assert.True(t, canDownloadPackItemFile(item, "thumbnail", PackAccessLevelLocked))
+	assert.True(t, canDownloadPackItemFile(item, "resource", PackAccessLevelLocked))
+	assert.False(t, canDownloadPackItemFile(item, "thumbnail", PackAccessLevelDenied))
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+)
+
+type PermissionService interface {
+	FindAll() ([]domain.Permission, error)
+
+	Save(permission dto.PermissionSaveDto, actor domain.AuditActor) (*domain.Permission, error)
+
+	// Delete only the permissions not checked by the system nor assigned to profiles
+	Delete(code int, actor domain.AuditActor) error
+}
+
+type domainPermissionService struct {
+	permissionRepository repository.PermissionRepository
+	profileRepository    repository.ProfileRepository
+	auditService         AuditService
+}
+
+func NewDomainPermissionService(
+	permissionRepository repository.PermissionRepository,
+	profileRepository repository.ProfileRepository,
+	auditService AuditService,
+) PermissionService {
+	return &domainPermissionService{permissionRepository, profileRepository, auditService}
+}
+
+// FindAll implements PermissionService
+func (port *domainPermissionService) FindAll() ([]domain.Permission, error) {
+	return port.permissionRepository.FindAll()
+}
+
+// Save implements PermissionService
+func (port *domainPermissionService) Save(input dto.PermissionSaveDto, actor domain.AuditActor) (*domain.Permission, error) {
+	existingPermission, err := port.permissionRepository.FindByCode(input.Code)
+	if err != nil {
+		return nil, err
+	}
+	savedPermission, err := port.permissionRepository.Save(domain.Permission{
+		Code: input.Code,
+		Name: input.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionPermissionSave, domain.AuditTargetPermission,
+		strconv.Itoa(input.Code), existingPermission, savedPermission)
+	return savedPermission, nil
+}
+
+// Delete implements PermissionService
+func (port *domainPermissionService) Delete(code int, actor domain.AuditActor) error {
+	if slices.Contains(domain.SystemPermissionCodes, code) {
+		return exception.NewSystemPermissionException(code)
+	}
+	permission, err := port.permissionRepository.FindByCode(code)
+	if err != nil {
+		return err
+	}
+	if permission == nil {
+		return exception.NewPermissionNotFoundException(code)
+	}
+	profiles, err := port.profileRepository.FindByPermissionCode(code)
+	if err != nil {
+		return err
+	}
+	if len(profiles) > 0 {
+		return exception.NewPermissionInUseException(code)
+	}
+	err = port.permissionRepository.Delete(code)
+	if err != nil {
+		return err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionPermissionDelete, domain.AuditTargetPermission,
+		strconv.Itoa(code), permission, nil)
+	return nil
+}
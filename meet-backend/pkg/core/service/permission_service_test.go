@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionSaveAndDeleteAreAudited(t *testing.T) {
+	auditService := &fakeAuditService{}
+	permissionService := NewDomainPermissionService(newFakePermissionRepository(), newFakeProfileRepository(), auditService)
+	actor := domain.AuditActor{PersonId: "admin", Ip: "127.0.0.1"}
+
+	_, err := permissionService.Save(dto.PermissionSaveDto{Code: 100, Name: "Export"}, actor)
+	require.NoError(t, err)
+	require.NoError(t, permissionService.Delete(100, actor))
+
+	// a permission of the system is not deleted nor audited
+	assert.Error(t, permissionService.Delete(domain.PermissionCodeManageSystem, actor))
+
+	assert.Equal(t, []fakeAuditEntry{
+		{actor, domain.AuditActionPermissionSave, "100"},
+		{actor, domain.AuditActionPermissionDelete, "100"},
+	}, auditService.entries)
+}
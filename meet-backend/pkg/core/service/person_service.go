@@ -1,35 +1,79 @@
 package service
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
 	"github.com/erodriguezg/meet/pkg/core/exception"
 	"github.com/erodriguezg/meet/pkg/core/repository"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
 )
 
 type PersonService interface {
 	FindByEmail(email string) (*domain.Person, error)
 
+	FindByExternalIdentity(provider string, subject string) (*domain.Person, error)
+
 	FindById(uuid string) (*domain.Person, error)
 
 	FindAll() ([]domain.Person, error)
 
 	FilterPaginated(filters domain.PersonFilter) ([]domain.Person, error)
 
-	Save(person domain.Person) (*domain.Person, error)
+	// Save records the changes in the audit log with the actor
+	Save(person domain.Person, actor domain.AuditActor) (*domain.Person, error)
+
+	// UpdateOwnData updates only the safe fields, the person can not change its profile,
+	// email or active state with it
+	UpdateOwnData(personId string, data dto.PersonSelfUpdateDto, actor domain.AuditActor) (*domain.Person, error)
+
+	AssignProfile(personId string, profileCode int, actor domain.AuditActor) (*domain.Person, error)
 
-	Delete(uuid string) error
+	Delete(uuid string, actor domain.AuditActor) error
 }
 
 type domainPersonService struct {
-	personRepository repository.PersonRepository
+	personRepository   repository.PersonRepository
+	profileRepository  repository.ProfileRepository
+	administratorGuard *administratorGuard
+	// persons by email, read on every authenticated request
+	personCache  *ttlcache.Cache[string, domain.Person]
+	auditService AuditService
 }
 
-func NewDomainPersonService(personRepository repository.PersonRepository) PersonService {
-	return &domainPersonService{personRepository}
+func NewDomainPersonService(
+	personRepository repository.PersonRepository,
+	profileRepository repository.ProfileRepository,
+	personCache *ttlcache.Cache[string, domain.Person],
+	auditService AuditService,
+) PersonService {
+	return &domainPersonService{
+		personRepository,
+		profileRepository,
+		&administratorGuard{profileRepository, personRepository},
+		personCache,
+		auditService,
+	}
 }
 
 func (port *domainPersonService) FindByEmail(email string) (*domain.Person, error) {
-	return port.personRepository.FindByEmail(email)
+	if cached, found := port.personCache.Get(personCacheKey(email)); found {
+		return clonePerson(cached), nil
+	}
+	person, err := port.personRepository.FindByEmail(email)
+	if err != nil || person == nil {
+		return person, err
+	}
+	port.personCache.Put(personCacheKey(email), *clonePerson(*person))
+	return person, nil
+}
+
+func (port *domainPersonService) FindByExternalIdentity(provider string, subject string) (*domain.Person, error) {
+	return port.personRepository.FindByExternalIdentity(provider, subject)
 }
 
 func (port *domainPersonService) FindById(uuid string) (*domain.Person, error) {
@@ -44,7 +88,7 @@ func (port *domainPersonService) FilterPaginated(filters domain.PersonFilter) ([
 	return port.personRepository.FilterPaginated(filters)
 }
 
-func (port *domainPersonService) Save(person domain.Person) (*domain.Person, error) {
+func (port *domainPersonService) Save(person domain.Person, actor domain.AuditActor) (*domain.Person, error) {
 
 	// validate unique email
 
@@ -57,6 +101,24 @@ func (port *domainPersonService) Save(person domain.Person) (*domain.Person, err
 		return nil, exception
 	}
 
+	previousPerson, err := port.findPreviousPerson(person, existingPersonWithEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingPersonWithEmail != nil && person.Id != nil {
+		err = port.administratorGuard.mustKeepAdministrator(*existingPersonWithEmail, &person)
+		if err != nil {
+			return nil, err
+		}
+
+		// the tokens already issued must not keep the access removed
+		if (existingPersonWithEmail.Active && !person.Active) || existingPersonWithEmail.ProfileCode != person.ProfileCode {
+			now := time.Now()
+			person.TokensNotBefore = &now
+		}
+	}
+
 	// transaction for save person
 
 	var updatedPerson *domain.Person
@@ -66,10 +128,113 @@ func (port *domainPersonService) Save(person domain.Person) (*domain.Person, err
 	} else {
 		updatedPerson, err = port.personRepository.Update(person)
 	}
+	port.personCache.Invalidate(personCacheKey(person.Email))
+	if previousPerson != nil {
+		port.personCache.Invalidate(personCacheKey(previousPerson.Email))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionPersonSave, domain.AuditTargetPerson,
+		personIdHex(updatedPerson), previousPerson, updatedPerson)
+	return updatedPerson, nil
+}
+
+func (port *domainPersonService) UpdateOwnData(personId string, data dto.PersonSelfUpdateDto, actor domain.AuditActor) (*domain.Person, error) {
+	person, err := port.personRepository.FindById(personId)
+	if err != nil {
+		return nil, err
+	}
+	if person == nil {
+		return nil, fmt.Errorf("the person %s does not exist", personId)
+	}
+
+	previousPerson := *person
+	person.FirstName = data.FirstName
+	person.LastName = data.LastName
+	person.BirthDay = data.BirthDay
+	updatedPerson, err := port.personRepository.Update(*person)
+	port.personCache.Invalidate(personCacheKey(person.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionPersonSave, domain.AuditTargetPerson,
+		personIdHex(updatedPerson), &previousPerson, updatedPerson)
+	return updatedPerson, nil
+}
+
+func (port *domainPersonService) AssignProfile(personId string, profileCode int, actor domain.AuditActor) (*domain.Person, error) {
+	person, err := port.personRepository.FindById(personId)
+	if err != nil {
+		return nil, err
+	}
+	if person == nil {
+		return nil, fmt.Errorf("the person %s does not exist", personId)
+	}
+	profile, err := port.profileRepository.FindByCode(profileCode)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, exception.NewProfileNotFoundException(profileCode)
+	}
+
+	person.ProfileCode = profileCode
+	return port.Save(*person, actor)
+}
+
+func (port *domainPersonService) Delete(uuid string, actor domain.AuditActor) error {
+	person, err := port.personRepository.FindById(uuid)
+	if err != nil {
+		return err
+	}
+	if person == nil {
+		return port.personRepository.Delete(uuid)
+	}
+	err = port.administratorGuard.mustKeepAdministrator(*person, nil)
+	if err != nil {
+		return err
+	}
+	err = port.personRepository.Delete(uuid)
+	port.personCache.Invalidate(personCacheKey(person.Email))
+	if err != nil {
+		return err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionPersonDelete, domain.AuditTargetPerson, uuid, person, nil)
+	return nil
+}
+
+// private
+
+// findPreviousPerson the stored person before the save, for the audit and the cache
+// (its email can change). Nil for a new person.
+func (port *domainPersonService) findPreviousPerson(person domain.Person, existingPersonWithEmail *domain.Person) (*domain.Person, error) {
+	if person.Id == nil {
+		return nil, nil
+	}
+	if existingPersonWithEmail != nil {
+		return existingPersonWithEmail, nil
+	}
+	return port.personRepository.FindById(person.Id.Hex())
+}
+
+func personIdHex(person *domain.Person) string {
+	if person == nil || person.Id == nil {
+		return ""
+	}
+	return person.Id.Hex()
+}
 
-	return updatedPerson, err
+// personCacheKey the emails are compared without case
+func personCacheKey(email string) string {
+	return strings.ToLower(email)
 }
 
-func (port *domainPersonService) Delete(uuid string) error {
-	return port.personRepository.Delete(uuid)
+// clonePerson the cached persons are not shared with the callers, they can modify them
+func clonePerson(person domain.Person) *domain.Person {
+	person.Identities = slices.Clone(person.Identities)
+	return &person
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/util/datetime"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPersonService(persons ...domain.Person) (PersonService, *fakePersonRepository, *fakeAuditService) {
+	personRepository := newFakePersonRepository(persons...)
+	auditService := &fakeAuditService{}
+	personService := NewDomainPersonService(personRepository, newFakeProfileRepository(),
+		ttlcache.New[string, domain.Person](time.Minute, 10), auditService)
+	return personService, personRepository, auditService
+}
+
+func TestPersonSaveKeepsLastAdministrator(t *testing.T) {
+	admin := domain.Person{Email: "admin@meet.com", ProfileCode: domain.ProfileCodeAdministrator, Active: true}
+	personService, personRepository, auditService := newTestPersonService(admin)
+	stored, _ := personRepository.FindByEmail(admin.Email)
+
+	demoted := *stored
+	demoted.ProfileCode = domain.ProfileCodeUser
+	_, err := personService.Save(demoted, domain.AuditActor{})
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	deactivated := *stored
+	deactivated.Active = false
+	_, err = personService.Save(deactivated, domain.AuditActor{})
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	unchanged, _ := personRepository.FindByEmail(admin.Email)
+	assert.Equal(t, domain.ProfileCodeAdministrator, unchanged.ProfileCode)
+	assert.True(t, unchanged.Active)
+	assert.Empty(t, auditService.entries)
+}
+
+func TestPersonSaveDemotesAdministratorWithOther(t *testing.T) {
+	personService, personRepository, _ := newTestPersonService(
+		domain.Person{Email: "admin@meet.com", ProfileCode: domain.ProfileCodeAdministrator, Active: true},
+		domain.Person{Email: "other@meet.com", ProfileCode: domain.ProfileCodeAdministrator, Active: true},
+	)
+	stored, _ := personRepository.FindByEmail("admin@meet.com")
+
+	stored.ProfileCode = domain.ProfileCodeUser
+	saved, err := personService.Save(*stored, domain.AuditActor{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProfileCodeUser, saved.ProfileCode)
+	assert.NotNil(t, saved.TokensNotBefore)
+}
+
+func TestPersonDeleteKeepsLastAdministrator(t *testing.T) {
+	personService, personRepository, auditService := newTestPersonService(
+		domain.Person{Email: "admin@meet.com", ProfileCode: domain.ProfileCodeAdministrator, Active: true},
+		domain.Person{Email: "inactive@meet.com", ProfileCode: domain.ProfileCodeAdministrator, Active: false},
+	)
+	admin, _ := personRepository.FindByEmail("admin@meet.com")
+
+	err := personService.Delete(admin.Id.Hex(), domain.AuditActor{})
+	assert.IsType(t, &exception.BusinessException{}, err)
+
+	stillStored, _ := personRepository.FindById(admin.Id.Hex())
+	assert.NotNil(t, stillStored)
+	assert.Empty(t, auditService.entries)
+}
+
+func TestPersonDeleteAdministratorWithOther(t *testing.T) {
+	personService, personRepository, auditService := newTestPersonService(
+		domain.Person{Email: "admin@meet.com", ProfileCode: domain.ProfileCodeAdministrator, Active: true},
+		domain.Person{Email: "other@meet.com", ProfileCode: domain.ProfileCodeAdministrator, Active: true},
+		domain.Person{Email: "user@meet.com", ProfileCode: domain.ProfileCodeUser, Active: true},
+	)
+	admin, _ := personRepository.FindByEmail("admin@meet.com")
+	user, _ := personRepository.FindByEmail("user@meet.com")
+
+	require.NoError(t, personService.Delete(admin.Id.Hex(), domain.AuditActor{PersonId: "requester"}))
+	require.NoError(t, personService.Delete(user.Id.Hex(), domain.AuditActor{PersonId: "requester"}))
+
+	deleted, _ := personRepository.FindById(admin.Id.Hex())
+	assert.Nil(t, deleted)
+	assert.Len(t, auditService.entries, 2)
+	assert.Equal(t, domain.AuditActionPersonDelete, auditService.entries[0].action)
+}
+
+func TestPersonUpdateOwnDataClearsBirthDay(t *testing.T) {
+	birthDay := datetime.NewFromTime(time.Date(1990, 5, 20, 0, 0, 0, 0, time.UTC))
+	personService, personRepository, auditService := newTestPersonService(
+		domain.Person{Email: "user@meet.com", FirstName: "Old", BirthDay: &birthDay, Active: true})
+	person, _ := personRepository.FindByEmail("user@meet.com")
+
+	actor := domain.AuditActor{PersonId: person.Id.Hex(), Ip: "10.0.0.1"}
+	updated, err := personService.UpdateOwnData(person.Id.Hex(),
+		dto.PersonSelfUpdateDto{FirstName: "New", LastName: "Name"}, actor)
+	require.NoError(t, err)
+	assert.Equal(t, "New", updated.FirstName)
+	assert.Nil(t, updated.BirthDay)
+
+	stored, _ := personRepository.FindById(person.Id.Hex())
+	assert.Nil(t, stored.BirthDay)
+
+	require.Len(t, auditService.entries, 1)
+	assert.Equal(t, actor, auditService.entries[0].actor)
+	assert.Equal(t, domain.AuditActionPersonSave, auditService.entries[0].action)
+	assert.Equal(t, person.Id.Hex(), auditService.entries[0].targetId)
+}
@@ -1,30 +1,146 @@
 package service
 
 import (
+	"slices"
+	"strconv"
+
 	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
 	"github.com/erodriguezg/meet/pkg/core/repository"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
 )
 
 type ProfileService interface {
 	FindByCode(code int) (*domain.Profile, error)
 
 	FindAll() ([]domain.Profile, error)
+
+	// Save creates or updates the profile, the permissions must exist
+	Save(profile dto.ProfileSaveDto, actor domain.AuditActor) (*domain.Profile, error)
+
+	// Delete only the profiles not used by the system nor assigned to persons
+	Delete(code int, actor domain.AuditActor) error
 }
 
 type domainProfileService struct {
-	profileRepository repository.ProfileRepository
+	profileRepository    repository.ProfileRepository
+	permissionRepository repository.PermissionRepository
+	personRepository     repository.PersonRepository
+	administratorGuard   *administratorGuard
+	// profiles by code, read on every authenticated request
+	profileCache *ttlcache.Cache[int, domain.Profile]
+	auditService AuditService
 }
 
-func NewDomainProfileService(profileRepository repository.ProfileRepository) ProfileService {
-	return &domainProfileService{profileRepository}
+func NewDomainProfileService(
+	profileRepository repository.ProfileRepository,
+	permissionRepository repository.PermissionRepository,
+	personRepository repository.PersonRepository,
+	profileCache *ttlcache.Cache[int, domain.Profile],
+	auditService AuditService,
+) ProfileService {
+	return &domainProfileService{
+		profileRepository,
+		permissionRepository,
+		personRepository,
+		&administratorGuard{profileRepository, personRepository},
+		profileCache,
+		auditService,
+	}
 }
 
 // FindByCode implements ProfileService
 func (port *domainProfileService) FindByCode(code int) (*domain.Profile, error) {
-	return port.profileRepository.FindByCode(code)
+	if cached, found := port.profileCache.Get(code); found {
+		cached.PermissionsCodes = slices.Clone(cached.PermissionsCodes)
+		return &cached, nil
+	}
+	profile, err := port.profileRepository.FindByCode(code)
+	if err != nil || profile == nil {
+		return profile, err
+	}
+	cached := *profile
+	cached.PermissionsCodes = slices.Clone(profile.PermissionsCodes)
+	port.profileCache.Put(code, cached)
+	return profile, nil
 }
 
 // FindAll implements ProfileService
 func (port *domainProfileService) FindAll() ([]domain.Profile, error) {
 	return port.profileRepository.FindAll()
 }
+
+// Save implements ProfileService
+func (port *domainProfileService) Save(input dto.ProfileSaveDto, actor domain.AuditActor) (*domain.Profile, error) {
+	profile := domain.Profile{
+		Code:             input.Code,
+		Name:             input.Name,
+		PermissionsCodes: []int{},
+	}
+	for _, permissionCode := range input.PermissionsCodes {
+		if slices.Contains(profile.PermissionsCodes, permissionCode) {
+			continue
+		}
+		permission, err := port.permissionRepository.FindByCode(permissionCode)
+		if err != nil {
+			return nil, err
+		}
+		if permission == nil {
+			return nil, exception.NewPermissionNotFoundException(permissionCode)
+		}
+		profile.PermissionsCodes = append(profile.PermissionsCodes, permissionCode)
+	}
+
+	existingProfile, err := port.profileRepository.FindByCode(profile.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existingProfile != nil && existingProfile.HasPermission(domain.PermissionCodeManageSystem) &&
+		!profile.HasPermission(domain.PermissionCodeManageSystem) {
+		err = port.administratorGuard.mustKeepAdministratorWithoutProfile(profile.Code)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	savedProfile, err := port.profileRepository.Save(profile)
+	port.profileCache.Invalidate(profile.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionProfileSave, domain.AuditTargetProfile,
+		strconv.Itoa(profile.Code), existingProfile, savedProfile)
+	return savedProfile, nil
+}
+
+// Delete implements ProfileService
+func (port *domainProfileService) Delete(code int, actor domain.AuditActor) error {
+	if slices.Contains(domain.SystemProfileCodes, code) {
+		return exception.NewSystemProfileException(code)
+	}
+	profile, err := port.profileRepository.FindByCode(code)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return exception.NewProfileNotFoundException(code)
+	}
+	count, err := port.personRepository.CountByProfileCodes([]int{code}, false)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return exception.NewProfileInUseException(code)
+	}
+	err = port.profileRepository.Delete(code)
+	port.profileCache.Invalidate(code)
+	if err != nil {
+		return err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionProfileDelete, domain.AuditTargetProfile,
+		strconv.Itoa(code), profile, nil)
+	return nil
+}
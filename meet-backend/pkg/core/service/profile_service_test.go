@@ -0,0 +1,35 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/util/ttlcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProfileCode = 10
+
+func TestProfileSaveAndDeleteAreAudited(t *testing.T) {
+	auditService := &fakeAuditService{}
+	profileService := NewDomainProfileService(newFakeProfileRepository(), newFakePermissionRepository(),
+		newFakePersonRepository(), ttlcache.New[int, domain.Profile](time.Minute, 10), auditService)
+	actor := domain.AuditActor{PersonId: "admin", Ip: "127.0.0.1"}
+
+	_, err := profileService.Save(dto.ProfileSaveDto{
+		Code:             testProfileCode,
+		Name:             "Support",
+		PermissionsCodes: []int{domain.PermissionCodeEditOwnProfile},
+	}, actor)
+	require.NoError(t, err)
+	require.NoError(t, profileService.Delete(testProfileCode, actor))
+
+	assert.Equal(t, []fakeAuditEntry{
+		{actor, domain.AuditActionProfileSave, strconv.Itoa(testProfileCode)},
+		{actor, domain.AuditActionProfileDelete, strconv.Itoa(testProfileCode)},
+	}, auditService.entries)
+}
@@ -24,28 +24,33 @@ type RoomService interface {
 
 	CreateRoom(params dto.CreateRoomDTO) (dto.RoomDTO, error)
 
-	DeleteRoom(roomHash string) error
+	// the changes of the rooms are recorded in the audit log with the actor
 
-	DeleteOwnRoom(roomHash string, ownerPersonId string) error
+	DeleteRoom(roomHash string, actor domain.AuditActor) error
+
+	DeleteOwnRoom(roomHash string, ownerPersonId string, actor domain.AuditActor) error
 
 	DeleteAllExpiredRooms() error
 
-	ChangeRoomVisibility(params dto.ChangeRoomVisibilityRoomDTO) (dto.RoomDTO, error)
+	ChangeRoomVisibility(params dto.ChangeRoomVisibilityRoomDTO, actor domain.AuditActor) (dto.RoomDTO, error)
 
-	ChangeRoomVisibilityOwnRoom(params dto.ChangeRoomVisibilityRoomDTO, ownerPersonId string) (dto.RoomDTO, error)
+	ChangeRoomVisibilityOwnRoom(params dto.ChangeRoomVisibilityRoomDTO, ownerPersonId string, actor domain.AuditActor) (dto.RoomDTO, error)
 }
 
 type domainRoomService struct {
 	roomRepository repository.RoomRepository
 	personService  PersonService
+	auditService   AuditService
 }
 
 func NewDomainRoomService(
 	roomRepository repository.RoomRepository,
-	personService PersonService) RoomService {
+	personService PersonService,
+	auditService AuditService) RoomService {
 	return &domainRoomService{
 		roomRepository,
 		personService,
+		auditService,
 	}
 }
 
@@ -82,7 +87,7 @@ func (port *domainRoomService) FindRoomByHash(roomHash string, personIdRequester
 }
 
 // ChangeRoomVisibility implements RoomService.
-func (port *domainRoomService) ChangeRoomVisibility(params dto.ChangeRoomVisibilityRoomDTO) (dto.RoomDTO, error) {
+func (port *domainRoomService) ChangeRoomVisibility(params dto.ChangeRoomVisibilityRoomDTO, actor domain.AuditActor) (dto.RoomDTO, error) {
 
 	roomFound, err := port.findRoomByHash(params.RoomHash)
 	if err != nil {
@@ -94,20 +99,21 @@ func (port *domainRoomService) ChangeRoomVisibility(params dto.ChangeRoomVisibil
 		return dto.RoomDTO{}, err
 	}
 
-	return port.changeRoomVisibility(params, &roomFound, &owner)
+	return port.changeRoomVisibility(params, &roomFound, &owner, actor)
 }
 
 // ChangeRoomVisibilityOwnRoom implements RoomService.
 func (port *domainRoomService) ChangeRoomVisibilityOwnRoom(
 	params dto.ChangeRoomVisibilityRoomDTO,
-	ownerPersonId string) (dto.RoomDTO, error) {
+	ownerPersonId string,
+	actor domain.AuditActor) (dto.RoomDTO, error) {
 
 	roomFound, owner, err := port.findOwnedRoom(params.RoomHash, ownerPersonId)
 	if err != nil {
 		return dto.RoomDTO{}, err
 	}
 
-	return port.changeRoomVisibility(params, &roomFound, &owner)
+	return port.changeRoomVisibility(params, &roomFound, &owner, actor)
 }
 
 // CreateRoom implements RoomService.
@@ -157,25 +163,25 @@ func (port *domainRoomService) DeleteAllExpiredRooms() error {
 }
 
 // DeleteRoom implements RoomService.
-func (port *domainRoomService) DeleteRoom(roomHash string) error {
+func (port *domainRoomService) DeleteRoom(roomHash string, actor domain.AuditActor) error {
 
 	roomFound, err := port.findRoomByHash(roomHash)
 	if err != nil {
 		return err
 	}
 
-	return port.roomRepository.Delete(*roomFound.Id)
+	return port.deleteRoom(&roomFound, actor)
 }
 
 // DeleteOwnRoom implements RoomService.
-func (port *domainRoomService) DeleteOwnRoom(roomHash string, ownerPersonId string) error {
+func (port *domainRoomService) DeleteOwnRoom(roomHash string, ownerPersonId string, actor domain.AuditActor) error {
 
 	roomFound, _, err := port.findOwnedRoom(roomHash, ownerPersonId)
 	if err != nil {
 		return err
 	}
 
-	return port.roomRepository.Delete(*roomFound.Id)
+	return port.deleteRoom(&roomFound, actor)
 }
 
 // FindAllRooms implements RoomService.
@@ -230,6 +236,16 @@ func (port *domainRoomService) FindByOwnerPersonId(ownerPersonId string) ([]dto.
 
 // private
 
+func (port *domainRoomService) deleteRoom(roomFound *domain.Room, actor domain.AuditActor) error {
+	err := port.roomRepository.Delete(*roomFound.Id)
+	if err != nil {
+		return err
+	}
+	port.auditService.Record(actor, domain.AuditActionRoomDelete, domain.AuditTargetRoom,
+		*roomFound.RoomHash, roomFound, nil)
+	return nil
+}
+
 func (port *domainRoomService) findPerson(personId string) (domain.Person, error) {
 	person, err := port.personService.FindById(personId)
 	if err != nil {
@@ -301,13 +317,17 @@ func (port *domainRoomService) generateRoomHash(room *domain.Room) string {
 func (port *domainRoomService) changeRoomVisibility(
 	params dto.ChangeRoomVisibilityRoomDTO,
 	roomFound *domain.Room,
-	owner *domain.Person) (dto.RoomDTO, error) {
+	owner *domain.Person,
+	actor domain.AuditActor) (dto.RoomDTO, error) {
 
+	previousRoom := *roomFound
 	roomFound.AnonymousAccess = params.NewAnonymousAccess
 	roomPersisted, err := port.roomRepository.Update(*roomFound)
 	if err != nil {
 		return dto.RoomDTO{}, err
 	}
+	port.auditService.Record(actor, domain.AuditActionRoomChangeVisibility, domain.AuditTargetRoom,
+		*roomFound.RoomHash, &previousRoom, roomPersisted)
 
 	return port.roomToDTO(roomPersisted, owner), nil
 }
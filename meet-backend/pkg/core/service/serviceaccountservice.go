@@ -0,0 +1,272 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	// the keys are "mk_<prefix>.<secret>", the prefix identifies the key in the logs and the
+	// database without exposing it
+	ApiKeyTokenPrefix = "mk_"
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+)
+
+type ServiceAccountService interface {
+	FindAll() ([]domain.ServiceAccount, error)
+
+	FindById(id string) (*domain.ServiceAccount, error)
+
+	Save(serviceAccount dto.ServiceAccountSaveDto, actor domain.AuditActor) (*domain.ServiceAccount, error)
+
+	// Delete the service account and revokes all its keys
+	Delete(id string, actor domain.AuditActor) error
+
+	FindApiKeys(serviceAccountId string) ([]domain.ApiKey, error)
+
+	// CreateApiKey returns the key, it is not possible to get it again
+	CreateApiKey(serviceAccountId string, apiKey dto.ApiKeyCreateDto, actor domain.AuditActor) (*dto.ApiKeyCreatedDto, error)
+
+	RevokeApiKey(serviceAccountId string, apiKeyId string, actor domain.AuditActor) error
+
+	// Authenticate returns the active service account and the key, nil when the key is not
+	// valid, expired or revoked.
+	Authenticate(key string) (*domain.ServiceAccount, *domain.ApiKey, error)
+}
+
+type domainServiceAccountService struct {
+	serviceAccountRepository repository.ServiceAccountRepository
+	apiKeyRepository         repository.ApiKeyRepository
+	permissionRepository     repository.PermissionRepository
+	auditService             AuditService
+	log                      *zap.Logger
+}
+
+func NewDomainServiceAccountService(
+	serviceAccountRepository repository.ServiceAccountRepository,
+	apiKeyRepository repository.ApiKeyRepository,
+	permissionRepository repository.PermissionRepository,
+	auditService AuditService,
+	log *zap.Logger,
+) ServiceAccountService {
+	return &domainServiceAccountService{serviceAccountRepository, apiKeyRepository, permissionRepository, auditService, log}
+}
+
+func (port *domainServiceAccountService) FindAll() ([]domain.ServiceAccount, error) {
+	return port.serviceAccountRepository.FindAll()
+}
+
+func (port *domainServiceAccountService) FindById(id string) (*domain.ServiceAccount, error) {
+	return port.serviceAccountRepository.FindById(id)
+}
+
+func (port *domainServiceAccountService) Save(input dto.ServiceAccountSaveDto, actor domain.AuditActor) (*domain.ServiceAccount, error) {
+	serviceAccount := domain.ServiceAccount{
+		CreationDate: time.Now(),
+	}
+	var previousServiceAccount *domain.ServiceAccount
+	if input.Id != "" {
+		existing, err := port.mustFindServiceAccount(input.Id)
+		if err != nil {
+			return nil, err
+		}
+		previousServiceAccount = existing
+		serviceAccount = *existing
+	}
+
+	withName, err := port.serviceAccountRepository.FindByName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if withName != nil && (serviceAccount.Id == nil || *withName.Id != *serviceAccount.Id) {
+		return nil, exception.NewServiceAccountNameNotAvailableException(input.Name)
+	}
+
+	serviceAccount.PermissionsCodes = []int{}
+	for _, permissionCode := range input.PermissionsCodes {
+		if slices.Contains(serviceAccount.PermissionsCodes, permissionCode) {
+			continue
+		}
+		permission, err := port.permissionRepository.FindByCode(permissionCode)
+		if err != nil {
+			return nil, err
+		}
+		if permission == nil {
+			return nil, exception.NewPermissionNotFoundException(permissionCode)
+		}
+		serviceAccount.PermissionsCodes = append(serviceAccount.PermissionsCodes, permissionCode)
+	}
+	serviceAccount.Name = input.Name
+	serviceAccount.Description = input.Description
+	serviceAccount.Active = input.Active
+
+	savedServiceAccount, err := port.serviceAccountRepository.Save(serviceAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionServiceAccountSave, domain.AuditTargetServiceAccount,
+		savedServiceAccount.Id.Hex(), previousServiceAccount, savedServiceAccount)
+	return savedServiceAccount, nil
+}
+
+func (port *domainServiceAccountService) Delete(id string, actor domain.AuditActor) error {
+	serviceAccount, err := port.mustFindServiceAccount(id)
+	if err != nil {
+		return err
+	}
+	err = port.apiKeyRepository.RevokeByServiceAccountId(*serviceAccount.Id)
+	if err != nil {
+		return err
+	}
+	err = port.serviceAccountRepository.Delete(*serviceAccount.Id)
+	if err != nil {
+		return err
+	}
+
+	port.auditService.Record(actor, domain.AuditActionServiceAccountDelete, domain.AuditTargetServiceAccount,
+		serviceAccount.Id.Hex(), serviceAccount, nil)
+	return nil
+}
+
+func (port *domainServiceAccountService) FindApiKeys(serviceAccountId string) ([]domain.ApiKey, error) {
+	serviceAccount, err := port.mustFindServiceAccount(serviceAccountId)
+	if err != nil {
+		return nil, err
+	}
+	return port.apiKeyRepository.FindByServiceAccountId(*serviceAccount.Id)
+}
+
+func (port *domainServiceAccountService) CreateApiKey(serviceAccountId string, input dto.ApiKeyCreateDto, actor domain.AuditActor) (*dto.ApiKeyCreatedDto, error) {
+	serviceAccount, err := port.mustFindServiceAccount(serviceAccountId)
+	if err != nil {
+		return nil, err
+	}
+	for _, permissionCode := range input.PermissionsCodes {
+		if !slices.Contains(serviceAccount.PermissionsCodes, permissionCode) {
+			return nil, exception.NewApiKeyPermissionNotAllowedException(permissionCode)
+		}
+	}
+
+	prefix, err := randomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return nil, err
+	}
+	key := ApiKeyTokenPrefix + prefix + "." + secret
+
+	now := time.Now()
+	expirationDate := now.AddDate(0, 0, input.ExpirationDays)
+	permissionsCodes := slices.Clone(input.PermissionsCodes)
+	if permissionsCodes == nil {
+		permissionsCodes = []int{}
+	}
+	apiKey, err := port.apiKeyRepository.Save(domain.ApiKey{
+		ServiceAccountId: *serviceAccount.Id,
+		Name:             input.Name,
+		Prefix:           prefix,
+		KeyHash:          hashToken(key),
+		PermissionsCodes: permissionsCodes,
+		CreationDate:     now,
+		ExpirationDate:   &expirationDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	port.log.Info("api key created",
+		zap.String("serviceAccount", serviceAccount.Name), zap.String("prefix", prefix))
+	// the key hash is not in the json, it is not recorded
+	port.auditService.Record(actor, domain.AuditActionApiKeyCreate, domain.AuditTargetApiKey,
+		apiKey.Id.Hex(), nil, apiKey)
+	return &dto.ApiKeyCreatedDto{ApiKey: *apiKey, Key: key}, nil
+}
+
+func (port *domainServiceAccountService) RevokeApiKey(serviceAccountId string, apiKeyId string, actor domain.AuditActor) error {
+	serviceAccount, err := port.mustFindServiceAccount(serviceAccountId)
+	if err != nil {
+		return err
+	}
+	objectId, err := primitive.ObjectIDFromHex(apiKeyId)
+	if err != nil {
+		return exception.NewApiKeyNotFoundException(apiKeyId)
+	}
+	revoked, err := port.apiKeyRepository.Revoke(*serviceAccount.Id, objectId)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return exception.NewApiKeyNotFoundException(apiKeyId)
+	}
+
+	port.auditService.Record(actor, domain.AuditActionApiKeyRevoke, domain.AuditTargetApiKey,
+		apiKeyId, nil, nil)
+	return nil
+}
+
+func (port *domainServiceAccountService) Authenticate(key string) (*domain.ServiceAccount, *domain.ApiKey, error) {
+	prefix, ok := parseApiKeyPrefix(key)
+	if !ok {
+		return nil, nil, nil
+	}
+	apiKey, err := port.apiKeyRepository.FindByPrefix(prefix)
+	if err != nil || apiKey == nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashToken(key))) != 1 || !apiKey.IsUsable(time.Now()) {
+		return nil, nil, nil
+	}
+
+	serviceAccount, err := port.serviceAccountRepository.FindById(apiKey.ServiceAccountId.Hex())
+	if err != nil || serviceAccount == nil || !serviceAccount.Active {
+		return nil, nil, err
+	}
+	return serviceAccount, apiKey, nil
+}
+
+// private
+
+func (port *domainServiceAccountService) mustFindServiceAccount(id string) (*domain.ServiceAccount, error) {
+	serviceAccount, err := port.serviceAccountRepository.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if serviceAccount == nil {
+		return nil, exception.NewServiceAccountNotFoundException(id)
+	}
+	return serviceAccount, nil
+}
+
+func parseApiKeyPrefix(key string) (string, bool) {
+	if !strings.HasPrefix(key, ApiKeyTokenPrefix) {
+		return "", false
+	}
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(key, ApiKeyTokenPrefix), ".")
+	if !found || len(prefix) != 2*apiKeyPrefixBytes || len(secret) != 2*apiKeySecretBytes {
+		return "", false
+	}
+	return prefix, true
+}
+
+func randomHex(size int) (string, error) {
+	value := make([]byte, size)
+	_, err := rand.Read(value)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(value), nil
+}
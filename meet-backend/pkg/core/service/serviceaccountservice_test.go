@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServiceAccountAndApiKeysChangesAreAudited(t *testing.T) {
+	auditService := &fakeAuditService{}
+	serviceAccountService := NewDomainServiceAccountService(newFakeServiceAccountRepository(), &fakeApiKeyRepository{},
+		newFakePermissionRepository(), auditService, zap.NewNop())
+	actor := domain.AuditActor{PersonId: "admin", Ip: "127.0.0.1"}
+
+	serviceAccount, err := serviceAccountService.Save(dto.ServiceAccountSaveDto{
+		Name:             "billing",
+		PermissionsCodes: []int{domain.PermissionCodeManageSystem},
+		Active:           true,
+	}, actor)
+	require.NoError(t, err)
+	serviceAccountId := serviceAccount.Id.Hex()
+
+	created, err := serviceAccountService.CreateApiKey(serviceAccountId, dto.ApiKeyCreateDto{Name: "job", ExpirationDays: 30}, actor)
+	require.NoError(t, err)
+	apiKeyId := created.ApiKey.Id.Hex()
+	require.NoError(t, serviceAccountService.RevokeApiKey(serviceAccountId, apiKeyId, actor))
+
+	// the revoked key is not found again, nothing is audited
+	assert.Error(t, serviceAccountService.RevokeApiKey(serviceAccountId, apiKeyId, actor))
+
+	require.NoError(t, serviceAccountService.Delete(serviceAccountId, actor))
+
+	assert.Equal(t, []fakeAuditEntry{
+		{actor, domain.AuditActionServiceAccountSave, serviceAccountId},
+		{actor, domain.AuditActionApiKeyCreate, apiKeyId},
+		{actor, domain.AuditActionApiKeyRevoke, apiKeyId},
+		{actor, domain.AuditActionServiceAccountDelete, serviceAccountId},
+	}, auditService.entries)
+}
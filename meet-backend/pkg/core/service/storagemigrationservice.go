@@ -0,0 +1,249 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const StorageMigrationDefaultBatchSize = 50
+
+type StorageMigrationOptions struct {
+	TargetStorageType string
+	BatchSize         int
+	// delete the file from the source storage after the metadata points to the target
+	DeleteSource bool
+	// only count the files to migrate
+	DryRun bool
+}
+
+type StorageMigrationService interface {
+	// MigrateFiles copies the uploaded files of the other storages to the target storage,
+	// verifies the copies and moves the metadata in batches. The migrated files are not
+	// selected again, so a migration interrupted can be executed again for resume it.
+	MigrateFiles(options StorageMigrationOptions) (*dto.StorageMigrationReportDto, error)
+}
+
+type domainStorageMigrationService struct {
+	storageService         StorageService
+	fileMetaDataRepository repository.FileMetaDataRepository
+	fileBlobRepository     repository.FileBlobRepository
+	log                    *zap.Logger
+}
+
+func NewDomainStorageMigrationService(
+	storageService StorageService,
+	fileMetaDataRepository repository.FileMetaDataRepository,
+	fileBlobRepository repository.FileBlobRepository,
+	log *zap.Logger,
+) StorageMigrationService {
+	return &domainStorageMigrationService{storageService, fileMetaDataRepository, fileBlobRepository, log}
+}
+
+func (port *domainStorageMigrationService) MigrateFiles(options StorageMigrationOptions) (*dto.StorageMigrationReportDto, error) {
+	if !slices.Contains(port.storageService.GetStorageTypes(), options.TargetStorageType) {
+		return nil, fmt.Errorf("the target storage %s is not configured, the storages are: %v",
+			options.TargetStorageType, port.storageService.GetStorageTypes())
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = StorageMigrationDefaultBatchSize
+	}
+	includeLegacy := port.storageService.GetLegacyStorageType() != options.TargetStorageType
+
+	report := dto.StorageMigrationReportDto{
+		TargetStorageType: options.TargetStorageType,
+		DryRun:            options.DryRun,
+	}
+
+	var afterId *primitive.ObjectID
+	for {
+		files, err := port.fileMetaDataRepository.FindUploadedNotInStorage(
+			options.TargetStorageType, includeLegacy, afterId, options.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			break
+		}
+		afterId = files[len(files)-1].Id
+		report.Batches++
+
+		if options.DryRun {
+			for _, file := range files {
+				report.MigratedFiles++
+				report.CopiedBytes += file.Size
+			}
+			continue
+		}
+
+		err = port.migrateBatch(files, options, &report)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &report, nil
+}
+
+// private
+
+func (port *domainStorageMigrationService) migrateBatch(files []domain.FileMetaData, options StorageMigrationOptions, report *dto.StorageMigrationReportDto) error {
+	sources := make([]domain.FileMetaData, 0, len(files))
+	targets := make([]domain.FileMetaData, 0, len(files))
+	for _, file := range files {
+		target, size, err := port.copyFile(file, options.TargetStorageType)
+		if err != nil {
+			port.log.Warn("file not migrated", zap.String("hash", file.Hash), zap.Error(err))
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", file.Hash, err.Error()))
+			continue
+		}
+		sources = append(sources, file)
+		targets = append(targets, *target)
+		report.CopiedBytes += size
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	err := port.fileMetaDataRepository.UpdateStorage(targets)
+	if err != nil {
+		return err
+	}
+	report.MigratedFiles += len(targets)
+	port.log.Info("storage migration batch done",
+		zap.String("target", options.TargetStorageType), zap.Int("files", len(targets)))
+
+	// the metadata already points to the target, a failed delete only leaves an unused copy
+	for _, source := range sources {
+		err = port.releaseSource(source, options.DeleteSource)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: source not released: %s", source.Hash, err.Error()))
+		}
+	}
+	return nil
+}
+
+// copyFile writes the file in the target storage and reads it back for compare the checksums,
+// the storages report checksums of different kinds so they are calculated here. The files
+// stored as blobs are copied only once to the blob of the target storage.
+func (port *domainStorageMigrationService) copyFile(source domain.FileMetaData, targetStorageType string) (*domain.FileMetaData, int64, error) {
+	target := source
+	target.StorageType = targetStorageType
+	target.DownloadUrl = nil
+
+	if source.ContentDigest != "" {
+		blob, err := port.fileBlobRepository.AcquireBlob(domain.FileBlob{
+			Digest:      source.ContentDigest,
+			StorageType: targetStorageType,
+			Path:        source.Path,
+			Size:        source.Size,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if blob.DeletingDate != nil {
+			return nil, 0, exception.NewFileBlobDeletingException(blob.Digest)
+		}
+		target.Path = blob.Path
+
+		stat, err := port.storageService.StatFile(target)
+		if err != nil {
+			return nil, 0, port.releaseTargetBlob(target, err)
+		}
+		if stat != nil {
+			// already copied by other file with the same content
+			return &target, 0, nil
+		}
+	}
+
+	size, err := port.copyContent(source, target)
+	if err != nil {
+		if target.ContentDigest != "" {
+			return nil, 0, port.releaseTargetBlob(target, err)
+		}
+		return nil, 0, err
+	}
+	return &target, size, nil
+}
+
+// copyContent streams the source to the target, the files can be too large for the memory
+func (port *domainStorageMigrationService) copyContent(source domain.FileMetaData, target domain.FileMetaData) (int64, error) {
+	reader, err := port.storageService.ReadFile(source)
+	if err != nil {
+		return 0, fmt.Errorf("error reading the source: %w", err)
+	}
+	defer reader.Close()
+
+	sourceHash := sha256.New()
+	counter := &countingWriter{}
+	contentType := source.GetContentType()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	err = port.storageService.PutFileStream(target, io.TeeReader(reader, io.MultiWriter(sourceHash, counter)), contentType)
+	if err != nil {
+		return 0, fmt.Errorf("error writing the target: %w", err)
+	}
+
+	targetChecksum, err := port.checksum(target)
+	if err != nil {
+		return 0, fmt.Errorf("error reading the target: %w", err)
+	}
+	if !bytes.Equal(targetChecksum, sourceHash.Sum(nil)) {
+		return 0, fmt.Errorf("the checksum of the copy does not match")
+	}
+	return counter.count, nil
+}
+
+func (port *domainStorageMigrationService) releaseTargetBlob(target domain.FileMetaData, cause error) error {
+	err := releaseFileBlob(port.fileBlobRepository, port.storageService, target, true)
+	return errors.Join(cause, err)
+}
+
+// releaseSource the blobs of the source storage lose the reference of the migrated file, the
+// object is deleted (with deleteSource) only when no other file of the source uses it.
+func (port *domainStorageMigrationService) releaseSource(source domain.FileMetaData, deleteSource bool) error {
+	if source.ContentDigest == "" {
+		if !deleteSource {
+			return nil
+		}
+		return port.storageService.DeleteFile(source)
+	}
+
+	return releaseFileBlob(port.fileBlobRepository, port.storageService, source, deleteSource)
+}
+
+func (port *domainStorageMigrationService) checksum(metaData domain.FileMetaData) ([]byte, error) {
+	reader, err := port.storageService.ReadFile(metaData)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, reader)
+	if err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// countingWriter counts the bytes copied to the target
+type countingWriter struct {
+	count int64
+}
+
+func (writer *countingWriter) Write(p []byte) (int, error) {
+	writer.count += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/util/hashutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+type testStorageMigration struct {
+	StorageMigrationService
+	source           *fakeStorageRepository
+	target           *fakeStorageRepository
+	fileMetaDataRepo *fakeFileMetaDataRepository
+	fileBlobRepo     *fakeFileBlobRepository
+}
+
+func newTestStorageMigration() *testStorageMigration {
+	source := newFakeStorageRepository("source")
+	target := newFakeStorageRepository("target")
+	fileMetaDataRepo := newFakeFileMetaDataRepository()
+	fileBlobRepo := newFakeFileBlobRepository()
+	storageService := NewDomainStorageService(target, source.GetStorageType(), source)
+	migrationService := NewDomainStorageMigrationService(storageService, fileMetaDataRepo, fileBlobRepo, zap.NewNop())
+	return &testStorageMigration{migrationService, source, target, fileMetaDataRepo, fileBlobRepo}
+}
+
+// addSourceFile stores an uploaded file in the source storage, as blob when shared
+func (port *testStorageMigration) addSourceFile(t *testing.T, hash string, content string, shared bool) domain.FileMetaData {
+	id := primitive.NewObjectID()
+	file := domain.FileMetaData{
+		Id:          &id,
+		Hash:        hash,
+		Path:        "uploads/" + hash + ".txt",
+		Uploaded:    true,
+		StorageType: port.source.GetStorageType(),
+		Size:        int64(len(content)),
+	}
+	if shared {
+		digest := hashutil.SHA256HexEncoding(content)
+		blob, err := port.fileBlobRepo.AcquireBlob(domain.FileBlob{
+			Digest:      digest,
+			StorageType: port.source.GetStorageType(),
+			Path:        domain.FileBlobPath(digest, ".txt"),
+			Size:        file.Size,
+		})
+		require.NoError(t, err)
+		file.Path = blob.Path
+		file.ContentDigest = digest
+	}
+	port.source.objects[file.Path] = []byte(content)
+	port.fileMetaDataRepo.files[hash] = file
+	return file
+}
+
+func TestMigrateFiles(t *testing.T) {
+	migration := newTestStorageMigration()
+	migration.addSourceFile(t, "own", "own content", false)
+	first := migration.addSourceFile(t, "first", "shared content", true)
+	migration.addSourceFile(t, "second", "shared content", true)
+
+	report, err := migration.MigrateFiles(StorageMigrationOptions{
+		TargetStorageType: "target",
+		BatchSize:         2,
+		DeleteSource:      true,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 3, report.MigratedFiles)
+	assert.Equal(t, 2, report.Batches)
+	// the shared content is copied once
+	assert.Equal(t, int64(len("own content")+len("shared content")), report.CopiedBytes)
+
+	for _, hash := range []string{"own", "first", "second"} {
+		migrated, _ := migration.fileMetaDataRepo.FindByHash(hash)
+		assert.Equal(t, "target", migrated.StorageType)
+		assert.Contains(t, migration.target.objects, migrated.Path)
+	}
+	assert.Equal(t, []byte("shared content"), migration.target.objects[first.Path])
+	assert.Len(t, migration.target.objects, 2)
+
+	// the source is released
+	assert.Empty(t, migration.source.objects)
+	assert.Nil(t, migration.fileBlobRepo.find("source", first.ContentDigest))
+	assert.Equal(t, 2, migration.fileBlobRepo.find("target", first.ContentDigest).RefCount)
+}
+
+func TestMigrateFilesDryRun(t *testing.T) {
+	migration := newTestStorageMigration()
+	migration.addSourceFile(t, "own", "own content", false)
+
+	report, err := migration.MigrateFiles(StorageMigrationOptions{TargetStorageType: "target", DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.MigratedFiles)
+	assert.Empty(t, migration.target.objects)
+
+	notMigrated, _ := migration.fileMetaDataRepo.FindByHash("own")
+	assert.Equal(t, "source", notMigrated.StorageType)
+}
+
+func TestMigrateFilesFailedCopy(t *testing.T) {
+	migration := newTestStorageMigration()
+	missing := migration.addSourceFile(t, "missing", "lost content", true)
+	delete(migration.source.objects, missing.Path)
+
+	report, err := migration.MigrateFiles(StorageMigrationOptions{TargetStorageType: "target", DeleteSource: true})
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.MigratedFiles)
+	assert.Len(t, report.Errors, 1)
+
+	// the file stays in the source and the blob of the target is released
+	notMigrated, _ := migration.fileMetaDataRepo.FindByHash("missing")
+	assert.Equal(t, "source", notMigrated.StorageType)
+	assert.Nil(t, migration.fileBlobRepo.find("target", missing.ContentDigest))
+	assert.Equal(t, 1, migration.fileBlobRepo.find("source", missing.ContentDigest).RefCount)
+}
+
+func TestMigrateFilesUnknownTarget(t *testing.T) {
+	migration := newTestStorageMigration()
+
+	_, err := migration.MigrateFiles(StorageMigrationOptions{TargetStorageType: "other"})
+	assert.Error(t, err)
+}
@@ -1,40 +1,228 @@
 package service
 
 import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
 	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/repository"
 )
 
 type StorageService interface {
+	// GetStorageType returns the storage where the new files are written
 	GetStorageType() string
 
+	// ResolveStorageType returns the storage where the file is, the files written before
+	// the storage type was recorded are in the legacy storage.
+	ResolveStorageType(metaData domain.FileMetaData) string
+
+	GetStorageTypes() []string
+
+	GetLegacyStorageType() string
+
 	GetFileUploadUrl(metaData domain.FileMetaData) (string, error)
 
 	GetFileDownloadUrl(metaData domain.FileMetaData) (string, error)
 
+	GetFileTemporaryDownloadUrl(metaData domain.FileMetaData, expiration time.Duration) (string, error)
+
 	DeleteFile(metaData domain.FileMetaData) error
+
+	StatFile(metaData domain.FileMetaData) (*domain.FileStat, error)
+
+	ReadFileHead(metaData domain.FileMetaData, length int) ([]byte, error)
+
+	ReadFile(metaData domain.FileMetaData) (io.ReadCloser, error)
+
+	PutFile(metaData domain.FileMetaData, content []byte, contentType string) error
+
+	PutFileStream(metaData domain.FileMetaData, reader io.Reader, contentType string) error
+
+	CopyFile(source domain.FileMetaData, target domain.FileMetaData) error
+
+	InitiateMultipartUpload(metaData domain.FileMetaData) (string, error)
+
+	GetMultipartUploadPartUrl(metaData domain.FileMetaData, uploadId string, partNumber int) (string, error)
+
+	CompleteMultipartUpload(metaData domain.FileMetaData, uploadId string, parts []domain.FileUploadPart) error
+
+	AbortMultipartUpload(metaData domain.FileMetaData, uploadId string) error
 }
 
 type domainStorageService struct {
-	storageRepository repository.StorageRepository
+	defaultStorage    repository.StorageRepository
+	legacyStorageType string
+	storages          map[string]repository.StorageRepository
 }
 
-func NewDomainStorageService(storageRepository repository.StorageRepository) StorageService {
-	return &domainStorageService{storageRepository}
+// NewDomainStorageService the new files are written in the default storage and every file is
+// read from the storage recorded in its metadata, so the files of the other storages keep
+// working while they are migrated.
+func NewDomainStorageService(
+	defaultStorage repository.StorageRepository,
+	legacyStorageType string,
+	otherStorages ...repository.StorageRepository,
+) StorageService {
+	storages := map[string]repository.StorageRepository{
+		defaultStorage.GetStorageType(): defaultStorage,
+	}
+	for _, storage := range otherStorages {
+		storages[storage.GetStorageType()] = storage
+	}
+	if legacyStorageType == "" {
+		legacyStorageType = defaultStorage.GetStorageType()
+	}
+	return &domainStorageService{defaultStorage, legacyStorageType, storages}
 }
 
 func (port *domainStorageService) GetStorageType() string {
-	return port.storageRepository.GetStorageType()
+	return port.defaultStorage.GetStorageType()
+}
+
+func (port *domainStorageService) ResolveStorageType(metaData domain.FileMetaData) string {
+	if metaData.StorageType == "" {
+		return port.legacyStorageType
+	}
+	return metaData.StorageType
+}
+
+func (port *domainStorageService) GetStorageTypes() []string {
+	storageTypes := make([]string, 0, len(port.storages))
+	for storageType := range port.storages {
+		storageTypes = append(storageTypes, storageType)
+	}
+	sort.Strings(storageTypes)
+	return storageTypes
+}
+
+func (port *domainStorageService) GetLegacyStorageType() string {
+	return port.legacyStorageType
 }
 
 func (port *domainStorageService) DeleteFile(metaData domain.FileMetaData) error {
-	return port.storageRepository.DeleteFile(metaData)
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return err
+	}
+	return storage.DeleteFile(metaData)
+}
+
+func (port *domainStorageService) StatFile(metaData domain.FileMetaData) (*domain.FileStat, error) {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StatFile(metaData)
+}
+
+func (port *domainStorageService) ReadFileHead(metaData domain.FileMetaData, length int) ([]byte, error) {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return nil, err
+	}
+	return storage.ReadFileHead(metaData, length)
+}
+
+func (port *domainStorageService) ReadFile(metaData domain.FileMetaData) (io.ReadCloser, error) {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return nil, err
+	}
+	return storage.ReadFile(metaData)
+}
+
+func (port *domainStorageService) PutFile(metaData domain.FileMetaData, content []byte, contentType string) error {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return err
+	}
+	return storage.PutFile(metaData, content, contentType)
+}
+
+func (port *domainStorageService) PutFileStream(metaData domain.FileMetaData, reader io.Reader, contentType string) error {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return err
+	}
+	return storage.PutFileStream(metaData, reader, contentType)
+}
+
+func (port *domainStorageService) CopyFile(source domain.FileMetaData, target domain.FileMetaData) error {
+	if port.ResolveStorageType(source) != port.ResolveStorageType(target) {
+		return fmt.Errorf("the files %s and %s are in different storages", source.Hash, target.Hash)
+	}
+	storage, err := port.storageFor(source)
+	if err != nil {
+		return err
+	}
+	return storage.CopyFile(source, target)
+}
+
+func (port *domainStorageService) InitiateMultipartUpload(metaData domain.FileMetaData) (string, error) {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return "", err
+	}
+	return storage.InitiateMultipartUpload(metaData)
+}
+
+func (port *domainStorageService) GetMultipartUploadPartUrl(metaData domain.FileMetaData, uploadId string, partNumber int) (string, error) {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return "", err
+	}
+	return storage.GetMultipartUploadPartUrl(metaData, uploadId, partNumber)
+}
+
+func (port *domainStorageService) CompleteMultipartUpload(metaData domain.FileMetaData, uploadId string, parts []domain.FileUploadPart) error {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return err
+	}
+	return storage.CompleteMultipartUpload(metaData, uploadId, parts)
+}
+
+func (port *domainStorageService) AbortMultipartUpload(metaData domain.FileMetaData, uploadId string) error {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return err
+	}
+	return storage.AbortMultipartUpload(metaData, uploadId)
 }
 
 func (port *domainStorageService) GetFileDownloadUrl(metaData domain.FileMetaData) (string, error) {
-	return port.storageRepository.GetFileDownloadUrl(metaData)
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return "", err
+	}
+	return storage.GetFileDownloadUrl(metaData)
+}
+
+func (port *domainStorageService) GetFileTemporaryDownloadUrl(metaData domain.FileMetaData, expiration time.Duration) (string, error) {
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return "", err
+	}
+	return storage.GetFileTemporaryDownloadUrl(metaData, expiration)
 }
 
 func (port *domainStorageService) GetFileUploadUrl(metaData domain.FileMetaData) (string, error) {
-	return port.storageRepository.GetFileUploadUrl(metaData)
+	storage, err := port.storageFor(metaData)
+	if err != nil {
+		return "", err
+	}
+	return storage.GetFileUploadUrl(metaData)
+}
+
+// private
+
+func (port *domainStorageService) storageFor(metaData domain.FileMetaData) (repository.StorageRepository, error) {
+	storageType := port.ResolveStorageType(metaData)
+	storage, exists := port.storages[storageType]
+	if !exists {
+		return nil, fmt.Errorf("the storage %s of the file %s is not configured", storageType, metaData.Hash)
+	}
+	return storage, nil
 }
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/dto"
+	"github.com/erodriguezg/meet/pkg/core/exception"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"github.com/erodriguezg/meet/pkg/util/imageutil"
+	"github.com/erodriguezg/meet/pkg/util/watermark"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// person id + payment order id
+	watermarkPayloadLength = 24
+	watermarkJpegQuality   = 92
+)
+
+type WatermarkService interface {
+	// GetWatermarkedFileHash returns the hash of the copy of the resource for the person,
+	// generated the first time and reused after.
+	GetWatermarkedFileHash(sourceFileHash string, packId string, personId string) (string, error)
+
+	ExtractWatermark(reader io.Reader) (*dto.WatermarkExtractDto, error)
+}
+
+type domainWatermarkService struct {
+	personService          PersonService
+	fileService            FileService
+	paymentOrderRepository repository.PaymentOrderRepository
+	repository             repository.WatermarkedFileRepository
+	key                    []byte
+}
+
+func NewDomainWatermarkService(
+	personService PersonService,
+	fileService FileService,
+	paymentOrderRepository repository.PaymentOrderRepository,
+	repository repository.WatermarkedFileRepository,
+	key []byte,
+) WatermarkService {
+	if len(key) == 0 {
+		panic("the watermark key is required")
+	}
+	return &domainWatermarkService{
+		personService,
+		fileService,
+		paymentOrderRepository,
+		repository,
+		key,
+	}
+}
+
+func (port *domainWatermarkService) GetWatermarkedFileHash(sourceFileHash string, packId string, personId string) (string, error) {
+	existing, err := port.repository.FindBySourceFileHashAndPersonId(sourceFileHash, personId)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return existing.FileHash, nil
+	}
+
+	sourceMetaData, err := port.fileService.FindByHash(sourceFileHash)
+	if err != nil {
+		return "", err
+	}
+	if sourceMetaData == nil {
+		return "", fmt.Errorf("error at watermarkService: file not found for hash: %s", sourceFileHash)
+	}
+
+	personObjectId, err := primitive.ObjectIDFromHex(personId)
+	if err != nil {
+		return "", err
+	}
+	packObjectId, err := primitive.ObjectIDFromHex(packId)
+	if err != nil {
+		return "", err
+	}
+
+	// packs given by an admin have no order, the order id is encoded with zeros
+	paymentOrder, err := port.paymentOrderRepository.FindCapturedByOwnerAndPackId(personId, packId)
+	if err != nil {
+		return "", err
+	}
+	var paymentOrderId *primitive.ObjectID
+	payload := make([]byte, 0, watermarkPayloadLength)
+	payload = append(payload, personObjectId[:]...)
+	if paymentOrder != nil && paymentOrder.Id != nil {
+		paymentOrderId = paymentOrder.Id
+		payload = append(payload, paymentOrder.Id[:]...)
+	} else {
+		payload = append(payload, primitive.NilObjectID[:]...)
+	}
+
+	content, contentType, err := port.watermarkContent(sourceMetaData, payload)
+	if err != nil {
+		return "", err
+	}
+
+	extension := path.Ext(sourceMetaData.Path)
+	watermarkedPath := fmt.Sprintf("%s-wm-%s%s", strings.TrimSuffix(sourceMetaData.Path, extension), personId, extension)
+
+	watermarkedMetaData, err := port.fileService.CreateGenerated(sourceFileHash, watermarkedPath,
+		[]string{sourceFileHash, personId, "watermark"}, sourceMetaData.TypeCode, content, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = port.repository.Save(domain.WatermarkedFile{
+		SourceFileHash: sourceFileHash,
+		FileHash:       watermarkedMetaData.Hash,
+		PackId:         packObjectId,
+		PersonId:       personObjectId,
+		PaymentOrderId: paymentOrderId,
+		CreationDate:   time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return watermarkedMetaData.Hash, nil
+}
+
+func (port *domainWatermarkService) ExtractWatermark(reader io.Reader) (*dto.WatermarkExtractDto, error) {
+	img, _, err := imageutil.Decode(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := watermark.Extract(img, watermarkPayloadLength, port.key)
+	if err != nil {
+		return nil, exception.NewWatermarkNotFoundException()
+	}
+
+	personId := primitive.ObjectID(payload[:12])
+	paymentOrderId := primitive.ObjectID(payload[12:])
+
+	output := dto.WatermarkExtractDto{
+		PersonId: personId.Hex(),
+	}
+
+	person, err := port.personService.FindById(personId.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if person != nil {
+		output.PersonEmail = &person.Email
+	}
+
+	if !paymentOrderId.IsZero() {
+		paymentOrderIdHex := paymentOrderId.Hex()
+		output.PaymentOrderId = &paymentOrderIdHex
+
+		paymentOrder, err := port.paymentOrderRepository.FindById(paymentOrderIdHex)
+		if err != nil {
+			return nil, err
+		}
+		if paymentOrder != nil {
+			output.OrderId = &paymentOrder.OrderId
+		}
+	}
+
+	return &output, nil
+}
+
+// private
+
+// watermarkContent the png files keep the format (lossless), the jpeg are encoded with high quality
+func (port *domainWatermarkService) watermarkContent(sourceMetaData *domain.FileMetaData, payload []byte) ([]byte, string, error) {
+	reader, err := port.fileService.ReadContent(sourceMetaData.Hash)
+	if err != nil {
+		return nil, "", err
+	}
+	defer reader.Close()
+
+	sourceImage, _, err := imageutil.Decode(reader)
+	if err != nil {
+		return nil, "", err
+	}
+
+	watermarked, err := watermark.Embed(sourceImage, payload, port.key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if sourceMetaData.TypeCode == domain.PackItemTypeCodeImgPng {
+		content, err := imageutil.EncodePng(watermarked)
+		return content, "image/png", err
+	}
+	content, err := imageutil.EncodeJpeg(watermarked, watermarkJpegQuality)
+	return content, "image/jpeg", err
+}
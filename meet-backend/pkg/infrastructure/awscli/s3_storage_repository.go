@@ -1,18 +1,27 @@
 package awscli
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/repository"
 	"go.uber.org/zap"
 )
 
+const multipartPartUrlExpiration = time.Hour
+
 type S3StorageConfig struct {
 	RestEndPoint           string
 	AccessKey              string
@@ -74,6 +83,18 @@ func (port *s3Storage) GetFileDownloadUrl(fmd domain.FileMetaData) (string, erro
 	return url, nil
 }
 
+func (port *s3Storage) GetFileTemporaryDownloadUrl(fmd domain.FileMetaData, expiration time.Duration) (string, error) {
+	req, _ := port.s3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(port.config.BucketName),
+		Key:    aws.String(fmd.Path),
+	})
+	url, err := req.Presign(expiration)
+	if err != nil {
+		return "", fmt.Errorf("can't preSign url for download: %w", err)
+	}
+	return url, nil
+}
+
 func (port *s3Storage) GetFileUploadUrl(fmd domain.FileMetaData) (string, error) {
 	req, _ := port.s3Client.PutObjectRequest(&s3.PutObjectInput{
 		Bucket: aws.String(port.config.BucketName),
@@ -86,4 +107,143 @@ func (port *s3Storage) GetFileUploadUrl(fmd domain.FileMetaData) (string, error)
 	return url, nil
 }
 
+func (port *s3Storage) StatFile(fmd domain.FileMetaData) (*domain.FileStat, error) {
+	output, err := port.s3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(port.config.BucketName),
+		Key:    aws.String(fmd.Path),
+	})
+	if err != nil {
+		if requestErr, ok := err.(awserr.RequestFailure); ok && requestErr.StatusCode() == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't stat file: %w", err)
+	}
+	return &domain.FileStat{
+		Size:        aws.Int64Value(output.ContentLength),
+		ContentType: aws.StringValue(output.ContentType),
+		Checksum:    "etag:" + strings.Trim(aws.StringValue(output.ETag), `"`),
+	}, nil
+}
+
+func (port *s3Storage) ReadFileHead(fmd domain.FileMetaData, length int) ([]byte, error) {
+	output, err := port.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(port.config.BucketName),
+		Key:    aws.String(fmd.Path),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", length-1)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read file head: %w", err)
+	}
+	defer output.Body.Close()
+	return io.ReadAll(io.LimitReader(output.Body, int64(length)))
+}
+
+func (port *s3Storage) ReadFile(fmd domain.FileMetaData) (io.ReadCloser, error) {
+	output, err := port.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(port.config.BucketName),
+		Key:    aws.String(fmd.Path),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read file: %w", err)
+	}
+	return output.Body, nil
+}
+
+func (port *s3Storage) PutFile(fmd domain.FileMetaData, content []byte, contentType string) error {
+	_, err := port.s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(port.config.BucketName),
+		Key:         aws.String(fmd.Path),
+		Body:        bytes.NewReader(content),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("can't put file: %w", err)
+	}
+	return nil
+}
+
+// PutFileStream the uploader sends the content in parts, the put object needs a seeker
+func (port *s3Storage) PutFileStream(fmd domain.FileMetaData, reader io.Reader, contentType string) error {
+	uploader := s3manager.NewUploaderWithClient(port.s3Client)
+	_, err := uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(port.config.BucketName),
+		Key:         aws.String(fmd.Path),
+		Body:        reader,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("can't put file: %w", err)
+	}
+	return nil
+}
+
+func (port *s3Storage) CopyFile(source domain.FileMetaData, target domain.FileMetaData) error {
+	_, err := port.s3Client.CopyObject(&s3.CopyObjectInput{
+		Bucket:     aws.String(port.config.BucketName),
+		Key:        aws.String(target.Path),
+		CopySource: aws.String(url.PathEscape(port.config.BucketName + "/" + source.Path)),
+	})
+	if err != nil {
+		return fmt.Errorf("can't copy file: %w", err)
+	}
+	return nil
+}
+
+func (port *s3Storage) InitiateMultipartUpload(fmd domain.FileMetaData) (string, error) {
+	output, err := port.s3Client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(port.config.BucketName),
+		Key:    aws.String(fmd.Path),
+	})
+	if err != nil {
+		return "", fmt.Errorf("can't initiate multipart upload: %w", err)
+	}
+	return aws.StringValue(output.UploadId), nil
+}
+
+func (port *s3Storage) GetMultipartUploadPartUrl(fmd domain.FileMetaData, uploadId string, partNumber int) (string, error) {
+	req, _ := port.s3Client.UploadPartRequest(&s3.UploadPartInput{
+		Bucket:     aws.String(port.config.BucketName),
+		Key:        aws.String(fmd.Path),
+		UploadId:   aws.String(uploadId),
+		PartNumber: aws.Int64(int64(partNumber)),
+	})
+	url, err := req.Presign(multipartPartUrlExpiration)
+	if err != nil {
+		return "", fmt.Errorf("can't preSign url for upload part: %w", err)
+	}
+	return url, nil
+}
+
+func (port *s3Storage) CompleteMultipartUpload(fmd domain.FileMetaData, uploadId string, parts []domain.FileUploadPart) error {
+	completedParts := make([]*s3.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completedParts = append(completedParts, &s3.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int64(int64(part.PartNumber)),
+		})
+	}
+	_, err := port.s3Client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(port.config.BucketName),
+		Key:             aws.String(fmd.Path),
+		UploadId:        aws.String(uploadId),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completedParts},
+	})
+	if err != nil {
+		return fmt.Errorf("can't complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (port *s3Storage) AbortMultipartUpload(fmd domain.FileMetaData, uploadId string) error {
+	_, err := port.s3Client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(port.config.BucketName),
+		Key:      aws.String(fmd.Path),
+		UploadId: aws.String(uploadId),
+	})
+	if err != nil {
+		return fmt.Errorf("can't abort multipart upload: %w", err)
+	}
+	return nil
+}
+
 // privates
@@ -0,0 +1,31 @@
+package awscli_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/repository/repositorytest"
+	"github.com/erodriguezg/meet/pkg/infrastructure/awscli"
+	"go.uber.org/zap"
+)
+
+// TestS3StorageContract runs against a real bucket (ex: a local minio), only when
+// S3_CONTRACT_TEST_ENDPOINT is defined.
+func TestS3StorageContract(t *testing.T) {
+	endpoint := os.Getenv("S3_CONTRACT_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_CONTRACT_TEST_ENDPOINT not defined")
+	}
+
+	storage := awscli.NewS3StorageClient(awscli.S3StorageConfig{
+		RestEndPoint:           endpoint,
+		AccessKey:              os.Getenv("S3_CONTRACT_TEST_ACCESS_KEY"),
+		SecretAccessKey:        os.Getenv("S3_CONTRACT_TEST_SECRET_ACCESS_KEY"),
+		BucketName:             os.Getenv("S3_CONTRACT_TEST_BUCKET"),
+		Region:                 os.Getenv("S3_CONTRACT_TEST_REGION"),
+		PathStyleAccessEnabled: true,
+	}, zap.NewNop())
+
+	repositorytest.RunStorageRepositoryContract(t, storage, http.DefaultClient)
+}
@@ -1,10 +1,14 @@
 package dropboxcli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -94,6 +98,28 @@ func (port *storageDropbox) GetFileDownloadUrl(metaData domain.FileMetaData) (st
 	return port.cleanDownloadUrl(linkMetaData.Url), nil
 }
 
+// GetFileTemporaryDownloadUrl dropbox temporary links have a fixed lifetime of four hours,
+// so the expiration is ignored.
+func (port *storageDropbox) GetFileTemporaryDownloadUrl(metaData domain.FileMetaData, expiration time.Duration) (string, error) {
+
+	filePath := "/" + metaData.Path
+
+	port.log.Debug("GetFileTemporaryDownloadUrl inputs: ",
+		zap.String("filePath", filePath))
+
+	filesClient, err := port.getFilesClient()
+	if err != nil {
+		return "", err
+	}
+
+	temporaryLinkResponse, err := filesClient.GetTemporaryLink(files.NewGetTemporaryLinkArg(filePath))
+	if err != nil {
+		return "", err
+	}
+
+	return temporaryLinkResponse.Link, nil
+}
+
 func (port *storageDropbox) DeleteFile(metaData domain.FileMetaData) error {
 
 	filePath := "/" + metaData.Path
@@ -116,6 +142,142 @@ func (port *storageDropbox) DeleteFile(metaData domain.FileMetaData) error {
 	return nil
 }
 
+func (port *storageDropbox) StatFile(metaData domain.FileMetaData) (*domain.FileStat, error) {
+
+	filePath := "/" + metaData.Path
+
+	filesClient, err := port.getFilesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	metadataResponse, err := filesClient.GetMetadata(files.NewGetMetadataArg(filePath))
+	if err != nil {
+		if apiErr, ok := err.(files.GetMetadataAPIError); ok && apiErr.EndpointError != nil &&
+			apiErr.EndpointError.Path != nil && apiErr.EndpointError.Path.Tag == files.LookupErrorNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	fileMetadata, ok := metadataResponse.(*files.FileMetadata)
+	if !ok {
+		return nil, fmt.Errorf("the path %s is not a file", filePath)
+	}
+
+	return &domain.FileStat{
+		Size:        int64(fileMetadata.Size),
+		ContentType: mime.TypeByExtension(filepath.Ext(filePath)),
+		Checksum:    "dropbox:" + fileMetadata.ContentHash,
+	}, nil
+}
+
+func (port *storageDropbox) ReadFileHead(metaData domain.FileMetaData, length int) ([]byte, error) {
+
+	filePath := "/" + metaData.Path
+
+	filesClient, err := port.getFilesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	downloadArg := files.NewDownloadArg(filePath)
+	downloadArg.ExtraHeaders = map[string]string{"Range": fmt.Sprintf("bytes=0-%d", length-1)}
+
+	_, content, err := filesClient.Download(downloadArg)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	return io.ReadAll(io.LimitReader(content, int64(length)))
+}
+
+func (port *storageDropbox) ReadFile(metaData domain.FileMetaData) (io.ReadCloser, error) {
+
+	filePath := "/" + metaData.Path
+
+	filesClient, err := port.getFilesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	_, content, err := filesClient.Download(files.NewDownloadArg(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func (port *storageDropbox) PutFile(metaData domain.FileMetaData, content []byte, contentType string) error {
+
+	filePath := "/" + metaData.Path
+
+	port.log.Debug("PutFile inputs: ",
+		zap.String("filePath", filePath))
+
+	filesClient, err := port.getFilesClient()
+	if err != nil {
+		return err
+	}
+
+	uploadArg := files.NewUploadArg(filePath)
+	uploadArg.Mode = &files.WriteMode{Tagged: dropbox.Tagged{Tag: files.WriteModeOverwrite}}
+
+	_, err = filesClient.Upload(uploadArg, bytes.NewReader(content))
+	return err
+}
+
+func (port *storageDropbox) PutFileStream(metaData domain.FileMetaData, reader io.Reader, contentType string) error {
+
+	filePath := "/" + metaData.Path
+
+	port.log.Debug("PutFileStream inputs: ",
+		zap.String("filePath", filePath))
+
+	filesClient, err := port.getFilesClient()
+	if err != nil {
+		return err
+	}
+
+	uploadArg := files.NewUploadArg(filePath)
+	uploadArg.Mode = &files.WriteMode{Tagged: dropbox.Tagged{Tag: files.WriteModeOverwrite}}
+
+	_, err = filesClient.Upload(uploadArg, reader)
+	return err
+}
+
+func (port *storageDropbox) CopyFile(source domain.FileMetaData, target domain.FileMetaData) error {
+
+	port.log.Debug("CopyFile inputs: ",
+		zap.String("source", source.Path), zap.String("target", target.Path))
+
+	filesClient, err := port.getFilesClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = filesClient.CopyV2(files.NewRelocationArg("/"+source.Path, "/"+target.Path))
+	return err
+}
+
+// the dropbox upload sessions are not driven by urls, the large files use the single upload link
+
+func (port *storageDropbox) InitiateMultipartUpload(metaData domain.FileMetaData) (string, error) {
+	return "", repository.ErrMultipartUploadNotSupported
+}
+
+func (port *storageDropbox) GetMultipartUploadPartUrl(metaData domain.FileMetaData, uploadId string, partNumber int) (string, error) {
+	return "", repository.ErrMultipartUploadNotSupported
+}
+
+func (port *storageDropbox) CompleteMultipartUpload(metaData domain.FileMetaData, uploadId string, parts []domain.FileUploadPart) error {
+	return repository.ErrMultipartUploadNotSupported
+}
+
+func (port *storageDropbox) AbortMultipartUpload(metaData domain.FileMetaData, uploadId string) error {
+	return repository.ErrMultipartUploadNotSupported
+}
+
 // private
 
 func (port *storageDropbox) getFilesClient() (files.Client, error) {
@@ -0,0 +1,30 @@
+package dropboxcli_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/core/repository/repositorytest"
+	"github.com/erodriguezg/meet/pkg/infrastructure/dropboxcli"
+	"go.uber.org/zap"
+)
+
+// TestDropboxStorageContract runs against a real dropbox app, only when
+// DROPBOX_CONTRACT_TEST_APP_KEY is defined.
+func TestDropboxStorageContract(t *testing.T) {
+	appKey := os.Getenv("DROPBOX_CONTRACT_TEST_APP_KEY")
+	if appKey == "" {
+		t.Skip("DROPBOX_CONTRACT_TEST_APP_KEY not defined")
+	}
+
+	storage := dropboxcli.NewStorageDropbox(
+		appKey,
+		os.Getenv("DROPBOX_CONTRACT_TEST_APP_SECRET"),
+		os.Getenv("DROPBOX_CONTRACT_TEST_REFRESH_TOKEN"),
+		http.DefaultClient,
+		zap.NewNop(),
+	)
+
+	repositorytest.RunStorageRepositoryContract(t, storage, http.DefaultClient)
+}
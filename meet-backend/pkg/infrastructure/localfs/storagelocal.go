@@ -0,0 +1,401 @@
+package localfs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	uploadUrlExpiration        = 10 * time.Minute
+	multipartPartUrlExpiration = time.Hour
+
+	// folder of the parts of the multipart uploads in progress
+	multipartDir = ".multipart"
+
+	// the long-lived download urls (same as a public bucket url) are signed without expiration
+	noExpiration = "0"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpiredUrl       = errors.New("the signed url is expired")
+	ErrInvalidPath      = errors.New("invalid file path")
+)
+
+type LocalStorageConfig struct {
+	// directory where the files are stored
+	BaseDir string
+	// absolute url of the signed endpoints, ex: http://localhost:3000/api/v1/local-storage
+	PublicUrl  string
+	SigningKey []byte
+}
+
+// LocalStorage is a StorageRepository over the local filesystem, the uploads and downloads
+// are served by the backend through HMAC signed urls.
+type LocalStorage interface {
+	repository.StorageRepository
+
+	VerifySignedUrl(method string, path string, expires string, signature string) error
+
+	WriteFile(path string, reader io.Reader) error
+
+	OpenFile(path string) (io.ReadCloser, error)
+}
+
+type storageLocal struct {
+	config LocalStorageConfig
+	log    *zap.Logger
+}
+
+func NewStorageLocal(config LocalStorageConfig, log *zap.Logger) LocalStorage {
+	if len(config.SigningKey) == 0 {
+		panic("the local storage signing key is required")
+	}
+	err := os.MkdirAll(config.BaseDir, 0o755)
+	if err != nil {
+		panic(err)
+	}
+	config.PublicUrl = strings.TrimSuffix(config.PublicUrl, "/")
+	return &storageLocal{config, log}
+}
+
+func (port *storageLocal) GetStorageType() string {
+	return "LOCAL"
+}
+
+func (port *storageLocal) GetFileUploadUrl(metaData domain.FileMetaData) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(uploadUrlExpiration).Unix(), 10)
+	return port.signedUrl(http.MethodPut, metaData.Path, expires)
+}
+
+func (port *storageLocal) GetFileDownloadUrl(metaData domain.FileMetaData) (string, error) {
+	return port.signedUrl(http.MethodGet, metaData.Path, noExpiration)
+}
+
+func (port *storageLocal) GetFileTemporaryDownloadUrl(metaData domain.FileMetaData, expiration time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(expiration).Unix(), 10)
+	return port.signedUrl(http.MethodGet, metaData.Path, expires)
+}
+
+func (port *storageLocal) DeleteFile(metaData domain.FileMetaData) error {
+	fullPath, err := port.fullPath(metaData.Path)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(fullPath)
+	if err != nil {
+		return fmt.Errorf("can't delete file: %w", err)
+	}
+	return nil
+}
+
+func (port *storageLocal) StatFile(metaData domain.FileMetaData) (*domain.FileStat, error) {
+	fullPath, err := port.fullPath(metaData.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't stat file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("can't stat file: %w", err)
+	}
+	checksum, err := sha256Hex(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't stat file: %w", err)
+	}
+
+	return &domain.FileStat{
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(fullPath)),
+		Checksum:    "sha256:" + checksum,
+	}, nil
+}
+
+func (port *storageLocal) ReadFileHead(metaData domain.FileMetaData, length int) ([]byte, error) {
+	file, err := port.OpenFile(metaData.Path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read file head: %w", err)
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, int64(length)))
+}
+
+func (port *storageLocal) ReadFile(metaData domain.FileMetaData) (io.ReadCloser, error) {
+	return port.OpenFile(metaData.Path)
+}
+
+func (port *storageLocal) PutFile(metaData domain.FileMetaData, content []byte, contentType string) error {
+	return port.WriteFile(metaData.Path, bytes.NewReader(content))
+}
+
+func (port *storageLocal) PutFileStream(metaData domain.FileMetaData, reader io.Reader, contentType string) error {
+	return port.WriteFile(metaData.Path, reader)
+}
+
+func (port *storageLocal) CopyFile(source domain.FileMetaData, target domain.FileMetaData) error {
+	file, err := port.OpenFile(source.Path)
+	if err != nil {
+		return fmt.Errorf("can't copy file: %w", err)
+	}
+	defer file.Close()
+	return port.WriteFile(target.Path, file)
+}
+
+func (port *storageLocal) InitiateMultipartUpload(metaData domain.FileMetaData) (string, error) {
+	if _, err := port.fullPath(metaData.Path); err != nil {
+		return "", err
+	}
+	randomBytes := make([]byte, 16)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", fmt.Errorf("can't generate upload id: %w", err)
+	}
+	uploadId := hex.EncodeToString(randomBytes)
+
+	partsDir, err := port.multipartPartsDir(uploadId)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(partsDir, 0o755)
+	if err != nil {
+		return "", fmt.Errorf("can't create the folder of the parts: %w", err)
+	}
+	return uploadId, nil
+}
+
+func (port *storageLocal) GetMultipartUploadPartUrl(metaData domain.FileMetaData, uploadId string, partNumber int) (string, error) {
+	if _, err := port.multipartPartsDir(uploadId); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(multipartPartUrlExpiration).Unix(), 10)
+	return port.signedUrl(http.MethodPut, multipartPartPath(uploadId, partNumber), expires)
+}
+
+// CompleteMultipartUpload joins the parts in the file, the etag of every part is the
+// sha256 of its content (the same sent by the upload endpoint).
+func (port *storageLocal) CompleteMultipartUpload(metaData domain.FileMetaData, uploadId string, parts []domain.FileUploadPart) error {
+	partsDir, err := port.multipartPartsDir(uploadId)
+	if err != nil {
+		return err
+	}
+
+	sortedParts := append([]domain.FileUploadPart{}, parts...)
+	sort.Slice(sortedParts, func(i, j int) bool {
+		return sortedParts[i].PartNumber < sortedParts[j].PartNumber
+	})
+
+	// one part open at a time, an upload can have up to 10000 parts
+	for _, part := range sortedParts {
+		err = port.verifyPartETag(uploadId, part)
+		if err != nil {
+			return err
+		}
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		var err error
+		for _, part := range sortedParts {
+			err = port.copyPart(writer, uploadId, part.PartNumber)
+			if err != nil {
+				break
+			}
+		}
+		writer.CloseWithError(err)
+	}()
+
+	err = port.WriteFile(metaData.Path, reader)
+	// unblocks the copy of the parts when the write fails
+	reader.CloseWithError(err)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(partsDir)
+}
+
+func (port *storageLocal) AbortMultipartUpload(metaData domain.FileMetaData, uploadId string) error {
+	partsDir, err := port.multipartPartsDir(uploadId)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(partsDir)
+}
+
+func (port *storageLocal) VerifySignedUrl(method string, path string, expires string, signature string) error {
+	if expires == noExpiration && method != http.MethodGet {
+		return ErrInvalidSignature
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, port.sign(method, path, expires)) {
+		return ErrInvalidSignature
+	}
+
+	if expires == noExpiration {
+		return nil
+	}
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if time.Now().Unix() > expiresUnix {
+		return ErrExpiredUrl
+	}
+	return nil
+}
+
+func (port *storageLocal) WriteFile(path string, reader io.Reader) error {
+	fullPath, err := port.fullPath(path)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(fullPath), 0o755)
+	if err != nil {
+		return fmt.Errorf("can't create the folders for %s: %w", path, err)
+	}
+
+	// write in a temporary file and rename, so a failed upload never leaves a partial file
+	tmpFile, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("can't create temporary file for %s: %w", path, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = io.Copy(tmpFile, reader)
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("can't write file %s: %w", path, err)
+	}
+	err = tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("can't write file %s: %w", path, err)
+	}
+
+	port.log.Debug("local storage file written", zap.String("path", path))
+	return os.Rename(tmpFile.Name(), fullPath)
+}
+
+func (port *storageLocal) OpenFile(path string) (io.ReadCloser, error) {
+	fullPath, err := port.fullPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(fullPath)
+}
+
+// private
+
+func (port *storageLocal) verifyPartETag(uploadId string, part domain.FileUploadPart) error {
+	partFile, err := port.OpenFile(multipartPartPath(uploadId, part.PartNumber))
+	if err != nil {
+		return fmt.Errorf("can't open the part %d: %w", part.PartNumber, err)
+	}
+	defer partFile.Close()
+
+	etag, err := sha256Hex(partFile)
+	if err != nil {
+		return fmt.Errorf("can't read the part %d: %w", part.PartNumber, err)
+	}
+	if strings.Trim(part.ETag, `"`) != etag {
+		return fmt.Errorf("the etag of the part %d does not match", part.PartNumber)
+	}
+	return nil
+}
+
+func (port *storageLocal) copyPart(writer io.Writer, uploadId string, partNumber int) error {
+	partFile, err := port.OpenFile(multipartPartPath(uploadId, partNumber))
+	if err != nil {
+		return fmt.Errorf("can't open the part %d: %w", partNumber, err)
+	}
+	defer partFile.Close()
+
+	_, err = io.Copy(writer, partFile)
+	if err != nil {
+		return fmt.Errorf("can't read the part %d: %w", partNumber, err)
+	}
+	return nil
+}
+
+func sha256Hex(reader io.Reader) (string, error) {
+	hash := sha256.New()
+	_, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func multipartPartPath(uploadId string, partNumber int) string {
+	return fmt.Sprintf("%s/%s/%05d", multipartDir, uploadId, partNumber)
+}
+
+func (port *storageLocal) multipartPartsDir(uploadId string) (string, error) {
+	if _, err := hex.DecodeString(uploadId); err != nil || uploadId == "" {
+		return "", ErrInvalidPath
+	}
+	return port.fullPath(multipartDir + "/" + uploadId)
+}
+
+func (port *storageLocal) signedUrl(method string, path string, expires string) (string, error) {
+	if _, err := port.fullPath(path); err != nil {
+		return "", err
+	}
+
+	escapedSegments := strings.Split(path, "/")
+	for i, segment := range escapedSegments {
+		escapedSegments[i] = url.PathEscape(segment)
+	}
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", hex.EncodeToString(port.sign(method, path, expires)))
+
+	return port.config.PublicUrl + "/" + strings.Join(escapedSegments, "/") + "?" + query.Encode(), nil
+}
+
+func (port *storageLocal) sign(method string, path string, expires string) []byte {
+	mac := hmac.New(sha256.New, port.config.SigningKey)
+	mac.Write([]byte(method + "\n" + path + "\n" + expires))
+	return mac.Sum(nil)
+}
+
+func (port *storageLocal) fullPath(path string) (string, error) {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return "", ErrInvalidPath
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", ErrInvalidPath
+		}
+	}
+	return filepath.Join(port.config.BaseDir, filepath.FromSlash(path)), nil
+}
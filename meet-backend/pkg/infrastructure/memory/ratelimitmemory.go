@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+)
+
+// the full buckets are removed periodically, they are the same as a missing bucket
+const rateLimitPruneInterval = time.Minute
+
+type rateLimitBucket struct {
+	tokens      float64
+	updatedTime time.Time
+	limit       domain.RateLimit
+}
+
+type rateLimitMemory struct {
+	mutex         sync.Mutex
+	buckets       map[string]*rateLimitBucket
+	lastPruneTime time.Time
+	now           func() time.Time
+}
+
+// NewRateLimitMemory the buckets of this instance, for deployments of a single replica.
+func NewRateLimitMemory() repository.RateLimitRepository {
+	return &rateLimitMemory{
+		buckets: make(map[string]*rateLimitBucket),
+		now:     time.Now,
+	}
+}
+
+// Take implements repository.RateLimitRepository.
+func (port *rateLimitMemory) Take(key string, limit domain.RateLimit) (bool, time.Duration, error) {
+	port.mutex.Lock()
+	defer port.mutex.Unlock()
+
+	now := port.now()
+	port.pruneIfNeeded(now)
+
+	bucket, found := port.buckets[key]
+	if !found {
+		bucket = &rateLimitBucket{tokens: float64(limit.Capacity), updatedTime: now}
+		port.buckets[key] = bucket
+	}
+	bucket.limit = limit
+	bucket.refill(now)
+
+	if bucket.tokens < 1 {
+		return false, limit.RetryAfter(bucket.tokens), nil
+	}
+	bucket.tokens--
+	return true, 0, nil
+}
+
+// private
+
+func (port *rateLimitMemory) pruneIfNeeded(now time.Time) {
+	if now.Sub(port.lastPruneTime) < rateLimitPruneInterval {
+		return
+	}
+	port.lastPruneTime = now
+	for key, bucket := range port.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= float64(bucket.limit.Capacity) {
+			delete(port.buckets, key)
+		}
+	}
+}
+
+func (bucket *rateLimitBucket) refill(now time.Time) {
+	elapsed := now.Sub(bucket.updatedTime).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(float64(bucket.limit.Capacity), bucket.tokens+elapsed*bucket.limit.TokensPerSecond())
+	}
+	bucket.updatedTime = now
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeRefillsTheBucket(t *testing.T) {
+	now := time.Now()
+	repository := NewRateLimitMemory().(*rateLimitMemory)
+	repository.now = func() time.Time { return now }
+	limit := domain.RateLimit{Capacity: 2, Period: 10 * time.Second}
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := repository.Take("ip:1", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter, err := repository.Take("ip:1", limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 5*time.Second, retryAfter)
+
+	// other keys have their own bucket
+	allowed, _, _ = repository.Take("ip:2", limit)
+	assert.True(t, allowed)
+
+	now = now.Add(5 * time.Second)
+	allowed, _, _ = repository.Take("ip:1", limit)
+	assert.True(t, allowed)
+	allowed, _, _ = repository.Take("ip:1", limit)
+	assert.False(t, allowed)
+}
+
+func TestTakePrunesTheFullBuckets(t *testing.T) {
+	now := time.Now()
+	repository := NewRateLimitMemory().(*rateLimitMemory)
+	repository.now = func() time.Time { return now }
+	limit := domain.RateLimit{Capacity: 5, Period: time.Second}
+
+	repository.Take("ip:1", limit)
+	now = now.Add(rateLimitPruneInterval)
+	repository.Take("ip:2", limit)
+
+	assert.Len(t, repository.buckets, 1)
+	assert.Contains(t, repository.buckets, "ip:2")
+}
@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	auditEntryCollection = "auditEntries"
+)
+
+type auditEntryMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewAuditEntryMongoDB(mongoDB *mongo.Database) repository.AuditEntryRepository {
+	return &auditEntryMongoDB{mongoDB}
+}
+
+// Save implements repository.AuditEntryRepository.
+func (port *auditEntryMongoDB) Save(entry domain.AuditEntry) error {
+	_, err := port.getCollection().InsertOne(context.Background(), entry)
+	return err
+}
+
+// SearchCount implements repository.AuditEntryRepository.
+func (port *auditEntryMongoDB) SearchCount(filters domain.AuditFilter) (int, error) {
+	count, err := port.getCollection().CountDocuments(context.Background(), port.searchFilterBson(filters))
+	if err != nil {
+		return -1, err
+	}
+	return int(count), nil
+}
+
+// Search implements repository.AuditEntryRepository.
+func (port *auditEntryMongoDB) Search(filters domain.AuditFilter, first int, last int) ([]domain.AuditEntry, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(first)).
+		SetLimit(int64(last - first))
+
+	cursor, err := port.getCollection().Find(context.Background(), port.searchFilterBson(filters), findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.AuditEntry{}
+	err = cursor.All(context.Background(), &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// private
+
+func (port *auditEntryMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(auditEntryCollection)
+}
+
+func (port *auditEntryMongoDB) searchFilterBson(filters domain.AuditFilter) bson.M {
+	filterBson := bson.M{}
+	if filters.ActorPersonId != nil {
+		filterBson["actor.personId"] = *filters.ActorPersonId
+	}
+	if filters.Action != nil {
+		filterBson["action"] = *filters.Action
+	}
+	if filters.TargetType != nil {
+		filterBson["targetType"] = *filters.TargetType
+	}
+	if filters.TargetId != nil {
+		filterBson["targetId"] = *filters.TargetId
+	}
+	dateFilter := bson.M{}
+	if filters.DateLower != nil {
+		dateFilter["$gte"] = *filters.DateLower
+	}
+	if filters.DateUpper != nil {
+		dateFilter["$lt"] = *filters.DateUpper
+	}
+	if len(dateFilter) > 0 {
+		filterBson["date"] = dateFilter
+	}
+	return filterBson
+}
@@ -0,0 +1,101 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	refreshTokenCollection       = "refreshTokens"
+	revokedAccessTokenCollection = "revokedAccessTokens"
+)
+
+type refreshTokenMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewRefreshTokenMongoDB(mongoDB *mongo.Database) repository.RefreshTokenRepository {
+	return &refreshTokenMongoDB{mongoDB}
+}
+
+// Save implements repository.RefreshTokenRepository.
+func (port *refreshTokenMongoDB) Save(refreshToken domain.RefreshToken) error {
+	_, err := port.getCollection().InsertOne(context.Background(), refreshToken)
+	return err
+}
+
+// FindByTokenHash implements repository.RefreshTokenRepository.
+func (port *refreshTokenMongoDB) FindByTokenHash(tokenHash string) (*domain.RefreshToken, error) {
+	return findOne[domain.RefreshToken](context.Background(), port.getCollection(), bson.M{"tokenHash": tokenHash})
+}
+
+// MarkUsed implements repository.RefreshTokenRepository.
+func (port *refreshTokenMongoDB) MarkUsed(id primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id":         id,
+		"usedDate":    bson.M{"$exists": false},
+		"revokedDate": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"usedDate": time.Now()}}
+	result, err := port.getCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RevokeFamily implements repository.RefreshTokenRepository.
+func (port *refreshTokenMongoDB) RevokeFamily(familyId string) error {
+	filter := bson.M{
+		"familyId":    familyId,
+		"revokedDate": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revokedDate": time.Now()}}
+	_, err := port.getCollection().UpdateMany(context.Background(), filter, update)
+	return err
+}
+
+// private
+
+func (port *refreshTokenMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(refreshTokenCollection)
+}
+
+type revokedAccessTokenMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewRevokedAccessTokenMongoDB(mongoDB *mongo.Database) repository.RevokedAccessTokenRepository {
+	return &revokedAccessTokenMongoDB{mongoDB}
+}
+
+// Save implements repository.RevokedAccessTokenRepository.
+func (port *revokedAccessTokenMongoDB) Save(revokedAccessToken domain.RevokedAccessToken) error {
+	_, err := port.getCollection().InsertOne(context.Background(), revokedAccessToken)
+	if mongo.IsDuplicateKeyError(err) {
+		// already revoked
+		return nil
+	}
+	return err
+}
+
+// ExistsByJti implements repository.RevokedAccessTokenRepository.
+func (port *revokedAccessTokenMongoDB) ExistsByJti(jti string) (bool, error) {
+	count, err := port.getCollection().CountDocuments(context.Background(), bson.M{"jti": jti})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// private
+
+func (port *revokedAccessTokenMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(revokedAccessTokenCollection)
+}
@@ -0,0 +1,65 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	exchangeRateCollection = "exchangeRates"
+)
+
+type exchangeRateMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewExchangeRateMongoDB(mongoDB *mongo.Database) repository.ExchangeRateRepository {
+	return &exchangeRateMongoDB{mongoDB}
+}
+
+// FindAll implements repository.ExchangeRateRepository.
+func (port *exchangeRateMongoDB) FindAll() ([]domain.ExchangeRate, error) {
+	filter := bson.M{}
+	return findMany[domain.ExchangeRate](context.Background(), port.getCollection(), filter)
+}
+
+// FindByCurrencyCode implements repository.ExchangeRateRepository.
+func (port *exchangeRateMongoDB) FindByCurrencyCode(currencyCode string) (*domain.ExchangeRate, error) {
+	filter := bson.M{"currencyCode": currencyCode}
+	return findOne[domain.ExchangeRate](context.Background(), port.getCollection(), filter)
+}
+
+// Save implements repository.ExchangeRateRepository.
+func (port *exchangeRateMongoDB) Save(rate domain.ExchangeRate) (*domain.ExchangeRate, error) {
+	filter := bson.M{"currencyCode": rate.CurrencyCode}
+	update := bson.M{"$set": rate}
+	opts := options.Update().SetUpsert(true)
+	result, err := port.getCollection().UpdateOne(context.Background(), filter, update, opts)
+	if err != nil {
+		return nil, err
+	}
+	if rate.Id == nil && result.UpsertedID != nil {
+		auxObjectId := result.UpsertedID.(primitive.ObjectID)
+		rate.Id = &auxObjectId
+	}
+	return &rate, nil
+}
+
+// DeleteByCurrencyCode implements repository.ExchangeRateRepository.
+func (port *exchangeRateMongoDB) DeleteByCurrencyCode(currencyCode string) error {
+	filter := bson.M{"currencyCode": currencyCode}
+	_, err := port.getCollection().DeleteOne(context.Background(), filter)
+	return err
+}
+
+// private
+
+func (port *exchangeRateMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(exchangeRateCollection)
+}
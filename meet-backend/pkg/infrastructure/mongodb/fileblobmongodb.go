@@ -0,0 +1,106 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	fileBlobCollection = "fileBlobs"
+)
+
+type fileBlobMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewFileBlobMongoDB(mongoDB *mongo.Database) repository.FileBlobRepository {
+	return &fileBlobMongoDB{mongoDB}
+}
+
+// AcquireBlob implements repository.FileBlobRepository.
+func (port *fileBlobMongoDB) AcquireBlob(blob domain.FileBlob) (*domain.FileBlob, error) {
+	filter := bson.M{
+		"digest":      blob.Digest,
+		"storageType": blob.StorageType,
+	}
+	update := bson.M{
+		"$inc": bson.M{"refCount": 1},
+		"$setOnInsert": bson.M{
+			"path":         blob.Path,
+			"size":         blob.Size,
+			"creationDate": time.Now(),
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var acquired domain.FileBlob
+	err := port.getCollection().FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&acquired)
+	if err != nil {
+		return nil, fmt.Errorf("error at findOneAndUpdate mongodb. error: %w", err)
+	}
+	return &acquired, nil
+}
+
+// ReleaseBlob implements repository.FileBlobRepository.
+func (port *fileBlobMongoDB) ReleaseBlob(digest string, storageType string) (*domain.FileBlob, error) {
+	filter := bson.M{
+		"digest":      digest,
+		"storageType": storageType,
+	}
+	update := bson.M{
+		"$inc": bson.M{"refCount": -1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var released domain.FileBlob
+	err := port.getCollection().FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&released)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error at findOneAndUpdate mongodb. error: %w", err)
+	}
+	return &released, nil
+}
+
+// MarkDeleting implements repository.FileBlobRepository.
+func (port *fileBlobMongoDB) MarkDeleting(id primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id":          id,
+		"refCount":     bson.M{"$lte": 0},
+		"deletingDate": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"deletingDate": time.Now()},
+	}
+	result, err := port.getCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, fmt.Errorf("error at updateOne mongodb. error: %w", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// Delete implements repository.FileBlobRepository.
+func (port *fileBlobMongoDB) Delete(id primitive.ObjectID) error {
+	_, err := port.getCollection().DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("error at deleteOne mongodb. error: %w", err)
+	}
+	return nil
+}
+
+// private
+
+func (port *fileBlobMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(fileBlobCollection)
+}
@@ -46,6 +46,61 @@ func (port *fileMetaDataMongoDB) FindByHash(hash string) (*domain.FileMetaData,
 	return &fileMetaData, nil
 }
 
+func (port *fileMetaDataMongoDB) FindAll() ([]domain.FileMetaData, error) {
+	files, err := findMany[domain.FileMetaData](context.Background(), port.getCollection(), bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error at find mongodb. error: %w", err)
+	}
+	return files, nil
+}
+
+func (port *fileMetaDataMongoDB) FindUploadedNotInStorage(storageType string, includeLegacy bool, afterId *primitive.ObjectID, limit int) ([]domain.FileMetaData, error) {
+	storageFilter := bson.M{"$ne": storageType}
+	if !includeLegacy {
+		storageFilter["$exists"] = true
+	}
+	filter := bson.M{
+		"uploaded":        true,
+		"multipartUpload": nil,
+		"storageType":     storageFilter,
+	}
+	if afterId != nil {
+		filter["_id"] = bson.M{"$gt": *afterId}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := port.getCollection().Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error at find mongodb. error: %w", err)
+	}
+	var files []domain.FileMetaData
+	err = cursor.All(context.Background(), &files)
+	if err != nil {
+		return nil, fmt.Errorf("error at decode cursor all. error: %w", err)
+	}
+	return files, nil
+}
+
+func (port *fileMetaDataMongoDB) UpdateStorage(files []domain.FileMetaData) error {
+	models := make([]mongo.WriteModel, 0, len(files))
+	for _, file := range files {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"hash": file.Hash}).
+			SetUpdate(bson.M{
+				"$set":   bson.M{"storageType": file.StorageType, "path": file.Path},
+				"$unset": bson.M{"downloadUrl": ""},
+			}))
+	}
+	_, err := port.getCollection().BulkWrite(context.Background(), models)
+	if err != nil {
+		return fmt.Errorf("error at bulkWrite mongodb. error: %w", err)
+	}
+	return nil
+}
+
 func (port *fileMetaDataMongoDB) Save(fileMetaData domain.FileMetaData) (*domain.FileMetaData, error) {
 	filter := bson.M{
 		"hash": fileMetaData.Hash,
@@ -71,6 +126,32 @@ func (port *fileMetaDataMongoDB) Save(fileMetaData domain.FileMetaData) (*domain
 	return &fileMetaData, nil
 }
 
+func (port *fileMetaDataMongoDB) SaveMultipartPart(hash string, uploadId string, part domain.FileUploadPart) (bool, error) {
+	filter := bson.M{
+		"hash":                     hash,
+		"multipartUpload.uploadId": uploadId,
+	}
+
+	// a pipeline update, the filter and the append are done over the same document version
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"multipartUpload.completedParts": bson.M{"$concatArrays": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$multipartUpload.completedParts", bson.A{}}},
+					"cond":  bson.M{"$ne": bson.A{"$$this.partNumber", part.PartNumber}},
+				}},
+				bson.A{bson.M{"partNumber": part.PartNumber, "etag": part.ETag}},
+			}},
+		}},
+	}
+
+	result, err := port.getCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, fmt.Errorf("error SaveMultipartPart. hash: %s, partNumber: %d, error: %w", hash, part.PartNumber, err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
 // private
 
 func (port *fileMetaDataMongoDB) getCollection() *mongo.Collection {
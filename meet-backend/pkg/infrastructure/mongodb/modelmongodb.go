@@ -83,6 +83,52 @@ func (port *modelMongoDB) FindModelByNickName(nickName string) (*domain.Model, e
 	return &model, nil
 }
 
+func (port *modelMongoDB) FindModelsByPreviousNickName(nickName string) ([]domain.Model, error) {
+	models, err := findMany[domain.Model](context.Background(), port.getCollection(), bson.M{"previousNickNames.nickName": nickName})
+	if err != nil {
+		return nil, fmt.Errorf("error FindModelsByPreviousNickName. nickName: %s. error: %w", nickName, err)
+	}
+	return models, nil
+}
+
+func (port *modelMongoDB) FindModelByProfileImageFileHash(fileHash string) (*domain.Model, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"profileImageFileHash": fileHash},
+		bson.M{"profileImageThumbnailFileHash": fileHash},
+	}}
+	model, err := findOne[domain.Model](context.Background(), port.getCollection(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("error FindModelByProfileImageFileHash. fileHash: %s. error: %w", fileHash, err)
+	}
+	return model, nil
+}
+
+func (port *modelMongoDB) FindModelById(modelId string) (*domain.Model, error) {
+	modelObjectId, err := primitive.ObjectIDFromHex(modelId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindModelById getting objectIdFromHex from modelId: %s. error: %w", modelId, err)
+	}
+	var model domain.Model
+	err = port.getCollection().
+		FindOne(context.Background(), bson.M{"_id": modelObjectId}).
+		Decode(&model)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error FindModelById. modelId: %s. error: %w", modelId, err)
+	}
+	return &model, nil
+}
+
+func (port *modelMongoDB) FindAllModels() ([]domain.Model, error) {
+	models, err := findMany[domain.Model](context.Background(), port.getCollection(), bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error Model FindAllModels. error: %w", err)
+	}
+	return models, nil
+}
+
 func (port *modelMongoDB) SaveModel(model domain.Model) (*domain.Model, error) {
 
 	filter := bson.M{
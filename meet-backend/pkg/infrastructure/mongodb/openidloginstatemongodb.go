@@ -0,0 +1,53 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	openIdLoginStateCollection = "openIdLoginStates"
+)
+
+type openIdLoginStateMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewOpenIdLoginStateMongoDB(mongoDB *mongo.Database) repository.OpenIdLoginStateRepository {
+	return &openIdLoginStateMongoDB{mongoDB}
+}
+
+// Save implements repository.OpenIdLoginStateRepository.
+func (port *openIdLoginStateMongoDB) Save(loginState domain.OpenIdLoginState) error {
+	_, err := port.getCollection().InsertOne(context.Background(), loginState)
+	return err
+}
+
+// Consume implements repository.OpenIdLoginStateRepository.
+func (port *openIdLoginStateMongoDB) Consume(stateId string) (*domain.OpenIdLoginState, error) {
+	// the ttl index removes the expired states only every minute, so the expiration is filtered too
+	filter := bson.M{
+		"stateId":        stateId,
+		"expirationDate": bson.M{"$gt": time.Now()},
+	}
+	var loginState domain.OpenIdLoginState
+	err := port.getCollection().FindOneAndDelete(context.Background(), filter).Decode(&loginState)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &loginState, nil
+}
+
+// private
+
+func (port *openIdLoginStateMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(openIdLoginStateCollection)
+}
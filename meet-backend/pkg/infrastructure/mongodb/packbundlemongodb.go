@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	packBundleCollection = "packBundles"
+)
+
+type packBundleMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewPackBundleMongoDB(mongoDB *mongo.Database) repository.PackBundleRepository {
+	return &packBundleMongoDB{mongoDB}
+}
+
+// FindBundleById implements repository.PackBundleRepository.
+func (port *packBundleMongoDB) FindBundleById(bundleId string) (*domain.PackBundle, error) {
+	bundleObjectId, err := primitive.ObjectIDFromHex(bundleId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindBundleById getting objectIdFromHex from bundleId: %s. error: %w", bundleId, err)
+	}
+	filter := bson.M{"_id": bundleObjectId}
+	return findOne[domain.PackBundle](context.Background(), port.getCollection(), filter)
+}
+
+// FindBundleActiveByModelIdAndBundleNumber implements repository.PackBundleRepository.
+func (port *packBundleMongoDB) FindBundleActiveByModelIdAndBundleNumber(modelId string, bundleNumber int) (*domain.PackBundle, error) {
+	modelObjectId, err := primitive.ObjectIDFromHex(modelId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindBundleActiveByModelIdAndBundleNumber getting objectIdFromHex from modelId: %s. error: %w", modelId, err)
+	}
+	filter := bson.M{
+		"modelId":      modelObjectId,
+		"bundleNumber": bundleNumber,
+		"active":       true,
+	}
+	return findOne[domain.PackBundle](context.Background(), port.getCollection(), filter)
+}
+
+// FindBundlesActiveByModelId implements repository.PackBundleRepository.
+func (port *packBundleMongoDB) FindBundlesActiveByModelId(modelId string) ([]domain.PackBundle, error) {
+	modelObjectId, err := primitive.ObjectIDFromHex(modelId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindBundlesActiveByModelId getting objectIdFromHex from modelId: %s. error: %w", modelId, err)
+	}
+	filter := bson.M{
+		"modelId": modelObjectId,
+		"active":  true,
+	}
+	return findMany[domain.PackBundle](context.Background(), port.getCollection(), filter)
+}
+
+// SaveBundle implements repository.PackBundleRepository.
+func (port *packBundleMongoDB) SaveBundle(bundle domain.PackBundle) (*domain.PackBundle, error) {
+	if bundle.Id == nil {
+		result, err := port.getCollection().InsertOne(context.Background(), bundle)
+		if err != nil {
+			return nil, err
+		}
+		auxId, ok := result.InsertedID.(primitive.ObjectID)
+		if !ok {
+			return nil, fmt.Errorf("failed to convert InsertedID to ObjectID")
+		}
+		bundle.Id = &auxId
+		return &bundle, nil
+	}
+
+	filter := bson.M{"_id": bundle.Id}
+	update := bson.M{"$set": bundle}
+	_, err := port.getCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// private
+
+func (port *packBundleMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(packBundleCollection)
+}
@@ -111,6 +111,29 @@ func (port *packMongoDB) FindPacksActiveByModelId(modelId string) ([]domain.Pack
 	return packs, nil
 }
 
+func (port *packMongoDB) FindPackByItemFileHash(fileHash string) (*domain.Pack, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"packItems.resourceFile": fileHash},
+			bson.M{"packItems.thumbnailFile": fileHash},
+			bson.M{"packItems.thumbnailLockedFile": fileHash},
+		},
+	}
+	pack, err := findOne[domain.Pack](context.Background(), port.getCollection(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("error Pack FindPackByItemFileHash. fileHash: %s. error: %w", fileHash, err)
+	}
+	return pack, nil
+}
+
+func (port *packMongoDB) FindAllPacks() ([]domain.Pack, error) {
+	packs, err := findMany[domain.Pack](context.Background(), port.getCollection(), bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error Pack FindAllPacks. error: %w", err)
+	}
+	return packs, nil
+}
+
 func (port *packMongoDB) SavePack(pack domain.Pack) (*domain.Pack, error) {
 	filter := bson.M{
 		"modelId":    pack.ModelId,
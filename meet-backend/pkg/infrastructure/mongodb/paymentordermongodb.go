@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/repository"
@@ -57,6 +58,39 @@ func (port *paymentOrderMongoDB) FindByOrderId(orderId string) (*domain.PaymentO
 	return &paymentOrder, nil
 }
 
+func (port *paymentOrderMongoDB) FindById(paymentOrderId string) (*domain.PaymentOrder, error) {
+	paymentOrderObjectId, err := primitive.ObjectIDFromHex(paymentOrderId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindById getting objectIdFromHex from paymentOrderId: %s. error: %w", paymentOrderId, err)
+	}
+	return findOne[domain.PaymentOrder](context.Background(), port.getCollection(), bson.M{"_id": paymentOrderObjectId})
+}
+
+func (port *paymentOrderMongoDB) FindCapturedByOwnerAndPackId(personId string, packId string) (*domain.PaymentOrder, error) {
+	personObjectId, err := primitive.ObjectIDFromHex(personId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindCapturedByOwnerAndPackId getting objectIdFromHex from personId: %s. error: %w", personId, err)
+	}
+	packObjectId, err := primitive.ObjectIDFromHex(packId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindCapturedByOwnerAndPackId getting objectIdFromHex from packId: %s. error: %w", packId, err)
+	}
+	filter := bson.M{
+		"capturedAt": bson.M{"$exists": true},
+		"$and": bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{"recipientPersonId": personObjectId},
+				bson.M{"personId": personObjectId, "recipientPersonId": bson.M{"$exists": false}},
+			}},
+			bson.M{"$or": bson.A{
+				bson.M{"packId": packObjectId},
+				bson.M{"bundlePacksId": packObjectId},
+			}},
+		},
+	}
+	return findOne[domain.PaymentOrder](context.Background(), port.getCollection(), filter)
+}
+
 // private
 
 func (port *paymentOrderMongoDB) getCollection() *mongo.Collection {
@@ -0,0 +1,61 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	permissionCollection = "permissions"
+)
+
+type permissionMongoDb struct {
+	mongoDB *mongo.Database
+}
+
+func NewPermissionMongoDB(mongoDB *mongo.Database) repository.PermissionRepository {
+	return &permissionMongoDb{mongoDB}
+}
+
+func (port *permissionMongoDb) FindByCode(code int) (*domain.Permission, error) {
+	permission, err := findOne[domain.Permission](context.Background(), port.getCollection(), bson.M{"code": code})
+	if err != nil {
+		return nil, fmt.Errorf("error decoding permission on FindByCode: %w", err)
+	}
+	return permission, nil
+}
+
+func (port *permissionMongoDb) FindAll() ([]domain.Permission, error) {
+	permissions, err := findMany[domain.Permission](context.Background(), port.getCollection(), bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error on FindAll: %w", err)
+	}
+	return permissions, nil
+}
+
+func (port *permissionMongoDb) Save(permission domain.Permission) (*domain.Permission, error) {
+	filter := bson.M{"code": permission.Code}
+	update := bson.M{"$set": permission}
+	_, err := port.getCollection().UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, fmt.Errorf("error on Save permission: %w", err)
+	}
+	return &permission, nil
+}
+
+func (port *permissionMongoDb) Delete(code int) error {
+	_, err := port.getCollection().DeleteOne(context.Background(), bson.M{"code": code})
+	return err
+}
+
+// private
+
+func (port *permissionMongoDb) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(permissionCollection)
+}
@@ -36,10 +36,24 @@ func (*personMongoDB) FindAll() ([]domain.Person, error) {
 	panic("unimplemented")
 }
 
+func (port *personMongoDB) CountByProfileCodes(profileCodes []int, onlyActive bool) (int64, error) {
+	filter := bson.M{"profileCode": bson.M{"$in": profileCodes}}
+	if onlyActive {
+		filter["active"] = true
+	}
+	count, err := port.getCollection().CountDocuments(context.Background(), filter)
+	if err != nil {
+		return 0, fmt.Errorf("error CountByProfileCodes. profileCodes: %v, error: %w", profileCodes, err)
+	}
+	return count, nil
+}
+
+// FindByEmail the emails are compared without case
 func (port *personMongoDB) FindByEmail(email string) (*domain.Person, error) {
 	var person domain.Person
+	opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
 	err := port.getCollection().
-		FindOne(context.Background(), bson.M{"email": email}).
+		FindOne(context.Background(), bson.M{"email": email}, opts).
 		Decode(&person)
 
 	if err != nil {
@@ -52,6 +66,15 @@ func (port *personMongoDB) FindByEmail(email string) (*domain.Person, error) {
 	return &person, err
 }
 
+func (port *personMongoDB) FindByExternalIdentity(provider string, subject string) (*domain.Person, error) {
+	filter := bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}}
+	person, err := findOne[domain.Person](context.Background(), port.getCollection(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("error FindByExternalIdentity. provider: %s, subject: %s, error: %w", provider, subject, err)
+	}
+	return person, nil
+}
+
 func (port *personMongoDB) FindById(uuid string) (*domain.Person, error) {
 	personObjectId, _ := primitive.ObjectIDFromHex(uuid)
 	var person domain.Person
@@ -84,6 +107,10 @@ func (port *personMongoDB) getCollection() *mongo.Collection {
 func (port *personMongoDB) upsert(person domain.Person) (*domain.Person, error) {
 	filter := bson.M{"email": person.Email}
 	update := bson.M{"$set": person}
+	// the omitempty fields are not in the $set, a cleared birthday must be removed
+	if person.BirthDay == nil {
+		update["$unset"] = bson.M{"birthday": ""}
+	}
 	opts := options.Update().SetUpsert(true)
 
 	result, err := port.getCollection().UpdateOne(context.Background(), filter, update, opts)
@@ -8,6 +8,7 @@ import (
 	"github.com/erodriguezg/meet/pkg/core/repository"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -52,6 +53,29 @@ func (port *profileMongoDb) FindAll() ([]domain.Profile, error) {
 	return profilesAux, nil
 }
 
+func (port *profileMongoDb) FindByPermissionCode(permissionCode int) ([]domain.Profile, error) {
+	profiles, err := findMany[domain.Profile](context.Background(), port.getCollection(), bson.M{"permissionsCodes": permissionCode})
+	if err != nil {
+		return nil, fmt.Errorf("error on FindByPermissionCode: %w", err)
+	}
+	return profiles, nil
+}
+
+func (port *profileMongoDb) Save(profile domain.Profile) (*domain.Profile, error) {
+	filter := bson.M{"code": profile.Code}
+	update := bson.M{"$set": profile}
+	_, err := port.getCollection().UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, fmt.Errorf("error on Save profile: %w", err)
+	}
+	return &profile, nil
+}
+
+func (port *profileMongoDb) Delete(code int) error {
+	_, err := port.getCollection().DeleteOne(context.Background(), bson.M{"code": code})
+	return err
+}
+
 // private
 
 func (port *profileMongoDb) getCollection() *mongo.Collection {
@@ -0,0 +1,86 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	rateLimitCollection = "rateLimits"
+)
+
+type rateLimitDocument struct {
+	Key     string  `bson:"_id"`
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+type rateLimitMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+// NewRateLimitMongoDB the buckets are shared by all the replicas, the expired buckets are
+// removed by the ttl index.
+func NewRateLimitMongoDB(mongoDB *mongo.Database) repository.RateLimitRepository {
+	return &rateLimitMongoDB{mongoDB}
+}
+
+// Take implements repository.RateLimitRepository.
+func (port *rateLimitMongoDB) Take(key string, limit domain.RateLimit) (bool, time.Duration, error) {
+	now := time.Now()
+	capacity := float64(limit.Capacity)
+
+	// the refill and the take are done in one atomic update
+	refilledTokens := bson.M{"$min": bson.A{
+		capacity,
+		bson.M{"$add": bson.A{
+			bson.M{"$ifNull": bson.A{"$tokens", capacity}},
+			bson.M{"$multiply": bson.A{
+				bson.M{"$divide": bson.A{
+					bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updatedDate", now}}}},
+					1000,
+				}},
+				limit.TokensPerSecond(),
+			}},
+		}},
+	}}
+	hasToken := bson.M{"$gte": bson.A{"$tokens", 1}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens":         refilledTokens,
+			"updatedDate":    now,
+			"expirationDate": now.Add(limit.Period),
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": hasToken,
+			"tokens":  bson.M{"$cond": bson.A{hasToken, bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var bucket rateLimitDocument
+	err := port.getCollection().FindOneAndUpdate(context.Background(), bson.M{"_id": key}, update, opts).Decode(&bucket)
+	if mongo.IsDuplicateKeyError(err) {
+		// other replica created the bucket at the same time
+		err = port.getCollection().FindOneAndUpdate(context.Background(), bson.M{"_id": key}, update, opts).Decode(&bucket)
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	if !bucket.Allowed {
+		return false, limit.RetryAfter(bucket.Tokens), nil
+	}
+	return true, 0, nil
+}
+
+// private
+
+func (port *rateLimitMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(rateLimitCollection)
+}
@@ -0,0 +1,140 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	serviceAccountCollection = "serviceAccounts"
+	apiKeyCollection         = "apiKeys"
+)
+
+type serviceAccountMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewServiceAccountMongoDB(mongoDB *mongo.Database) repository.ServiceAccountRepository {
+	return &serviceAccountMongoDB{mongoDB}
+}
+
+// FindById implements repository.ServiceAccountRepository.
+func (port *serviceAccountMongoDB) FindById(id string) (*domain.ServiceAccount, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+	serviceAccount, err := findOne[domain.ServiceAccount](context.Background(), port.getCollection(), bson.M{"_id": objectId})
+	if err != nil {
+		return nil, fmt.Errorf("error FindById. serviceAccountId: %s. error: %w", id, err)
+	}
+	return serviceAccount, nil
+}
+
+// FindByName implements repository.ServiceAccountRepository.
+func (port *serviceAccountMongoDB) FindByName(name string) (*domain.ServiceAccount, error) {
+	return findOne[domain.ServiceAccount](context.Background(), port.getCollection(), bson.M{"name": name})
+}
+
+// FindAll implements repository.ServiceAccountRepository.
+func (port *serviceAccountMongoDB) FindAll() ([]domain.ServiceAccount, error) {
+	return findMany[domain.ServiceAccount](context.Background(), port.getCollection(), bson.M{})
+}
+
+// Save implements repository.ServiceAccountRepository.
+func (port *serviceAccountMongoDB) Save(serviceAccount domain.ServiceAccount) (*domain.ServiceAccount, error) {
+	if serviceAccount.Id == nil {
+		result, err := port.getCollection().InsertOne(context.Background(), serviceAccount)
+		if err != nil {
+			return nil, err
+		}
+		id := result.InsertedID.(primitive.ObjectID)
+		serviceAccount.Id = &id
+		return &serviceAccount, nil
+	}
+	_, err := port.getCollection().ReplaceOne(context.Background(), bson.M{"_id": serviceAccount.Id}, serviceAccount)
+	if err != nil {
+		return nil, err
+	}
+	return &serviceAccount, nil
+}
+
+// Delete implements repository.ServiceAccountRepository.
+func (port *serviceAccountMongoDB) Delete(id primitive.ObjectID) error {
+	_, err := port.getCollection().DeleteOne(context.Background(), bson.M{"_id": id})
+	return err
+}
+
+// private
+
+func (port *serviceAccountMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(serviceAccountCollection)
+}
+
+type apiKeyMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewApiKeyMongoDB(mongoDB *mongo.Database) repository.ApiKeyRepository {
+	return &apiKeyMongoDB{mongoDB}
+}
+
+// FindByPrefix implements repository.ApiKeyRepository.
+func (port *apiKeyMongoDB) FindByPrefix(prefix string) (*domain.ApiKey, error) {
+	return findOne[domain.ApiKey](context.Background(), port.getCollection(), bson.M{"prefix": prefix})
+}
+
+// FindByServiceAccountId implements repository.ApiKeyRepository.
+func (port *apiKeyMongoDB) FindByServiceAccountId(serviceAccountId primitive.ObjectID) ([]domain.ApiKey, error) {
+	return findMany[domain.ApiKey](context.Background(), port.getCollection(), bson.M{"serviceAccountId": serviceAccountId})
+}
+
+// Save implements repository.ApiKeyRepository.
+func (port *apiKeyMongoDB) Save(apiKey domain.ApiKey) (*domain.ApiKey, error) {
+	result, err := port.getCollection().InsertOne(context.Background(), apiKey)
+	if err != nil {
+		return nil, err
+	}
+	id := result.InsertedID.(primitive.ObjectID)
+	apiKey.Id = &id
+	return &apiKey, nil
+}
+
+// Revoke implements repository.ApiKeyRepository.
+func (port *apiKeyMongoDB) Revoke(serviceAccountId primitive.ObjectID, id primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id":              id,
+		"serviceAccountId": serviceAccountId,
+		"revokedDate":      bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revokedDate": time.Now()}}
+	result, err := port.getCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RevokeByServiceAccountId implements repository.ApiKeyRepository.
+func (port *apiKeyMongoDB) RevokeByServiceAccountId(serviceAccountId primitive.ObjectID) error {
+	filter := bson.M{
+		"serviceAccountId": serviceAccountId,
+		"revokedDate":      bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revokedDate": time.Now()}}
+	_, err := port.getCollection().UpdateMany(context.Background(), filter, update)
+	return err
+}
+
+// private
+
+func (port *apiKeyMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(apiKeyCollection)
+}
@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erodriguezg/meet/pkg/core/domain"
+	"github.com/erodriguezg/meet/pkg/core/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	watermarkedFileCollection = "watermarkedFiles"
+)
+
+type watermarkedFileMongoDB struct {
+	mongoDB *mongo.Database
+}
+
+func NewWatermarkedFileMongoDB(mongoDB *mongo.Database) repository.WatermarkedFileRepository {
+	return &watermarkedFileMongoDB{mongoDB}
+}
+
+// FindBySourceFileHashAndPersonId implements repository.WatermarkedFileRepository.
+func (port *watermarkedFileMongoDB) FindBySourceFileHashAndPersonId(sourceFileHash string, personId string) (*domain.WatermarkedFile, error) {
+	personObjectId, err := primitive.ObjectIDFromHex(personId)
+	if err != nil {
+		return nil, fmt.Errorf("error on FindBySourceFileHashAndPersonId getting objectIdFromHex from personId: %s. error: %w", personId, err)
+	}
+	filter := bson.M{
+		"sourceFileHash": sourceFileHash,
+		"personId":       personObjectId,
+	}
+	return findOne[domain.WatermarkedFile](context.Background(), port.getCollection(), filter)
+}
+
+// Save implements repository.WatermarkedFileRepository.
+func (port *watermarkedFileMongoDB) Save(watermarkedFile domain.WatermarkedFile) (*domain.WatermarkedFile, error) {
+	if watermarkedFile.Id == nil {
+		result, err := port.getCollection().InsertOne(context.Background(), watermarkedFile)
+		if err != nil {
+			return nil, err
+		}
+		auxId, ok := result.InsertedID.(primitive.ObjectID)
+		if !ok {
+			return nil, fmt.Errorf("failed to convert InsertedID to ObjectID")
+		}
+		watermarkedFile.Id = &auxId
+		return &watermarkedFile, nil
+	}
+
+	filter := bson.M{"_id": watermarkedFile.Id}
+	update := bson.M{"$set": watermarkedFile}
+	_, err := port.getCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, err
+	}
+	return &watermarkedFile, nil
+}
+
+// DeleteByFileHash implements repository.WatermarkedFileRepository.
+func (port *watermarkedFileMongoDB) DeleteByFileHash(fileHash string) error {
+	_, err := port.getCollection().DeleteMany(context.Background(), bson.M{"fileHash": fileHash})
+	return err
+}
+
+// private
+
+func (port *watermarkedFileMongoDB) getCollection() *mongo.Collection {
+	return port.mongoDB.Collection(watermarkedFileCollection)
+}
@@ -9,11 +9,21 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/erodriguezg/meet/pkg/core/domain"
 	"github.com/erodriguezg/meet/pkg/core/repository"
 )
 
 // https://developer.paypal.com/docs/checkout/standard/integrate/
 
+// https://developer.paypal.com/docs/reports/reference/paypal-supported-currencies/
+// HUF and TWD are left out, paypal does not accept their decimals although ISO 4217 has them
+var paypalCurrencyCodes = map[string]bool{
+	"AUD": true, "BRL": true, "CAD": true, "CHF": true, "CNY": true, "CZK": true,
+	"DKK": true, "EUR": true, "GBP": true, "HKD": true, "ILS": true, "JPY": true,
+	"MXN": true, "MYR": true, "NOK": true, "NZD": true, "PHP": true, "PLN": true,
+	"SEK": true, "SGD": true, "THB": true, "USD": true,
+}
+
 type paypalPayClient struct {
 	httpClient       *http.Client
 	paypalBaseApiUrl string
@@ -54,7 +64,14 @@ func (port *paypalPayClient) GetClientData() (map[string]any, error) {
 	}, nil
 }
 
+func (port *paypalPayClient) SupportsCurrency(currencyCode string) bool {
+	return paypalCurrencyCodes[currencyCode]
+}
+
 func (port *paypalPayClient) CreateOrder(value float64, currencyCode string) (orderID string, outputErr error) {
+	if !port.SupportsCurrency(currencyCode) {
+		return "", fmt.Errorf("paypal does not support the currency %s", currencyCode)
+	}
 	paypalCreateOrderUrl := fmt.Sprintf("%s/v2/checkout/orders", port.paypalBaseApiUrl)
 	accessToken, err := port.generateAccessToken()
 	if err != nil {
@@ -67,7 +84,7 @@ func (port *paypalPayClient) CreateOrder(value float64, currencyCode string) (or
 			{
 				Amount: PurchaseUnitAmount{
 					CurrencyCode: currencyCode,
-					Value:        fmt.Sprintf("%.2f", value),
+					Value:        domain.FormatCurrency(value, currencyCode),
 				},
 			},
 		},
@@ -0,0 +1,56 @@
+package paypalcli_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/infrastructure/paypalcli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPaypalServer answers the token and the orders, the amounts of the created orders are kept
+func newPaypalServer(t *testing.T, amounts *[]paypalcli.PurchaseUnitAmount) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token"})
+	})
+	mux.HandleFunc("/v2/checkout/orders", func(w http.ResponseWriter, r *http.Request) {
+		var payload paypalcli.CreateOrderPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		*amounts = append(*amounts, payload.PurchaseUnits[0].Amount)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "order"})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCreateOrderFormatsTheMinorUnits(t *testing.T) {
+	var amounts []paypalcli.PurchaseUnitAmount
+	server := newPaypalServer(t, &amounts)
+	client := paypalcli.NewPayPalPaymentClientRepository(server.Client(), server.URL, "client", "secret")
+
+	_, err := client.CreateOrder(1499.6, "JPY")
+	require.NoError(t, err)
+	_, err = client.CreateOrder(9.234, "EUR")
+	require.NoError(t, err)
+
+	assert.Equal(t, []paypalcli.PurchaseUnitAmount{
+		{CurrencyCode: "JPY", Value: "1500"},
+		{CurrencyCode: "EUR", Value: "9.23"},
+	}, amounts)
+}
+
+func TestCreateOrderRejectsUnsupportedCurrency(t *testing.T) {
+	var amounts []paypalcli.PurchaseUnitAmount
+	server := newPaypalServer(t, &amounts)
+	client := paypalcli.NewPayPalPaymentClientRepository(server.Client(), server.URL, "client", "secret")
+
+	assert.False(t, client.SupportsCurrency("CLP"))
+	_, err := client.CreateOrder(9444, "CLP")
+	assert.Error(t, err)
+	assert.Empty(t, amounts)
+}
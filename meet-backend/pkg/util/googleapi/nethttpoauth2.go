@@ -9,17 +9,13 @@ import (
 	"strings"
 )
 
-const (
-	oauth2BaseUrl  string = "https://oauth2.googleapis.com"
-	oauth2TokenUrl string = oauth2BaseUrl + "/token"
-)
-
 type netHttpOauth2Api struct {
 	httpClient *http.Client
+	tokenUrl   string
 }
 
-func NewNetHttpOauth2Api(httpClient *http.Client) OAuth2Api {
-	return &netHttpOauth2Api{httpClient}
+func NewNetHttpOauth2Api(httpClient *http.Client, tokenUrl string) OAuth2Api {
+	return &netHttpOauth2Api{httpClient, tokenUrl}
 }
 
 func (port *netHttpOauth2Api) ValidateAndGetToken(input OAuth2TokenRequest) (OAuth2TokenResponse, error) {
@@ -32,8 +28,11 @@ func (port *netHttpOauth2Api) ValidateAndGetToken(input OAuth2TokenRequest) (OAu
 	data.Set("client_secret", input.ClientSecret)
 	data.Set("redirect_uri", input.RedirectUri)
 	data.Set("grant_type", input.GrantType)
+	if input.CodeVerifier != "" {
+		data.Set("code_verifier", input.CodeVerifier)
+	}
 
-	request, err := http.NewRequest(http.MethodPost, oauth2TokenUrl, strings.NewReader(data.Encode()))
+	request, err := http.NewRequest(http.MethodPost, port.tokenUrl, strings.NewReader(data.Encode()))
 	if err != nil {
 		return output, err
 	}
@@ -6,6 +6,8 @@ type OAuth2TokenRequest struct {
 	ClientSecret string
 	RedirectUri  string
 	GrantType    string
+	// pkce, the secret of the code_challenge sent in the login url
+	CodeVerifier string
 }
 
 type OAuth2TokenResponse struct {
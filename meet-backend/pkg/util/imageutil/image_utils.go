@@ -0,0 +1,162 @@
+// Package imageutil is a small pure Go image pipeline (jpeg and png) used for
+// generate the previews of the uploaded files.
+package imageutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// MaxPixels bounds the memory of a decoded image, a small file can declare huge dimensions
+const MaxPixels = 64 * 1024 * 1024
+
+var ErrImageTooLarge = errors.New("the image has too many pixels")
+
+// Decode checks the dimensions in the header before decoding the pixels.
+func Decode(reader io.Reader) (image.Image, string, error) {
+	// the header read by DecodeConfig is decoded again with the rest of the reader
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(reader, &header))
+	if err != nil {
+		return nil, "", fmt.Errorf("error decoding image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, "", fmt.Errorf("error decoding image of %dx%d: %w", config.Width, config.Height, ErrImageTooLarge)
+	}
+
+	img, format, err := image.Decode(io.MultiReader(&header, reader))
+	if err != nil {
+		return nil, "", fmt.Errorf("error decoding image: %w", err)
+	}
+	return img, format, nil
+}
+
+// EncodeJpeg encodes the image over a white background, so the transparent areas
+// of a png are not turned black.
+func EncodeJpeg(img image.Image, quality int) ([]byte, error) {
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), img, bounds.Min, draw.Over)
+
+	var buffer bytes.Buffer
+	err := jpeg.Encode(&buffer, canvas, &jpeg.Options{Quality: quality})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding jpeg: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func EncodePng(img image.Image) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := png.Encode(&buffer, img)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding png: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// Resize scales the image to the width keeping the aspect ratio, averaging the source
+// pixels covered by every destination pixel. Images smaller than the width are not enlarged.
+func Resize(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= width {
+		return cloneRGBA(src)
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		srcY0 := bounds.Min.Y + y*bounds.Dy()/height
+		srcY1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			srcX0 := bounds.Min.X + x*bounds.Dx()/width
+			srcX1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+			dst.Set(x, y, averageColor(src, image.Rect(srcX0, srcY0, srcX1, srcY1)))
+		}
+	}
+	return dst
+}
+
+// Pixelate replaces every block of pixels with its average color, the result can not
+// be reverted for recover the original image.
+func Pixelate(src image.Image, blockSize int) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += blockSize {
+		for x := bounds.Min.X; x < bounds.Max.X; x += blockSize {
+			block := image.Rect(x, y, x+blockSize, y+blockSize).Intersect(bounds)
+			blockColor := averageColor(src, block)
+			dstBlock := block.Sub(bounds.Min)
+			draw.Draw(dst, dstBlock, image.NewUniform(blockColor), image.Point{}, draw.Src)
+		}
+	}
+	return dst
+}
+
+// VideoPlaceholder is a dark image with a play symbol, used as preview of the videos.
+func VideoPlaceholder(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{40, 40, 40, 255}), image.Point{}, draw.Src)
+
+	// triangle pointing to the right in the center
+	size := min(width, height) / 3
+	left := (width - size) / 2
+	top := (height - size) / 2
+	playColor := color.RGBA{220, 220, 220, 255}
+	for y := 0; y < size; y++ {
+		// the triangle is wider in the middle row
+		halfHeight := size / 2
+		distance := y - halfHeight
+		if distance < 0 {
+			distance = -distance
+		}
+		rowWidth := size - distance*2
+		for x := 0; x < rowWidth; x++ {
+			img.Set(left+x, top+y, playColor)
+		}
+	}
+	return img
+}
+
+// private
+
+func averageColor(src image.Image, rect image.Rectangle) color.RGBA {
+	if rect.Empty() {
+		rect = image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+1, rect.Min.Y+1)
+	}
+	var r, g, b, a, count uint64
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			cr, cg, cb, ca := src.At(x, y).RGBA()
+			r += uint64(cr)
+			g += uint64(cg)
+			b += uint64(cb)
+			a += uint64(ca)
+			count++
+		}
+	}
+	return color.RGBA{
+		R: uint8(r / count >> 8),
+		G: uint8(g / count >> 8),
+		B: uint8(b / count >> 8),
+		A: uint8(a / count >> 8),
+	}
+}
+
+func cloneRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	return dst
+}
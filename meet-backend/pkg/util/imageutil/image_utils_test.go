@@ -0,0 +1,73 @@
+package imageutil_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/util/imageutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkerboard(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x+y)%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+func TestResizeKeepsAspectRatio(t *testing.T) {
+	resized := imageutil.Resize(checkerboard(800, 400), 200)
+	assert.Equal(t, 200, resized.Bounds().Dx())
+	assert.Equal(t, 100, resized.Bounds().Dy())
+
+	// the checkerboard averages to gray
+	r, _, _, _ := resized.At(10, 10).RGBA()
+	assert.InDelta(t, 0x7fff, r, 0x0200)
+}
+
+func TestResizeDoesNotEnlarge(t *testing.T) {
+	resized := imageutil.Resize(checkerboard(100, 50), 200)
+	assert.Equal(t, 100, resized.Bounds().Dx())
+	assert.Equal(t, 50, resized.Bounds().Dy())
+}
+
+func TestPixelateUsesOneColorByBlock(t *testing.T) {
+	pixelated := imageutil.Pixelate(checkerboard(40, 40), 10)
+	assert.Equal(t, pixelated.At(0, 0), pixelated.At(9, 9))
+	assert.Equal(t, pixelated.At(10, 10), pixelated.At(19, 19))
+}
+
+func TestEncodeJpegCanBeDecoded(t *testing.T) {
+	content, err := imageutil.EncodeJpeg(imageutil.VideoPlaceholder(200, 112), 80)
+	require.NoError(t, err)
+
+	decoded, format, err := imageutil.Decode(bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 200, decoded.Bounds().Dx())
+}
+
+func TestDecodeRejectsTooManyPixels(t *testing.T) {
+	content, err := imageutil.EncodePng(checkerboard(4, 4))
+	require.NoError(t, err)
+
+	// a few bytes that declare a 100000x100000 image in the png header
+	binary.BigEndian.PutUint32(content[16:], 100000)
+	binary.BigEndian.PutUint32(content[20:], 100000)
+	binary.BigEndian.PutUint32(content[29:], crc32.ChecksumIEEE(content[12:29]))
+
+	_, _, err = imageutil.Decode(bytes.NewReader(content))
+	assert.ErrorIs(t, err, imageutil.ErrImageTooLarge)
+}
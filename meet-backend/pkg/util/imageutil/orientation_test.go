@@ -0,0 +1,75 @@
+package imageutil_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/util/imageutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withExifOrientation inserts an APP1 exif segment with only the orientation tag
+// after the start of image marker of the jpeg.
+func withExifOrientation(jpegContent []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	output := append([]byte{}, jpegContent[:2]...)
+	output = append(output, segment...)
+	return append(output, jpegContent[2:]...)
+}
+
+func TestReadOrientationFromJpegExif(t *testing.T) {
+	content, err := imageutil.EncodeJpeg(checkerboard(16, 8), 90)
+	require.NoError(t, err)
+
+	assert.Equal(t, imageutil.OrientationNormal, imageutil.ReadOrientation(content))
+	assert.Equal(t, 6, imageutil.ReadOrientation(withExifOrientation(content, 6)))
+}
+
+func TestReadOrientationIgnoresInvalidData(t *testing.T) {
+	assert.Equal(t, imageutil.OrientationNormal, imageutil.ReadOrientation(nil))
+	assert.Equal(t, imageutil.OrientationNormal, imageutil.ReadOrientation([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF}))
+}
+
+func TestReencodeDropsExif(t *testing.T) {
+	content, err := imageutil.EncodeJpeg(checkerboard(16, 8), 90)
+	require.NoError(t, err)
+
+	decoded, _, err := imageutil.Decode(bytes.NewReader(withExifOrientation(content, 6)))
+	require.NoError(t, err)
+	reencoded, err := imageutil.EncodeJpeg(decoded, 90)
+	require.NoError(t, err)
+
+	assert.False(t, bytes.Contains(reencoded, []byte("Exif")))
+}
+
+func TestOrientRotatesClockwise(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	src.Set(0, 0, color.RGBA{255, 0, 0, 255})
+
+	oriented := imageutil.Orient(src, 6)
+	assert.Equal(t, 2, oriented.Bounds().Dx())
+	assert.Equal(t, 4, oriented.Bounds().Dy())
+	// the top left corner goes to the top right
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, oriented.At(1, 0))
+}
+
+func TestOrientNormalKeepsImage(t *testing.T) {
+	src := checkerboard(4, 2)
+	assert.Same(t, src, imageutil.Orient(src, imageutil.OrientationNormal))
+}
@@ -2,10 +2,15 @@ package jwtutil
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// clock difference allowed with the issuers of the tokens
+const clockLeeway = time.Minute
+
 type golangJwtUtil struct{}
 
 func NewGolangJwtUtil() JwtUtil {
@@ -25,3 +30,31 @@ func (port *golangJwtUtil) ParseWithoutKey(jwtString string) (map[string]any, er
 		return nil, fmt.Errorf("can not parse claims jwt")
 	}
 }
+
+// ParseWithKeySet implements JwtUtil
+func (port *golangJwtUtil) ParseWithKeySet(jwtString string, keySet KeySet, validation ClaimsValidation) (map[string]any, error) {
+	token, err := jwt.Parse(jwtString,
+		func(token *jwt.Token) (any, error) {
+			keyId, _ := token.Header["kid"].(string)
+			return keySet.GetKey(keyId)
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithAudience(validation.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockLeeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("can not parse claims jwt")
+	}
+	issuer, _ := claims.GetIssuer()
+	if !slices.Contains(validation.Issuers, issuer) {
+		return nil, fmt.Errorf("invalid jwt issuer: '%s'", issuer)
+	}
+	return claims, nil
+}
@@ -0,0 +1,141 @@
+package jwtutil
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// used when the response does not have a cache-control max-age
+	jwksDefaultCacheDuration = time.Hour
+	// an unknown key id forces a refresh (the keys are rotated), but not more often than this
+	jwksMinRefreshInterval = time.Minute
+)
+
+var maxAgeRegexp = regexp.MustCompile(`max-age=(\d+)`)
+
+type jwksKeySet struct {
+	httpClient  *http.Client
+	jwksUrl     string
+	mutex       sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	expiresAt   time.Time
+	lastFetchAt time.Time
+}
+
+// NewJwksKeySet returns a KeySet with the rsa keys published in the jwks url, the keys
+// are cached by the time told by the cache-control header of the response.
+func NewJwksKeySet(httpClient *http.Client, jwksUrl string) KeySet {
+	return &jwksKeySet{
+		httpClient: httpClient,
+		jwksUrl:    jwksUrl,
+		keys:       map[string]*rsa.PublicKey{},
+	}
+}
+
+// GetKey implements KeySet
+func (port *jwksKeySet) GetKey(keyId string) (any, error) {
+	port.mutex.Lock()
+	defer port.mutex.Unlock()
+
+	now := time.Now()
+	key, found := port.keys[keyId]
+	expired := now.After(port.expiresAt)
+	unknownKey := !found && now.Sub(port.lastFetchAt) >= jwksMinRefreshInterval
+	if expired || unknownKey {
+		err := port.fetchKeys(now)
+		if err != nil {
+			return nil, err
+		}
+		key, found = port.keys[keyId]
+	}
+
+	if !found {
+		return nil, fmt.Errorf("the jwks does not have the key id: '%s'", keyId)
+	}
+	return key, nil
+}
+
+// private
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (port *jwksKeySet) fetchKeys(now time.Time) error {
+	port.lastFetchAt = now
+
+	response, err := port.httpClient.Get(port.jwksUrl)
+	if err != nil {
+		return fmt.Errorf("error getting the jwks: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("error getting the jwks, status code: %d", response.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&jwks)
+	if err != nil {
+		return fmt.Errorf("error decoding the jwks: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return fmt.Errorf("error decoding the jwks key '%s': %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	port.keys = keys
+	port.expiresAt = now.Add(cacheDuration(response.Header.Get("Cache-Control")))
+	return nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	e := new(big.Int).SetBytes(exponent)
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(e.Int64())}, nil
+}
+
+func cacheDuration(cacheControl string) time.Duration {
+	match := maxAgeRegexp.FindStringSubmatch(cacheControl)
+	if match == nil {
+		return jwksDefaultCacheDuration
+	}
+	seconds, err := strconv.Atoi(match[1])
+	if err != nil {
+		return jwksDefaultCacheDuration
+	}
+	return time.Duration(seconds) * time.Second
+}
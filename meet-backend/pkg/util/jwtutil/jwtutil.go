@@ -1,5 +1,21 @@
 package jwtutil
 
+// KeySet gives the public key for verify the signature of a jwt by its key id (kid header).
+type KeySet interface {
+	GetKey(keyId string) (any, error)
+}
+
+type ClaimsValidation struct {
+	// accepted values of the iss claim
+	Issuers []string
+	// required value in the aud claim
+	Audience string
+}
+
 type JwtUtil interface {
 	ParseWithoutKey(jwt string) (map[string]any, error)
+
+	// ParseWithKeySet verifies the rs256 signature with the key set, the expiration
+	// and the issuer and audience claims before return the claims.
+	ParseWithKeySet(jwt string, keySet KeySet, validation ClaimsValidation) (map[string]any, error)
 }
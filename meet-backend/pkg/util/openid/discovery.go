@@ -0,0 +1,45 @@
+package openid
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+func fetchDiscoveryDocument(httpClient *http.Client, issuer string) (*discoveryDocument, error) {
+	response, err := httpClient.Get(strings.TrimSuffix(issuer, "/") + discoveryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the openid discovery document of %s: %w", issuer, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("error getting the openid discovery document of %s, status code: %d", issuer, response.StatusCode)
+	}
+
+	var document discoveryDocument
+	err = json.NewDecoder(response.Body).Decode(&document)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding the openid discovery document of %s: %w", issuer, err)
+	}
+
+	// the issuer of the document must be the configured one, so a document can not
+	// redirect the keys to other issuer
+	if strings.TrimSuffix(document.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("the openid discovery document of %s has other issuer: %s", issuer, document.Issuer)
+	}
+	if document.AuthorizationEndpoint == "" || document.TokenEndpoint == "" || document.JwksUri == "" {
+		return nil, fmt.Errorf("the openid discovery document of %s is incomplete", issuer)
+	}
+	return &document, nil
+}
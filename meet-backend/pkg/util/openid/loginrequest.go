@@ -0,0 +1,80 @@
+package openid
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+const randomValueBytes = 32
+
+// LoginRequest are the values of one login, the state and the nonce travel in the
+// login url and the code verifier must be kept in the backend until the callback.
+type LoginRequest struct {
+	// signed state id: <stateId>.<hmac>
+	State        string
+	StateId      string
+	Nonce        string
+	CodeVerifier string
+}
+
+// NewLoginRequest generates random values for a login, the state is signed with
+// the pass phrase so the callbacks with forged states are rejected without lookups.
+func NewLoginRequest(statePassPhrase string) (LoginRequest, error) {
+	stateId, err := randomValue()
+	if err != nil {
+		return LoginRequest{}, err
+	}
+	nonce, err := randomValue()
+	if err != nil {
+		return LoginRequest{}, err
+	}
+	codeVerifier, err := randomValue()
+	if err != nil {
+		return LoginRequest{}, err
+	}
+	return LoginRequest{
+		State:        stateId + "." + signState(stateId, statePassPhrase),
+		StateId:      stateId,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}, nil
+}
+
+// VerifyState returns the state id of a state signed with the pass phrase.
+func VerifyState(state string, statePassPhrase string) (string, bool) {
+	stateId, signature, found := strings.Cut(state, ".")
+	if !found || stateId == "" {
+		return "", false
+	}
+	expected := signState(stateId, statePassPhrase)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return "", false
+	}
+	return stateId, true
+}
+
+// CodeChallenge is the pkce S256 challenge of the code verifier.
+func CodeChallenge(codeVerifier string) string {
+	digest := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// private
+
+func signState(stateId string, statePassPhrase string) string {
+	mac := hmac.New(sha256.New, []byte(statePassPhrase))
+	mac.Write([]byte(stateId))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func randomValue() (string, error) {
+	value := make([]byte, randomValueBytes)
+	_, err := rand.Read(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
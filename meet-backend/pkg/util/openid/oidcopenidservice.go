@@ -0,0 +1,157 @@
+package openid
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/erodriguezg/meet/pkg/util/googleapi"
+	"github.com/erodriguezg/meet/pkg/util/jwtutil"
+	"go.uber.org/zap"
+)
+
+const (
+	GoogleIssuer string = "https://accounts.google.com"
+	defaultScope string = "openid email profile"
+)
+
+// issuerAliases other values of the iss claim used by the providers
+var issuerAliases = map[string][]string{
+	GoogleIssuer: {"accounts.google.com"},
+}
+
+type oidcOpenIdService struct {
+	config     OpenIdConfig
+	httpClient *http.Client
+	jwtUtil    jwtutil.JwtUtil
+	log        *zap.Logger
+
+	// loaded from the discovery document on the first use
+	mutex     sync.Mutex
+	discovery *discoveryDocument
+	oauth2Api googleapi.OAuth2Api
+	keySet    jwtutil.KeySet
+}
+
+// NewOidcOpenIdService a provider configured by its discovery document, the document
+// is read on the first login so the backend starts with the provider down.
+func NewOidcOpenIdService(config OpenIdConfig, httpClient *http.Client, jwtUtil jwtutil.JwtUtil, log *zap.Logger) OpenIdService {
+	if config.Scope == "" {
+		config.Scope = defaultScope
+	}
+	return &oidcOpenIdService{
+		config:     config,
+		httpClient: httpClient,
+		jwtUtil:    jwtUtil,
+		log:        log,
+	}
+}
+
+// GetName implements OpenIdService
+func (port *oidcOpenIdService) GetName() string {
+	return port.config.Name
+}
+
+// GetLoginUrl implements OpenIdService
+func (port *oidcOpenIdService) GetLoginUrl(request LoginRequest) (string, error) {
+	discovery, err := port.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	urlTarget := discovery.AuthorizationEndpoint + separator
+	urlTarget += "response_type=code"
+	urlTarget += "&client_id=" + url.QueryEscape(port.config.ClientId)
+	urlTarget += "&scope=" + url.QueryEscape(port.config.Scope)
+	urlTarget += "&redirect_uri=" + url.QueryEscape(port.config.RedirectUri)
+	urlTarget += "&state=" + url.QueryEscape(request.State)
+	urlTarget += "&nonce=" + url.QueryEscape(request.Nonce)
+	urlTarget += "&code_challenge=" + CodeChallenge(request.CodeVerifier)
+	urlTarget += "&code_challenge_method=S256"
+	urlTarget += "&prompt=consent"
+	return urlTarget, nil
+}
+
+// ProcessCallback implements OpenIdService
+func (port *oidcOpenIdService) ProcessCallback(code string, request LoginRequest) (OpenIdUser, error) {
+	var openIdUser OpenIdUser
+
+	discovery, err := port.getDiscovery()
+	if err != nil {
+		return openIdUser, err
+	}
+
+	tokenRequest := googleapi.OAuth2TokenRequest{
+		Code:         code,
+		ClientId:     port.config.ClientId,
+		ClientSecret: port.config.ClientSecret,
+		RedirectUri:  port.config.RedirectUri,
+		GrantType:    "authorization_code",
+		CodeVerifier: request.CodeVerifier,
+	}
+
+	tokenResponse, err := port.oauth2Api.ValidateAndGetToken(tokenRequest)
+	if err != nil {
+		return openIdUser, err
+	}
+
+	jwt, err := port.jwtUtil.ParseWithKeySet(tokenResponse.IdToken, port.keySet, jwtutil.ClaimsValidation{
+		Issuers:  append([]string{discovery.Issuer}, issuerAliases[discovery.Issuer]...),
+		Audience: port.config.ClientId,
+	})
+	if err != nil {
+		return openIdUser, fmt.Errorf("invalid openid id token: %w", err)
+	}
+
+	if nonce, _ := jwt["nonce"].(string); nonce == "" || nonce != request.Nonce {
+		return openIdUser, fmt.Errorf("invalid openid id token: the nonce does not match")
+	}
+	emailVerified, _ := jwt["email_verified"].(bool)
+	if !emailVerified && !port.config.TrustEmails {
+		return openIdUser, fmt.Errorf("invalid openid id token: the email is not verified")
+	}
+
+	openIdUser.Provider = port.config.Name
+	openIdUser.Subject, _ = jwt["sub"].(string)
+	openIdUser.Email, _ = jwt["email"].(string)
+	openIdUser.EmailVerified = emailVerified
+	openIdUser.FirstName, _ = jwt["given_name"].(string)
+	openIdUser.LastName, _ = jwt["family_name"].(string)
+	openIdUser.PictureUrl, _ = jwt["picture"].(string)
+	openIdUser.Locale, _ = jwt["locale"].(string)
+
+	if openIdUser.Subject == "" || openIdUser.Email == "" {
+		return openIdUser, fmt.Errorf("invalid openid id token: without subject or email")
+	}
+
+	return openIdUser, nil
+}
+
+// private
+
+func (port *oidcOpenIdService) getDiscovery() (*discoveryDocument, error) {
+	port.mutex.Lock()
+	defer port.mutex.Unlock()
+
+	if port.discovery != nil {
+		return port.discovery, nil
+	}
+
+	discovery, err := fetchDiscoveryDocument(port.httpClient, port.config.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	port.log.Info("openid provider discovered",
+		zap.String("provider", port.config.Name), zap.String("issuer", discovery.Issuer))
+
+	port.oauth2Api = googleapi.NewNetHttpOauth2Api(port.httpClient, discovery.TokenEndpoint)
+	port.keySet = jwtutil.NewJwksKeySet(port.httpClient, discovery.JwksUri)
+	port.discovery = discovery
+	return discovery, nil
+}
@@ -0,0 +1,352 @@
+package openid_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erodriguezg/meet/pkg/util/jwtutil"
+	"github.com/erodriguezg/meet/pkg/util/openid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	clientId        = "test-client"
+	clientSecret    = "test-secret"
+	statePassPhrase = "test-pass-phrase"
+)
+
+type authorization struct {
+	nonce         string
+	codeChallenge string
+}
+
+// fakeOpenIdProvider a local openid provider with the token and jwks endpoints, the
+// login in the provider is simulated with authorize.
+type fakeOpenIdProvider struct {
+	server         *httptest.Server
+	key            *rsa.PrivateKey
+	keyId          string
+	mutex          sync.Mutex
+	authorizations map[string]authorization
+	jwksFetches    int
+	// issuer published in the discovery document
+	discoveryIssuer string
+	// changes the claims of the next id tokens
+	claimsModifier func(claims jwt.MapClaims)
+	// signs the next id tokens with other key
+	signingKey *rsa.PrivateKey
+}
+
+func newFakeOpenIdProvider(t *testing.T) *fakeOpenIdProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	provider := &fakeOpenIdProvider{
+		key:            key,
+		keyId:          "test-key",
+		authorizations: map[string]authorization{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", provider.discovery)
+	mux.HandleFunc("/token", provider.token)
+	mux.HandleFunc("/jwks", provider.jwks)
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+func (provider *fakeOpenIdProvider) issuer() string {
+	return provider.server.URL
+}
+
+// authorize reads the login url like the provider does and returns the code of the callback
+func (provider *fakeOpenIdProvider) authorize(t *testing.T, loginUrl string) string {
+	parsedUrl, err := url.Parse(loginUrl)
+	require.NoError(t, err)
+	query := parsedUrl.Query()
+	require.Equal(t, clientId, query.Get("client_id"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	code := "code-" + query.Get("state")
+	provider.authorizations[code] = authorization{
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	return code
+}
+
+func (provider *fakeOpenIdProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := provider.issuer()
+	if provider.discoveryIssuer != "" {
+		issuer = provider.discoveryIssuer
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 issuer,
+		"authorization_endpoint": provider.server.URL + "/auth",
+		"token_endpoint":         provider.server.URL + "/token",
+		"jwks_uri":               provider.server.URL + "/jwks",
+	})
+}
+
+func (provider *fakeOpenIdProvider) token(w http.ResponseWriter, r *http.Request) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	_ = r.ParseForm()
+	code := r.PostForm.Get("code")
+	auth, found := provider.authorizations[code]
+	delete(provider.authorizations, code)
+	if !found || r.PostForm.Get("client_id") != clientId || r.PostForm.Get("client_secret") != clientSecret ||
+		openid.CodeChallenge(r.PostForm.Get("code_verifier")) != auth.codeChallenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            provider.issuer(),
+		"aud":            clientId,
+		"sub":            "1234",
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          auth.nonce,
+		"email":          "user@test.com",
+		"email_verified": true,
+		"given_name":     "Test",
+		"family_name":    "User",
+	}
+	if provider.claimsModifier != nil {
+		provider.claimsModifier(claims)
+	}
+	signingKey := provider.key
+	if provider.signingKey != nil {
+		signingKey = provider.signingKey
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = provider.keyId
+	signed, err := idToken.SignedString(signingKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"id_token": signed, "token_type": "Bearer"})
+}
+
+func (provider *fakeOpenIdProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.jwksFetches++
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kid": provider.keyId,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(provider.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(provider.key.E)).Bytes()),
+		}},
+	})
+}
+
+func newOpenIdService(provider *fakeOpenIdProvider) openid.OpenIdService {
+	config := openid.OpenIdConfig{
+		Name:         "fake",
+		Issuer:       provider.issuer(),
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		RedirectUri:  "http://localhost/callback",
+	}
+	return openid.NewOidcOpenIdService(config, provider.server.Client(), jwtutil.NewGolangJwtUtil(), zap.NewNop())
+}
+
+// login starts a login and returns the code given by the provider
+func login(t *testing.T, provider *fakeOpenIdProvider, service openid.OpenIdService) (string, openid.LoginRequest) {
+	request, err := openid.NewLoginRequest(statePassPhrase)
+	require.NoError(t, err)
+	loginUrl, err := service.GetLoginUrl(request)
+	require.NoError(t, err)
+	return provider.authorize(t, loginUrl), request
+}
+
+func TestProcessCallbackReturnsVerifiedUser(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	service := newOpenIdService(provider)
+
+	code, request := login(t, provider, service)
+	user, err := service.ProcessCallback(code, request)
+	require.NoError(t, err)
+
+	assert.Equal(t, "fake", user.Provider)
+	assert.Equal(t, "1234", user.Subject)
+	assert.Equal(t, "user@test.com", user.Email)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, "Test User", user.GetFullName())
+}
+
+func TestProcessCallbackAcceptsTrustedEmailsWithoutVerifiedClaim(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	provider.claimsModifier = func(claims jwt.MapClaims) { delete(claims, "email_verified") }
+	config := openid.OpenIdConfig{
+		Name:         "directory",
+		Issuer:       provider.issuer(),
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		TrustEmails:  true,
+	}
+	service := openid.NewOidcOpenIdService(config, provider.server.Client(), jwtutil.NewGolangJwtUtil(), zap.NewNop())
+
+	code, request := login(t, provider, service)
+	user, err := service.ProcessCallback(code, request)
+	require.NoError(t, err)
+	assert.Equal(t, "user@test.com", user.Email)
+	assert.False(t, user.EmailVerified)
+}
+
+func TestDiscoveryRejectsOtherIssuer(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	provider.discoveryIssuer = "https://evil.test"
+	service := newOpenIdService(provider)
+
+	request, err := openid.NewLoginRequest(statePassPhrase)
+	require.NoError(t, err)
+	_, err = service.GetLoginUrl(request)
+	assert.ErrorContains(t, err, "other issuer")
+}
+
+func TestProviderRegistry(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	first := newOpenIdService(provider)
+	second := openid.NewOidcOpenIdService(openid.OpenIdConfig{Name: "other", Issuer: provider.issuer(), ClientId: clientId},
+		provider.server.Client(), jwtutil.NewGolangJwtUtil(), zap.NewNop())
+
+	registry := openid.NewOpenIdProviderRegistry(first, second)
+	assert.Equal(t, "fake", registry.GetDefaultProviderName())
+	assert.Equal(t, []string{"fake", "other"}, registry.GetProviderNames())
+
+	found, ok := registry.GetProvider("other")
+	assert.True(t, ok)
+	assert.Same(t, second, found)
+	_, ok = registry.GetProvider("unknown")
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { openid.NewOpenIdProviderRegistry(first, first) })
+}
+
+func TestProcessCallbackRejectsWrongCodeVerifier(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	service := newOpenIdService(provider)
+
+	code, request := login(t, provider, service)
+	request.CodeVerifier = "stolen-code-without-verifier"
+	_, err := service.ProcessCallback(code, request)
+	assert.Error(t, err)
+}
+
+func TestProcessCallbackRejectsNonceOfOtherLogin(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	service := newOpenIdService(provider)
+
+	code, request := login(t, provider, service)
+	request.Nonce = "nonce-of-other-login"
+	_, err := service.ProcessCallback(code, request)
+	assert.ErrorContains(t, err, "nonce")
+}
+
+func TestProcessCallbackRejectsInvalidIdTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cases := map[string]func(provider *fakeOpenIdProvider){
+		"other audience": func(provider *fakeOpenIdProvider) {
+			provider.claimsModifier = func(claims jwt.MapClaims) { claims["aud"] = "other-client" }
+		},
+		"other issuer": func(provider *fakeOpenIdProvider) {
+			provider.claimsModifier = func(claims jwt.MapClaims) { claims["iss"] = "https://evil.test" }
+		},
+		"expired": func(provider *fakeOpenIdProvider) {
+			provider.claimsModifier = func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }
+		},
+		"email not verified": func(provider *fakeOpenIdProvider) {
+			provider.claimsModifier = func(claims jwt.MapClaims) { claims["email_verified"] = false }
+		},
+		"signed by other key": func(provider *fakeOpenIdProvider) {
+			provider.signingKey = otherKey
+		},
+	}
+
+	for name, modify := range cases {
+		t.Run(name, func(t *testing.T) {
+			provider := newFakeOpenIdProvider(t)
+			service := newOpenIdService(provider)
+			modify(provider)
+
+			code, request := login(t, provider, service)
+			_, err := service.ProcessCallback(code, request)
+			assert.ErrorContains(t, err, "invalid openid id token")
+		})
+	}
+}
+
+func TestJwksKeysAreCached(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	service := newOpenIdService(provider)
+
+	for i := 0; i < 3; i++ {
+		code, request := login(t, provider, service)
+		_, err := service.ProcessCallback(code, request)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, provider.jwksFetches)
+}
+
+func TestLoginUrlHasChallengeAndNonce(t *testing.T) {
+	provider := newFakeOpenIdProvider(t)
+	service := newOpenIdService(provider)
+
+	request, err := openid.NewLoginRequest(statePassPhrase)
+	require.NoError(t, err)
+	loginUrl, err := service.GetLoginUrl(request)
+	require.NoError(t, err)
+	parsedUrl, err := url.Parse(loginUrl)
+	require.NoError(t, err)
+
+	query := parsedUrl.Query()
+	assert.Equal(t, request.State, query.Get("state"))
+	assert.Equal(t, request.Nonce, query.Get("nonce"))
+	assert.Equal(t, openid.CodeChallenge(request.CodeVerifier), query.Get("code_challenge"))
+	assert.NotContains(t, parsedUrl.RawQuery, request.CodeVerifier)
+}
+
+func TestVerifyState(t *testing.T) {
+	request, err := openid.NewLoginRequest(statePassPhrase)
+	require.NoError(t, err)
+	other, err := openid.NewLoginRequest(statePassPhrase)
+	require.NoError(t, err)
+	assert.NotEqual(t, request.StateId, other.StateId)
+
+	stateId, ok := openid.VerifyState(request.State, statePassPhrase)
+	assert.True(t, ok)
+	assert.Equal(t, request.StateId, stateId)
+
+	_, ok = openid.VerifyState(request.State, "other-pass-phrase")
+	assert.False(t, ok)
+	_, ok = openid.VerifyState(other.StateId+"."+request.State[len(request.StateId)+1:], statePassPhrase)
+	assert.False(t, ok)
+	_, ok = openid.VerifyState(request.StateId, statePassPhrase)
+	assert.False(t, ok)
+}
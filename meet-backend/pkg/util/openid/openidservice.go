@@ -3,11 +3,17 @@ package openid
 import "fmt"
 
 type OpenIdUser struct {
-	Email      string
-	FirstName  string
-	LastName   string
-	PictureUrl string
-	Locale     string
+	// name of the provider and the subject (sub claim) identify the user in the provider
+	Provider string
+	Subject  string
+	Email    string
+	// the provider asserts the email (email_verified claim), false for the emails only
+	// trusted by the configuration, they can not be linked to an existing person
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	PictureUrl    string
+	Locale        string
 }
 
 func (model *OpenIdUser) GetFullName() string {
@@ -15,14 +21,30 @@ func (model *OpenIdUser) GetFullName() string {
 }
 
 type OpenIdConfig struct {
-	ResponseType string
-	Scope        string
+	// name of the provider in the login urls and the linked identities
+	Name string
+	// the discovery document is read from <issuer>/.well-known/openid-configuration
+	Issuer       string
 	ClientId     string
 	ClientSecret string
 	RedirectUri  string
+	Scope        string
+	// the provider does not send the email_verified claim and all its emails are verified,
+	// like the corporate directories
+	TrustEmails bool
 }
 
 type OpenIdService interface {
-	GetLoginUrl(state string) string
-	ProcessCallback(code string, state string, stateValidator func(string) bool) (OpenIdUser, error)
+	GetName() string
+	GetLoginUrl(request LoginRequest) (string, error)
+	// ProcessCallback exchanges the code and returns the user of the verified id token,
+	// the request must be the one of the state received in the callback.
+	ProcessCallback(code string, request LoginRequest) (OpenIdUser, error)
+}
+
+// OpenIdProviderRegistry the providers configured for login, the first one is the default.
+type OpenIdProviderRegistry interface {
+	GetProvider(name string) (OpenIdService, bool)
+	GetProviderNames() []string
+	GetDefaultProviderName() string
 }
@@ -0,0 +1,39 @@
+package openid
+
+import "fmt"
+
+type openIdProviderRegistry struct {
+	providers map[string]OpenIdService
+	names     []string
+}
+
+func NewOpenIdProviderRegistry(providers ...OpenIdService) OpenIdProviderRegistry {
+	if len(providers) == 0 {
+		panic("at least one openid provider is required")
+	}
+	registry := &openIdProviderRegistry{providers: map[string]OpenIdService{}}
+	for _, provider := range providers {
+		if _, duplicated := registry.providers[provider.GetName()]; duplicated {
+			panic(fmt.Sprintf("the openid provider %s is duplicated", provider.GetName()))
+		}
+		registry.providers[provider.GetName()] = provider
+		registry.names = append(registry.names, provider.GetName())
+	}
+	return registry
+}
+
+// GetProvider implements OpenIdProviderRegistry
+func (port *openIdProviderRegistry) GetProvider(name string) (OpenIdService, bool) {
+	provider, found := port.providers[name]
+	return provider, found
+}
+
+// GetProviderNames implements OpenIdProviderRegistry
+func (port *openIdProviderRegistry) GetProviderNames() []string {
+	return append([]string{}, port.names...)
+}
+
+// GetDefaultProviderName implements OpenIdProviderRegistry
+func (port *openIdProviderRegistry) GetDefaultProviderName() string {
+	return port.names[0]
+}
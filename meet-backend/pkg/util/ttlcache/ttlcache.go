@@ -0,0 +1,137 @@
+package ttlcache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats counters of the cache since it was created.
+type Stats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`
+	Invalidations int64 `json:"invalidations"`
+	Size          int   `json:"size"`
+}
+
+// StatsProvider the caches are exposed with it for the metrics.
+type StatsProvider interface {
+	GetStats() Stats
+}
+
+type entry[K comparable, V any] struct {
+	key            K
+	value          V
+	expirationTime time.Time
+}
+
+// Cache keeps up to maxSize values for the ttl, when it is full the least recently
+// used value is evicted. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	ttl     time.Duration
+	maxSize int
+	now     func() time.Time
+
+	mutex   sync.Mutex
+	entries map[K]*list.Element
+	order   *list.List
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	evictions     atomic.Int64
+	invalidations atomic.Int64
+}
+
+func New[K comparable, V any](ttl time.Duration, maxSize int) *Cache[K, V] {
+	if ttl <= 0 || maxSize <= 0 {
+		panic("the ttl and the max size of the cache must be positive")
+	}
+	return &Cache[K, V]{
+		ttl:     ttl,
+		maxSize: maxSize,
+		now:     time.Now,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (port *Cache[K, V]) Get(key K) (V, bool) {
+	port.mutex.Lock()
+	defer port.mutex.Unlock()
+
+	element, found := port.entries[key]
+	if found && port.now().Before(element.Value.(*entry[K, V]).expirationTime) {
+		port.order.MoveToFront(element)
+		port.hits.Add(1)
+		return element.Value.(*entry[K, V]).value, true
+	}
+	if found {
+		port.removeElement(element)
+	}
+	port.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+func (port *Cache[K, V]) Put(key K, value V) {
+	port.mutex.Lock()
+	defer port.mutex.Unlock()
+
+	expirationTime := port.now().Add(port.ttl)
+	if element, found := port.entries[key]; found {
+		element.Value = &entry[K, V]{key, value, expirationTime}
+		port.order.MoveToFront(element)
+		return
+	}
+	port.entries[key] = port.order.PushFront(&entry[K, V]{key, value, expirationTime})
+	for port.order.Len() > port.maxSize {
+		port.removeElement(port.order.Back())
+		port.evictions.Add(1)
+	}
+}
+
+// Invalidate removes the values of the keys, the next get reads them again from the source.
+func (port *Cache[K, V]) Invalidate(keys ...K) {
+	port.mutex.Lock()
+	defer port.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, found := port.entries[key]; found {
+			port.removeElement(element)
+			port.invalidations.Add(1)
+		}
+	}
+}
+
+func (port *Cache[K, V]) InvalidateAll() {
+	port.mutex.Lock()
+	defer port.mutex.Unlock()
+
+	port.invalidations.Add(int64(port.order.Len()))
+	port.entries = make(map[K]*list.Element)
+	port.order.Init()
+}
+
+// GetStats implements StatsProvider
+func (port *Cache[K, V]) GetStats() Stats {
+	port.mutex.Lock()
+	size := port.order.Len()
+	port.mutex.Unlock()
+
+	return Stats{
+		Hits:          port.hits.Load(),
+		Misses:        port.misses.Load(),
+		Evictions:     port.evictions.Load(),
+		Invalidations: port.invalidations.Load(),
+		Size:          size,
+	}
+}
+
+// private
+
+func (port *Cache[K, V]) removeElement(element *list.Element) {
+	port.order.Remove(element)
+	delete(port.entries, element.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,64 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExpiresAfterTtl(t *testing.T) {
+	now := time.Now()
+	cache := New[string, int](time.Minute, 10)
+	cache.now = func() time.Time { return now }
+
+	cache.Put("a", 1)
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	now = now.Add(time.Minute)
+	_, found = cache.Get("a")
+	assert.False(t, found)
+
+	stats := cache.GetStats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, 0, stats.Size)
+}
+
+func TestPutEvictsTheLeastRecentlyUsed(t *testing.T) {
+	cache := New[string, int](time.Minute, 2)
+
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Get("a")
+	cache.Put("c", 3)
+
+	_, found := cache.Get("b")
+	assert.False(t, found)
+	_, found = cache.Get("a")
+	assert.True(t, found)
+	_, found = cache.Get("c")
+	assert.True(t, found)
+	assert.Equal(t, int64(1), cache.GetStats().Evictions)
+	assert.Equal(t, 2, cache.GetStats().Size)
+}
+
+func TestInvalidate(t *testing.T) {
+	cache := New[int, string](time.Minute, 10)
+	cache.Put(1, "one")
+	cache.Put(2, "two")
+	cache.Put(3, "three")
+
+	cache.Invalidate(1, 4)
+	_, found := cache.Get(1)
+	assert.False(t, found)
+	_, found = cache.Get(2)
+	assert.True(t, found)
+
+	cache.InvalidateAll()
+	_, found = cache.Get(3)
+	assert.False(t, found)
+	assert.Equal(t, int64(3), cache.GetStats().Invalidations)
+}
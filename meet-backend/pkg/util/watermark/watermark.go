@@ -0,0 +1,464 @@
+// Package watermark embeds a short payload in the luminance of an image with a
+// spread spectrum pattern. The mark is invisible, survives jpeg re-compression, crops
+// and resizes, and is extracted without the original image, only with the same key.
+//
+// The payload is laid in a tile of blocks repeated over all the image, so the mark is
+// periodic: the extraction finds the scale from the period, and the position of the
+// tile (lost with a crop) searching the alignment where the message is valid.
+package watermark
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// every chip is a square of pixels with the same sign, big enough for survive the jpeg compression
+	chipSize = 4
+	// every block (chipsPerBlock x chipsPerBlock chips) carries one bit of the message
+	chipsPerBlock = 4
+	blockSize     = chipSize * chipsPerBlock
+
+	strength = 4.0
+
+	syncByte = 0xA5
+	// many alignments are tried in the extraction, the crc32 keeps the false positives negligible
+	checksumLen = 4
+
+	// range of the resizes searched in the extraction
+	minScale = 0.5
+	maxScale = 2.0
+	// periods of the autocorrelation tried as scale of the image
+	periodCandidates = 3
+	// alignments of the blocks, by energy, tried for every scale
+	alignmentCandidates = 4
+)
+
+var (
+	ErrImageTooSmall = errors.New("the image is too small for the watermark")
+	ErrNotFound      = errors.New("watermark not found")
+)
+
+// MinImageSize the width and height that an image needs at least for embed a payload of the length.
+func MinImageSize(payloadLength int) int {
+	return tileBlocksFor(messageBitsLength(payloadLength)) * blockSize
+}
+
+// Embed returns a copy of the image with the payload repeated over all the image.
+func Embed(src image.Image, payload []byte, key []byte) (image.Image, error) {
+	bits := messageBits(payload)
+	tileBlocks := tileBlocksFor(len(bits))
+
+	bounds := src.Bounds()
+	minSize := MinImageSize(len(payload))
+	if bounds.Dx() < minSize || bounds.Dy() < minSize {
+		return nil, ErrImageTooSmall
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+
+	pattern := chipPattern(key)
+	signs := cellSigns(key, tileBlocks)
+	blocksX, blocksY := bounds.Dx()/blockSize, bounds.Dy()/blockSize
+	for blockY := 0; blockY < blocksY; blockY++ {
+		for blockX := 0; blockX < blocksX; blockX++ {
+			cell := (blockY%tileBlocks)*tileBlocks + blockX%tileBlocks
+			bitSign := -signs[cell]
+			if bits[cell%len(bits)] {
+				bitSign = signs[cell]
+			}
+			for chip, chipSign := range pattern {
+				delta := strength * bitSign * chipSign
+				chipX := blockX*blockSize + (chip%chipsPerBlock)*chipSize
+				chipY := blockY*blockSize + (chip/chipsPerBlock)*chipSize
+				addToChip(dst, chipX, chipY, delta)
+			}
+		}
+	}
+	return dst, nil
+}
+
+// Extract recovers a payload with the length used in Embed.
+func Extract(img image.Image, payloadLength int, key []byte) ([]byte, error) {
+	bitsLength := messageBitsLength(payloadLength)
+	tileBlocks := tileBlocksFor(bitsLength)
+	tileSize := tileBlocks * blockSize
+
+	lumas := newLumaImage(img)
+	minSize := int(float64(tileSize) * minScale)
+	if lumas.width < minSize || lumas.height < minSize {
+		return nil, ErrImageTooSmall
+	}
+
+	pattern := chipPattern(key)
+	signs := cellSigns(key, tileBlocks)
+	for _, scale := range candidateScales(lumas, tileSize) {
+		scaled := lumas
+		if scale != 1 {
+			scaled = lumas.resize(int(math.Round(float64(lumas.width)/scale)), int(math.Round(float64(lumas.height)/scale)))
+		}
+		payload, found := extractAligned(scaled, pattern, signs, bitsLength, payloadLength)
+		if found {
+			return payload, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// private
+
+// tileBlocksFor the side of the square tile with a block for every bit
+func tileBlocksFor(bitsLength int) int {
+	return int(math.Ceil(math.Sqrt(float64(bitsLength))))
+}
+
+func messageBitsLength(payloadLength int) int {
+	return (1 + payloadLength + checksumLen) * 8
+}
+
+// messageBits the message is: sync byte + payload + crc of the payload
+func messageBits(payload []byte) []bool {
+	message := make([]byte, 0, 1+len(payload)+checksumLen)
+	message = append(message, syncByte)
+	message = append(message, payload...)
+	message = binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(payload))
+
+	bits := make([]bool, 0, len(message)*8)
+	for _, b := range message {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, b&(1<<i) != 0)
+		}
+	}
+	return bits
+}
+
+func parseMessage(bits []bool, payloadLength int) ([]byte, error) {
+	message := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			message[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	if message[0] != syncByte {
+		return nil, ErrNotFound
+	}
+	payload := message[1 : 1+payloadLength]
+	checksum := binary.BigEndian.Uint32(message[1+payloadLength:])
+	if checksum != crc32.ChecksumIEEE(payload) {
+		return nil, ErrNotFound
+	}
+	return payload, nil
+}
+
+// chipPattern pseudo random signs of the chips of the blocks, depends only on the key. The
+// same pattern in every block keeps the mark periodic and the alignment search small.
+func chipPattern(key []byte) []float64 {
+	return keyedSigns(key, "chips", chipsPerBlock*chipsPerBlock)
+}
+
+// cellSigns scramble the bits by cell of the tile, so the patterns of other keys, even
+// correlated with the pattern of the key, never read a valid message.
+func cellSigns(key []byte, tileBlocks int) []float64 {
+	return keyedSigns(key, "cells", tileBlocks*tileBlocks)
+}
+
+// keyedSigns balanced pseudo random signs: the same number of positive and negative
+func keyedSigns(key []byte, purpose string, length int) []float64 {
+	seedHash := sha256.Sum256(append(append([]byte{}, key...), purpose...))
+	random := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(seedHash[:8]))))
+
+	signs := make([]float64, length)
+	for i := range signs {
+		if i < length/2 {
+			signs[i] = 1
+		} else {
+			signs[i] = -1
+		}
+	}
+	random.Shuffle(len(signs), func(i, j int) {
+		signs[i], signs[j] = signs[j], signs[i]
+	})
+	return signs
+}
+
+type alignment struct {
+	energy float64
+	// correlation accumulated by cell of the tile, the cells are relative to the first block
+	cells []float64
+}
+
+// extractAligned tries the offsets of the block grid (lost with a crop) and then the
+// positions of the tile for the best ones, the valid message is the right alignment.
+func extractAligned(lumas *lumaImage, pattern []float64, signs []float64, bitsLength int, payloadLength int) ([]byte, bool) {
+	tileBlocks := tileBlocksFor(bitsLength)
+	// the details, the mark is in the high frequencies and the content mostly in the low ones
+	sums := lumas.highPass().integral()
+
+	var alignments []alignment
+	for offsetY := 0; offsetY < blockSize; offsetY++ {
+		for offsetX := 0; offsetX < blockSize; offsetX++ {
+			blocksX, blocksY := (lumas.width-offsetX)/blockSize, (lumas.height-offsetY)/blockSize
+			if blocksX < tileBlocks || blocksY < tileBlocks {
+				continue
+			}
+			current := alignment{cells: make([]float64, tileBlocks*tileBlocks)}
+			for blockY := 0; blockY < blocksY; blockY++ {
+				for blockX := 0; blockX < blocksX; blockX++ {
+					correlation := blockCorrelation(sums, pattern, offsetX+blockX*blockSize, offsetY+blockY*blockSize)
+					current.energy += math.Abs(correlation)
+					current.cells[(blockY%tileBlocks)*tileBlocks+blockX%tileBlocks] += correlation
+				}
+			}
+			alignments = append(alignments, current)
+		}
+	}
+	sort.Slice(alignments, func(i, j int) bool {
+		return alignments[i].energy > alignments[j].energy
+	})
+
+	correlations := make([]float64, bitsLength)
+	bits := make([]bool, bitsLength)
+	for i := 0; i < len(alignments) && i < alignmentCandidates; i++ {
+		for phaseY := 0; phaseY < tileBlocks; phaseY++ {
+			for phaseX := 0; phaseX < tileBlocks; phaseX++ {
+				clear(correlations)
+				for cell, correlation := range alignments[i].cells {
+					tileX := (cell%tileBlocks + phaseX) % tileBlocks
+					tileY := (cell/tileBlocks + phaseY) % tileBlocks
+					tileCell := tileY*tileBlocks + tileX
+					correlations[tileCell%bitsLength] += correlation * signs[tileCell]
+				}
+				for bit, correlation := range correlations {
+					bits[bit] = correlation > 0
+				}
+				payload, err := parseMessage(bits, payloadLength)
+				if err == nil {
+					return payload, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// blockCorrelation removes the mean of the block, so only the high frequencies are correlated
+func blockCorrelation(sums *integralImage, pattern []float64, x int, y int) float64 {
+	var chipLumas [chipsPerBlock * chipsPerBlock]float64
+	mean := 0.0
+	for chip := range pattern {
+		chipX := x + (chip%chipsPerBlock)*chipSize
+		chipY := y + (chip/chipsPerBlock)*chipSize
+		chipLumas[chip] = sums.sum(chipX, chipY, chipSize, chipSize)
+		mean += chipLumas[chip]
+	}
+	mean /= float64(len(pattern))
+
+	correlation := 0.0
+	for chip, chipSign := range pattern {
+		correlation += (chipLumas[chip] - mean) * chipSign
+	}
+	return correlation / (chipSize * chipSize)
+}
+
+// candidateScales the original scale first (the image was only cropped or re-compressed),
+// then the scales of the strongest periods of the image.
+func candidateScales(lumas *lumaImage, tileSize int) []float64 {
+	scales := []float64{1}
+	for _, period := range strongestPeriods(lumas, int(float64(tileSize)*minScale), int(float64(tileSize)*maxScale)) {
+		scale := period / float64(tileSize)
+		// the period is estimated, the neighbor scales cover its error
+		scales = append(scales, scale, scale*0.998, scale*1.002)
+	}
+	return scales
+}
+
+// strongestPeriods the peaks of the autocorrelation of the high frequencies, in both axes
+// because the resizes keep the aspect ratio. The periods are interpolated between pixels.
+func strongestPeriods(lumas *lumaImage, minPeriod int, maxPeriod int) []float64 {
+	details := lumas.highPass()
+	maxPeriod = min(maxPeriod, max(lumas.width, lumas.height)-minPeriod)
+	if maxPeriod <= minPeriod+1 {
+		return nil
+	}
+
+	autocorrelation := make([]float64, maxPeriod+2)
+	for lag := minPeriod - 1; lag <= maxPeriod+1; lag++ {
+		autocorrelation[lag] = details.autocorrelation(lag)
+	}
+
+	type peak struct {
+		period float64
+		value  float64
+	}
+	var peaks []peak
+	for lag := minPeriod; lag <= maxPeriod; lag++ {
+		previous, value, next := autocorrelation[lag-1], autocorrelation[lag], autocorrelation[lag+1]
+		if value <= previous || value < next || value <= 0 {
+			continue
+		}
+		// vertex of the parabola through the three lags
+		shift := 0.0
+		if curvature := previous - 2*value + next; curvature < 0 {
+			shift = 0.5 * (previous - next) / curvature
+		}
+		peaks = append(peaks, peak{float64(lag) + shift, value})
+	}
+	sort.Slice(peaks, func(i, j int) bool {
+		return peaks[i].value > peaks[j].value
+	})
+
+	periods := make([]float64, 0, periodCandidates)
+	for i := 0; i < len(peaks) && i < periodCandidates; i++ {
+		periods = append(periods, peaks[i].period)
+	}
+	return periods
+}
+
+// lumaImage the luminance of the pixels, the extraction reads every pixel many times
+type lumaImage struct {
+	width  int
+	height int
+	values []float64
+}
+
+func newLumaImage(img image.Image) *lumaImage {
+	bounds := img.Bounds()
+	lumas := &lumaImage{bounds.Dx(), bounds.Dy(), make([]float64, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < lumas.height; y++ {
+		for x := 0; x < lumas.width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			lumas.values[y*lumas.width+x] = 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+		}
+	}
+	return lumas
+}
+
+func (lumas *lumaImage) at(x int, y int) float64 {
+	x = min(max(x, 0), lumas.width-1)
+	y = min(max(y, 0), lumas.height-1)
+	return lumas.values[y*lumas.width+x]
+}
+
+// resize bilinear, back to the scale of the embedding
+func (lumas *lumaImage) resize(width int, height int) *lumaImage {
+	resized := &lumaImage{width, height, make([]float64, width*height)}
+	scaleX := float64(lumas.width) / float64(width)
+	scaleY := float64(lumas.height) / float64(height)
+	for y := 0; y < height; y++ {
+		sourceY := (float64(y)+0.5)*scaleY - 0.5
+		y0 := int(math.Floor(sourceY))
+		weightY := sourceY - float64(y0)
+		for x := 0; x < width; x++ {
+			sourceX := (float64(x)+0.5)*scaleX - 0.5
+			x0 := int(math.Floor(sourceX))
+			weightX := sourceX - float64(x0)
+			top := lumas.at(x0, y0)*(1-weightX) + lumas.at(x0+1, y0)*weightX
+			bottom := lumas.at(x0, y0+1)*(1-weightX) + lumas.at(x0+1, y0+1)*weightX
+			resized.values[y*width+x] = top*(1-weightY) + bottom*weightY
+		}
+	}
+	return resized
+}
+
+// highPass the luminance minus its local mean, the mark is in the details and the
+// content of the image is mostly in the low frequencies
+func (lumas *lumaImage) highPass() *lumaImage {
+	const radius = chipSize
+	sums := lumas.integral()
+	details := &lumaImage{lumas.width, lumas.height, make([]float64, len(lumas.values))}
+	for y := 0; y < lumas.height; y++ {
+		y0, y1 := max(y-radius, 0), min(y+radius+1, lumas.height)
+		for x := 0; x < lumas.width; x++ {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, lumas.width)
+			mean := sums.sum(x0, y0, x1-x0, y1-y0) / float64((x1-x0)*(y1-y0))
+			details.values[y*lumas.width+x] = lumas.values[y*lumas.width+x] - mean
+		}
+	}
+	return details
+}
+
+// autocorrelation of the lag in the rows plus in the columns, normalized by the pairs
+func (lumas *lumaImage) autocorrelation(lag int) float64 {
+	total, pairs := 0.0, 0
+	if lag < lumas.width {
+		for y := 0; y < lumas.height; y += 2 {
+			row := lumas.values[y*lumas.width : (y+1)*lumas.width]
+			for x := 0; x+lag < lumas.width; x++ {
+				total += row[x] * row[x+lag]
+			}
+			pairs += lumas.width - lag
+		}
+	}
+	if lag < lumas.height {
+		for y := 0; y+lag < lumas.height; y++ {
+			row := lumas.values[y*lumas.width : (y+1)*lumas.width]
+			lagRow := lumas.values[(y+lag)*lumas.width : (y+lag+1)*lumas.width]
+			for x := 0; x < lumas.width; x += 2 {
+				total += row[x] * lagRow[x]
+			}
+			pairs += (lumas.width + 1) / 2
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return total / float64(pairs)
+}
+
+// integralImage sums of the rectangles from the origin, for sum any rectangle in constant time
+type integralImage struct {
+	width  int
+	values []float64
+}
+
+func (lumas *lumaImage) integral() *integralImage {
+	width := lumas.width + 1
+	sums := &integralImage{width, make([]float64, width*(lumas.height+1))}
+	for y := 0; y < lumas.height; y++ {
+		rowSum := 0.0
+		for x := 0; x < lumas.width; x++ {
+			rowSum += lumas.values[y*lumas.width+x]
+			sums.values[(y+1)*width+x+1] = sums.values[y*width+x+1] + rowSum
+		}
+	}
+	return sums
+}
+
+func (sums *integralImage) sum(x int, y int, width int, height int) float64 {
+	return sums.values[(y+height)*sums.width+x+width] - sums.values[y*sums.width+x+width] -
+		sums.values[(y+height)*sums.width+x] + sums.values[y*sums.width+x]
+}
+
+func addToChip(img *image.RGBA, chipX int, chipY int, delta float64) {
+	for y := chipY; y < chipY+chipSize; y++ {
+		for x := chipX; x < chipX+chipSize; x++ {
+			pixel := img.RGBAAt(x, y)
+			// same delta in the three channels changes the luminance and not the hue
+			img.SetRGBA(x, y, color.RGBA{
+				R: clamp(float64(pixel.R) + delta),
+				G: clamp(float64(pixel.G) + delta),
+				B: clamp(float64(pixel.B) + delta),
+				A: pixel.A,
+			})
+		}
+	}
+}
+
+func clamp(value float64) uint8 {
+	if value < 0 {
+		return 0
+	}
+	if value > 255 {
+		return 255
+	}
+	return uint8(value + 0.5)
+}
@@ -0,0 +1,171 @@
+package watermark_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"testing"
+
+	"github.com/erodriguezg/meet/pkg/util/imageutil"
+	"github.com/erodriguezg/meet/pkg/util/watermark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var key = []byte("test-watermark-key")
+
+// photoLike a smooth gradient with some texture, similar to a photo
+func photoLike(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			texture := 20 * math.Sin(float64(x)/7) * math.Cos(float64(y)/11)
+			img.Set(x, y, color.RGBA{
+				R: uint8(60 + float64(x*120/width) + texture),
+				G: uint8(80 + float64(y*100/height) + texture),
+				B: uint8(100 + texture),
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+func jpegRoundTrip(t *testing.T, img image.Image, quality int) image.Image {
+	var buffer bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buffer, img, &jpeg.Options{Quality: quality}))
+	compressed, err := jpeg.Decode(&buffer)
+	require.NoError(t, err)
+	return compressed
+}
+
+// upscale bilinear, as the image editors
+func upscale(src image.Image, scale float64) image.Image {
+	bounds := src.Bounds()
+	width, height := int(float64(bounds.Dx())*scale), int(float64(bounds.Dy())*scale)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	channel := func(x int, y int) [3]float64 {
+		x = min(max(x, 0), bounds.Dx()-1)
+		y = min(max(y, 0), bounds.Dy()-1)
+		r, g, b, _ := src.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+		return [3]float64{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+	}
+	for y := 0; y < height; y++ {
+		sourceY := (float64(y)+0.5)/scale - 0.5
+		y0 := int(math.Floor(sourceY))
+		weightY := sourceY - float64(y0)
+		for x := 0; x < width; x++ {
+			sourceX := (float64(x)+0.5)/scale - 0.5
+			x0 := int(math.Floor(sourceX))
+			weightX := sourceX - float64(x0)
+			var rgb [3]uint8
+			for i := range rgb {
+				top := channel(x0, y0)[i]*(1-weightX) + channel(x0+1, y0)[i]*weightX
+				bottom := channel(x0, y0+1)[i]*(1-weightX) + channel(x0+1, y0+1)[i]*weightX
+				rgb[i] = uint8(top*(1-weightY) + bottom*weightY + 0.5)
+			}
+			dst.Set(x, y, color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 255})
+		}
+	}
+	return dst
+}
+
+func TestEmbedAndExtractAfterJpegCompression(t *testing.T) {
+	payload := []byte("0123456789abcdefghijklmn")
+
+	marked, err := watermark.Embed(photoLike(800, 600), payload, key)
+	require.NoError(t, err)
+
+	extracted, err := watermark.Extract(jpegRoundTrip(t, marked, 75), len(payload), key)
+	require.NoError(t, err)
+	assert.Equal(t, payload, extracted)
+}
+
+func TestEmbedAndExtractAfterStrongJpegCompression(t *testing.T) {
+	payload := []byte("0123456789abcdefghijklmn")
+
+	marked, err := watermark.Embed(photoLike(800, 600), payload, key)
+	require.NoError(t, err)
+
+	extracted, err := watermark.Extract(jpegRoundTrip(t, marked, 50), len(payload), key)
+	require.NoError(t, err)
+	assert.Equal(t, payload, extracted)
+}
+
+func TestEmbedAndExtractAfterCrop(t *testing.T) {
+	payload := []byte("0123456789abcdefghijklmn")
+
+	marked, err := watermark.Embed(photoLike(800, 600), payload, key)
+	require.NoError(t, err)
+
+	// the offset is not aligned with the blocks nor the tile of the mark
+	cropped := marked.(*image.RGBA).SubImage(image.Rect(37, 53, 637, 453))
+
+	extracted, err := watermark.Extract(jpegRoundTrip(t, cropped, 75), len(payload), key)
+	require.NoError(t, err)
+	assert.Equal(t, payload, extracted)
+}
+
+func TestEmbedAndExtractAfterDownscale(t *testing.T) {
+	payload := []byte("0123456789abcdefghijklmn")
+
+	marked, err := watermark.Embed(photoLike(800, 600), payload, key)
+	require.NoError(t, err)
+
+	extracted, err := watermark.Extract(jpegRoundTrip(t, imageutil.Resize(marked, 600), 90), len(payload), key)
+	require.NoError(t, err)
+	assert.Equal(t, payload, extracted)
+}
+
+func TestEmbedAndExtractAfterUpscale(t *testing.T) {
+	payload := []byte("0123456789abcdefghijklmn")
+
+	marked, err := watermark.Embed(photoLike(800, 600), payload, key)
+	require.NoError(t, err)
+
+	extracted, err := watermark.Extract(jpegRoundTrip(t, upscale(marked, 1.5), 90), len(payload), key)
+	require.NoError(t, err)
+	assert.Equal(t, payload, extracted)
+}
+
+func TestExtractWithOtherKeyFails(t *testing.T) {
+	payload := []byte("0123456789abcdefghijklmn")
+
+	marked, err := watermark.Embed(photoLike(800, 600), payload, key)
+	require.NoError(t, err)
+
+	_, err = watermark.Extract(marked, len(payload), []byte("other-key"))
+	assert.ErrorIs(t, err, watermark.ErrNotFound)
+}
+
+func TestExtractFromUnmarkedImageFails(t *testing.T) {
+	_, err := watermark.Extract(photoLike(800, 600), 24, key)
+	assert.ErrorIs(t, err, watermark.ErrNotFound)
+}
+
+func TestEmbedIsInvisible(t *testing.T) {
+	original := photoLike(400, 300)
+	marked, err := watermark.Embed(original, []byte("payload"), key)
+	require.NoError(t, err)
+
+	r1, _, _, _ := original.At(10, 10).RGBA()
+	r2, _, _, _ := marked.At(10, 10).RGBA()
+	assert.InDelta(t, r1>>8, r2>>8, 5)
+}
+
+func TestEmbedInSmallImageFails(t *testing.T) {
+	_, err := watermark.Embed(photoLike(32, 32), []byte("0123456789abcdefghijklmn"), key)
+	assert.ErrorIs(t, err, watermark.ErrImageTooSmall)
+}
+
+func TestEmbedInImageOfMinSize(t *testing.T) {
+	payload := []byte("0123456789abcdefghijklmn")
+	minSize := watermark.MinImageSize(len(payload))
+
+	_, err := watermark.Embed(photoLike(minSize, minSize), payload, key)
+	assert.NoError(t, err)
+	_, err = watermark.Embed(photoLike(minSize-1, minSize), payload, key)
+	assert.ErrorIs(t, err, watermark.ErrImageTooSmall)
+}
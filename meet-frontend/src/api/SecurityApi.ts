@@ -17,6 +17,15 @@ const getToken = async (code: string, state: string): Promise<any> => {
   return data
 }
 
+const refreshToken = async (refreshToken: string): Promise<any> => {
+  const { data } = await axios.post('/api/v1/security/refresh', { refreshToken })
+  return data
+}
+
+const logout = async (refreshToken: string | null): Promise<void> => {
+  await axios.post('/api/v1/security/logout', { refreshToken: refreshToken ?? '' })
+}
+
 const getIdentity = async (): Promise<Identity> => {
   const { data } = await axios.get('/api/v1/security/identity')
   return data.payload
@@ -25,5 +34,7 @@ const getIdentity = async (): Promise<Identity> => {
 export const SecurityApi = {
   getLoginUrl,
   getToken,
+  refreshToken,
+  logout,
   getIdentity
 }
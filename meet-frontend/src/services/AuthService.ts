@@ -4,6 +4,7 @@ import { CookieStorageUtil } from '../utils/CookieStorageUtil'
 import { SessionStorageUtil } from '../utils/SessionStorageUtil'
 
 const jwtCookieKey = 'jwt'
+const refreshTokenCookieKey = 'refreshToken'
 const identitySessionKey = 'identity'
 
 export interface Identity {
@@ -29,19 +30,57 @@ export enum Permission {
   CREATE_ROOM = 3
 }
 
+// the refresh of the jwt in progress, shared by the requests rejected at the same time
+let refreshingToken: Promise<string | null> | null = null
+
 const processLoginCallback = async (code: string, state: string): Promise<void> => {
   const tokenResponse = await SecurityApi.getToken(code, state)
-  CookieStorageUtil.setString(jwtCookieKey, tokenResponse.jwt)
+  saveTokens(tokenResponse)
   await refreshIdentity(tokenResponse.jwt)
 }
 
 const initialize = async (): Promise<void> => {
-  const jwt = CookieStorageUtil.getString(jwtCookieKey)
+  AxiosUtil.setUnauthorizedHandler(refreshToken)
+  let jwt = CookieStorageUtil.getString(jwtCookieKey)
+  if (jwt === null && CookieStorageUtil.getString(refreshTokenCookieKey) !== null) {
+    jwt = await refreshToken()
+  }
   if (jwt !== null) {
     await refreshIdentity(jwt)
   }
 }
 
+// refreshToken gets a new jwt with the refresh token, the refresh token is rotated
+const refreshToken = async (): Promise<string | null> => {
+  if (refreshingToken === null) {
+    refreshingToken = requestRefreshToken().finally(() => {
+      refreshingToken = null
+    })
+  }
+  return await refreshingToken
+}
+
+const requestRefreshToken = async (): Promise<string | null> => {
+  const currentRefreshToken = CookieStorageUtil.getString(refreshTokenCookieKey)
+  if (currentRefreshToken === null) {
+    return null
+  }
+  try {
+    const tokenResponse = await SecurityApi.refreshToken(currentRefreshToken)
+    saveTokens(tokenResponse)
+    AxiosUtil.setAuthorization(tokenResponse.jwt)
+    return tokenResponse.jwt
+  } catch (e) {
+    cleanStorage()
+    return null
+  }
+}
+
+const saveTokens = (tokenResponse: any): void => {
+  CookieStorageUtil.setString(jwtCookieKey, tokenResponse.jwt)
+  CookieStorageUtil.setString(refreshTokenCookieKey, tokenResponse.refreshToken)
+}
+
 const getIdentity = (): Identity | null => {
   return SessionStorageUtil.getItem(identitySessionKey)
 }
@@ -66,9 +105,15 @@ const hasAnyPermission = (permissionList: Permission[]): boolean => {
   return permissionList.some(p => hasPermission(p)) ?? false
 }
 
-const logout = (): void => {
-  CookieStorageUtil.remove(jwtCookieKey)
-  SessionStorageUtil.remove(identitySessionKey)
+// logout revokes the session in the backend, the local session is removed even when it fails
+const logout = async (): Promise<void> => {
+  try {
+    await SecurityApi.logout(CookieStorageUtil.getString(refreshTokenCookieKey))
+  } catch (e) {
+    console.error(e)
+  } finally {
+    cleanStorage()
+  }
 }
 
 const refreshIdentity = async (jwtToken: string): Promise<void> => {
@@ -83,7 +128,9 @@ const refreshIdentity = async (jwtToken: string): Promise<void> => {
 
 const cleanStorage = (): void => {
   CookieStorageUtil.remove(jwtCookieKey)
+  CookieStorageUtil.remove(refreshTokenCookieKey)
   SessionStorageUtil.remove(identitySessionKey)
+  AxiosUtil.removeAuthorization()
 }
 
 export const AuthService = {
@@ -1,16 +1,35 @@
-import axios, { type AxiosResponse } from 'axios'
+import axios, { type AxiosError, type AxiosResponse, type InternalAxiosRequestConfig } from 'axios'
 import BusinessError from '../errors/BusinessError'
 
+// returns the new jwt, or null when the session can not be refreshed
+type UnauthorizedHandler = () => Promise<string | null>
+
+interface RetriableRequestConfig extends InternalAxiosRequestConfig {
+  authorizationRetried?: boolean
+}
+
+const refreshTokenUrl = '/api/v1/security/refresh'
+
+let unauthorizedHandler: UnauthorizedHandler | null = null
+
 const initialize = (): void => {
   axios.defaults.baseURL = import.meta.env.VITE_APP_BACKEND_URL
   axios.defaults.headers.post['Content-Type'] = 'application/json'
-  axios.interceptors.response.use(businessExceptionInterceptor)
+  axios.interceptors.response.use(businessExceptionInterceptor, unauthorizedInterceptor)
 }
 
 const setAuthorization = (token: string): void => {
   axios.defaults.headers.Authorization = `Bearer ${token}`
 }
 
+const removeAuthorization = (): void => {
+  delete axios.defaults.headers.Authorization
+}
+
+const setUnauthorizedHandler = (handler: UnauthorizedHandler): void => {
+  unauthorizedHandler = handler
+}
+
 const businessExceptionInterceptor = (response: AxiosResponse): AxiosResponse => {
   if (response?.data?.status === 'BUSINESS_ERROR') {
     throw new BusinessError(response.data.respuesta)
@@ -18,7 +37,25 @@ const businessExceptionInterceptor = (response: AxiosResponse): AxiosResponse =>
   return response
 }
 
+// unauthorizedInterceptor the jwt expired, the request is sent again once with a new jwt
+const unauthorizedInterceptor = async (error: AxiosError): Promise<AxiosResponse> => {
+  const config = error.config as RetriableRequestConfig | undefined
+  if (error.response?.status !== 401 || config === undefined || config.authorizationRetried === true ||
+    config.url === refreshTokenUrl || unauthorizedHandler === null) {
+    throw error
+  }
+  const token = await unauthorizedHandler()
+  if (token === null) {
+    throw error
+  }
+  config.authorizationRetried = true
+  config.headers.Authorization = `Bearer ${token}`
+  return await axios.request(config)
+}
+
 export const AxiosUtil = {
   initialize,
-  setAuthorization
+  setAuthorization,
+  removeAuthorization,
+  setUnauthorizedHandler
 }